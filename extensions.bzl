@@ -46,6 +46,8 @@ def _compiler_extension(module_ctx):
     )
     
     gleam_toml = None
+    verify_tarballs = ""
+    hex_registry = ""
     for mod in module_ctx.modules:
         # if mod.name == "rules_gleam":
         #     continue
@@ -56,11 +58,15 @@ def _compiler_extension(module_ctx):
                     continue
                 fail("There should be one gleam.toml defined, existing declaration at %s" % module_ctx.path(gleam_toml))
             gleam_toml = gleam_deps.gleam_toml
+            verify_tarballs = gleam_deps.verify_tarballs
+            hex_registry = gleam_deps.hex_registry
 
     hex_modules = []
     hex_modules = gleam_hex_repositories(
         module_ctx,
         gleam_toml = gleam_toml,
+        verify_tarballs = verify_tarballs,
+        hex_registry = hex_registry,
     )
     for hex_mod in hex_modules:
         direct_deps[hex_mod] = True
@@ -95,6 +101,12 @@ gleam = module_extension(
                     mandatory = True,
                     doc = "The gleam.toml file to be pulling deps from.",
                 ),
+                "hex_registry": attr.string(
+                    doc = "A Hex registry snapshot directory, relative to the gleam.toml, to resolve it with when manifest.toml is missing or stale.",
+                ),
+                "verify_tarballs": attr.string(
+                    doc = "A directory of downloaded Hex tarballs, relative to the gleam.toml, to check against the manifest.toml checksums.",
+                ),
             },
         ),
         "erlang": tag_class(
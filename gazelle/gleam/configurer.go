@@ -1,46 +1,117 @@
 package gleam
 
 import (
+	"context"
 	"flag"
 	"fmt"
 	"log"
 	"os"
 	"os/exec"
+	"path"
 	"path/filepath"
+	"regexp"
 	"strings"
 	"sync"
 
 	"github.com/BurntSushi/toml"
+	"github.com/bmatcuk/doublestar/v4"
 	"github.com/bazelbuild/bazel-gazelle/config"
-	"github.com/bazelbuild/bazel-gazelle/repo"
+	"github.com/bazelbuild/bazel-gazelle/label"
 	"github.com/bazelbuild/bazel-gazelle/rule"
 	"github.com/bazelbuild/buildtools/build"
 	"github.com/bazelbuild/rules_go/go/runfiles"
+	"github.com/iocat/rules_gleam/gazelle/gleam/otp"
+	"github.com/iocat/rules_gleam/internal/hex"
 )
 
 type GleamConfig struct {
 	// For directive gleam_visibility
 	gleamVisibility []string
+	// For directive gleam_target, one of targetErlang, targetJavascript or
+	// targetBoth. Defaults to targetErlang when unset.
+	target string
+	// For directive gleam_resolve, labels to use for imports instead of
+	// resolving them.
+	resolveOverrides map[string]label.Label
+	// For directive gleam_exclude, glob patterns relative to the repository
+	// root of files and directories left out of rule generation.
+	excludes []string
+	// For directive gleam_ignore, whether the directory and its
+	// subdirectories are left out of rule generation.
+	ignore bool
+	// For directive gleam_generation_mode, one of generationModeModule or
+	// generationModePackage. Defaults to generationModeModule when unset.
+	mode string
+	// For directive gleam_test_naming, glob patterns relative to the
+	// repository root of the files holding test modules, on top of the
+	// _test.gleam and _tests.gleam suffixes.
+	testNaming []string
+	// For directive gleam_test_mode, one of testModePackage or testModeFile.
+	// Defaults to testModePackage when unset.
+	testMode string
+	// The OTP modules "erl:" imports aren't resolved for: the ones of the
+	// -gleam_otp_root installation, else of the gleam_otp_version directive.
+	// Defaults to the ones of otp.DefaultVersion when unset.
+	otpModules *otp.Modules
+	// For directive gleam_elixir_package, labels of the mix packages, by
+	// package name, that "erl:Elixir.*" imports of their modules depend on.
+	elixirPackages map[string]label.Label
 
 	// Whether we're generates for an external Gleam (Hex) repository
 	externalRepo bool
-	// Cache of remote repositories from gleam.toml
-	// Reusing Go repo.Repo for now.
-	repos []repo.Repo
-	// Required for external repo construction.
+	// Modules of the remote repositories from gleam.toml, built once by
+	// CheckFlags and shared by every directory.
+	modules *moduleIndex
+	// Required for external repo construction without a manifest.toml.
 	gleamCompilerPath string
+	// Hex repository and cache directory of the packages of an external
+	// repo's manifest.toml.
+	hexMirror   string
+	hexCacheDir string
+	// Whether import cycles fail the run instead of only being reported.
+	failOnImportCycle bool
+	// OTP installation to read the OTP modules from instead of the known
+	// ones of a release. The gleam_otp_version directive is then ignored.
+	otpRoot string
+	// Whether imports that don't get a dependency, e.g. of OTP modules, are
+	// logged.
+	verbose bool
 }
 
 func (c *GleamConfig) clone() *GleamConfig {
 	visibility := make([]string, len(c.gleamVisibility))
-	repos := make([]repo.Repo, len(c.repos))
-	copy(repos, c.repos)
 	copy(visibility, c.gleamVisibility)
+	resolveOverrides := make(map[string]label.Label, len(c.resolveOverrides))
+	for imp, l := range c.resolveOverrides {
+		resolveOverrides[imp] = l
+	}
+	excludes := make([]string, len(c.excludes))
+	copy(excludes, c.excludes)
+	testNaming := make([]string, len(c.testNaming))
+	copy(testNaming, c.testNaming)
+	elixirPackages := make(map[string]label.Label, len(c.elixirPackages))
+	for pkg, l := range c.elixirPackages {
+		elixirPackages[pkg] = l
+	}
 	return &GleamConfig{
 		gleamVisibility:   visibility,
+		target:            c.target,
+		resolveOverrides:  resolveOverrides,
+		excludes:          excludes,
+		ignore:            c.ignore,
+		mode:              c.mode,
+		testNaming:        testNaming,
+		testMode:          c.testMode,
 		externalRepo:      c.externalRepo,
-		repos:             repos,
+		modules:           c.modules,
 		gleamCompilerPath: c.gleamCompilerPath,
+		hexMirror:         c.hexMirror,
+		hexCacheDir:       c.hexCacheDir,
+		failOnImportCycle: c.failOnImportCycle,
+		otpModules:        c.otpModules,
+		elixirPackages:    elixirPackages,
+		otpRoot:           c.otpRoot,
+		verbose:           c.verbose,
 	}
 }
 
@@ -49,25 +120,57 @@ func (g *gleamLanguage) RegisterFlags(fs *flag.FlagSet, cmd string, c *config.Co
 	c.Exts[languageName] = pc
 
 	fs.StringVar(&pc.gleamCompilerPath, "gleam_compiler_path", "", "The path to the gleam compiler")
+	fs.StringVar(&pc.hexMirror, "gleam_hex_mirror", "", //
+		"The Hex repository to download packages from, defaults to $HEX_MIRROR or "+hex.DefaultMirror)
+	fs.StringVar(&pc.hexCacheDir, "gleam_hex_cache", "", //
+		"The directory downloaded Hex packages are cached in, defaults to the user cache directory")
 	fs.BoolVar(&pc.externalRepo, "gleam_external_repo", //
 		false, "Whether we're setting up an external Gleam repository")
+	fs.StringVar(&pc.otpRoot, "gleam_otp_root", "", //
+		"The OTP installation, e.g. of the Erlang toolchain, to read the modules of OTP applications from.\n"+
+			"It takes precedence over the gleam_otp_version directive")
+	fs.BoolVar(&pc.failOnImportCycle, "gleam_fail_on_import_cycle", //
+		false, "Whether to exit with an error when Gleam modules import each other in a cycle.\n"+
+			"Cycles are found once every rule is resolved, so Gazelle exits before writing any BUILD file")
+	fs.BoolVar(&pc.verbose, "gleam_verbose", //
+		false, "Whether to log the imports that don't get a dependency, e.g. of OTP modules")
 }
 
 func (g *gleamLanguage) CheckFlags(fs *flag.FlagSet, c *config.Config) error {
 	gc := GetGleamConfig(c).clone()
 	c.Exts[languageName] = gc
+	g.failOnImportCycle = gc.failOnImportCycle
+
+	if gc.otpRoot != "" {
+		modules, err := otp.ReadRoot(gc.otpRoot)
+		if err != nil {
+			return fmt.Errorf("failed to read the OTP modules of -gleam_otp_root: %w", err)
+		}
+		gc.otpModules = modules
+	}
 
 	if gc.externalRepo {
-		if len(gc.gleamCompilerPath) == 0 {
-			return fmt.Errorf("gleam compiler not provided.")
+		if len(gc.hexCacheDir) == 0 {
+			cacheDir, err := hex.DefaultCacheDir()
+			if err != nil {
+				return fmt.Errorf("no directory to cache Hex packages in, set -gleam_hex_cache: %w", err)
+			}
+			gc.hexCacheDir = cacheDir
 		}
-		if err := maybePopulateRemoteCacheFromGleamTomlForExternalRepo(c, gc, &gc.repos); err != nil {
+		modules, err := indexExternalRepoDeps(c, gc)
+		if err != nil {
 			return err
 		}
+		gc.modules = modules
 	} else {
-		if err := maybePopulateRemoteCacheFromBzlMod(c, &gc.repos); err != nil {
+		modules, err := indexBzlModDeps(c)
+		if err != nil {
 			return err
 		}
+		if err := markDevOnlyModules(c.RepoRoot, modules); err != nil {
+			return err
+		}
+		gc.modules = modules
 	}
 
 	return nil
@@ -75,24 +178,11 @@ func (g *gleamLanguage) CheckFlags(fs *flag.FlagSet, c *config.Config) error {
 
 type GleamToml struct {
 	Name            string            `toml:"name"`
-	Dependencies    map[string]string `toml:"dependencies"`
+	// Version requirements, or tables for git and local path dependencies.
+	Dependencies    map[string]any `toml:"dependencies"`
 	DevDependencies map[string]any `toml:"dev-dependencies"`
 }
 
-type ManifestTomlPackage struct {
-	Name          string   `toml:"name"`
-	Version       string   `toml:"version"`
-	BuildTools    []string `toml:"build_tools"`
-	Requirements  []string `toml:"requirements"`
-	OtpApp        string   `toml:"otp_app"`
-	Source        string   `toml:"source"`
-	OuterChecksum string   `toml:"outer_checksum"`
-}
-
-type ManifestToml struct {
-	Packages []ManifestTomlPackage `toml:"packages"`
-}
-
 func parseGleamToml(repoRoot string) (*GleamToml, error) {
 	gleamTomlPath := filepath.Join(repoRoot, "gleam.toml")
 	if _, err := os.Stat(gleamTomlPath); err != nil {
@@ -108,41 +198,107 @@ func parseGleamToml(repoRoot string) (*GleamToml, error) {
 	if err := toml.Unmarshal(data, &gleamToml); err != nil {
 		return nil, err
 	}
-	// clear dev dependencies. (minimize fetch)
-	gleamToml.DevDependencies = make(map[string]any)
-	// rewrite back for gleam tool to pick up.
+	return &gleamToml, nil
+}
 
-	removedDevToml, err := toml.Marshal(gleamToml)
+// writeDownloadGleamToml writes a copy of the gleam.toml of srcDir without
+// its dev-dependencies to dstDir, along with manifest.toml if there is one.
+// The copy keeps the sections that aren't modelled by GleamToml. Relative
+// paths of local dependencies are made absolute, so that they still point to
+// the packages next to srcDir.
+func writeDownloadGleamToml(srcDir, dstDir string) error {
+	absSrcDir, err := filepath.Abs(srcDir)
 	if err != nil {
-		return nil, fmt.Errorf("failed to remove dev deps: %s", err)
+		return err
 	}
-	err = os.WriteFile(gleamTomlPath, []byte(removedDevToml), 0644)
+	data, err := os.ReadFile(filepath.Join(srcDir, "gleam.toml"))
 	if err != nil {
-		return nil, fmt.Errorf("failed to write gleam.toml with removed deps: %s", err)
+		return err
+	}
+	var gleamToml map[string]any
+	if err := toml.Unmarshal(data, &gleamToml); err != nil {
+		return err
+	}
+	delete(gleamToml, "dev-dependencies")
+	if deps, ok := gleamToml["dependencies"].(map[string]any); ok {
+		for _, dep := range deps {
+			absolutePath(dep, absSrcDir)
+		}
+	}
+	removedDevToml, err := toml.Marshal(gleamToml)
+	if err != nil {
+		return fmt.Errorf("failed to remove dev deps: %s", err)
+	}
+	if err := os.WriteFile(filepath.Join(dstDir, "gleam.toml"), removedDevToml, 0644); err != nil {
+		return fmt.Errorf("failed to write gleam.toml with removed deps: %s", err)
 	}
-	return &gleamToml, nil
-}
 
-func parseManifestToml(repoRoot string) (*ManifestToml, error) {
-	manifestTomlPath := filepath.Join(repoRoot, "manifest.toml")
-	if _, err := os.Stat(manifestTomlPath); err != nil {
-		return nil, nil
+	manifest, err := os.ReadFile(filepath.Join(srcDir, "manifest.toml"))
+	if os.IsNotExist(err) {
+		return nil
+	} else if err != nil {
+		return err
+	}
+	var manifestToml map[string]any
+	if err := toml.Unmarshal(manifest, &manifestToml); err != nil {
+		return err
 	}
+	// Local packages and their requirements have the same paths as in
+	// gleam.toml, so that the copied manifest.toml still matches it.
+	rewritten := false
+	// Inline tables, like gleam writes them, or [[packages]] tables.
+	switch pkgs := manifestToml["packages"].(type) {
+	case []any:
+		for _, pkg := range pkgs {
+			rewritten = absolutePath(pkg, absSrcDir) || rewritten
+		}
+	case []map[string]any:
+		for _, pkg := range pkgs {
+			rewritten = absolutePath(pkg, absSrcDir) || rewritten
+		}
+	}
+	if requirements, ok := manifestToml["requirements"].(map[string]any); ok {
+		for _, requirement := range requirements {
+			rewritten = absolutePath(requirement, absSrcDir) || rewritten
+		}
+	}
+	if rewritten {
+		if manifest, err = toml.Marshal(manifestToml); err != nil {
+			return fmt.Errorf("failed to rewrite the paths of manifest.toml: %s", err)
+		}
+	}
+	return os.WriteFile(filepath.Join(dstDir, "manifest.toml"), manifest, 0644)
+}
 
-	data, err := os.ReadFile(manifestTomlPath)
-	if err != nil {
-		return nil, err
+// absolutePath makes the relative path of a local dependency, package or
+// requirement table absolute, from dir. It reports whether it did.
+func absolutePath(table any, dir string) bool {
+	t, ok := table.(map[string]any)
+	if !ok {
+		return false
+	}
+	path, ok := t["path"].(string)
+	if !ok || filepath.IsAbs(path) {
+		return false
 	}
+	t["path"] = filepath.Join(dir, filepath.FromSlash(path))
+	return true
+}
 
-	var manifestToml = new(ManifestToml)
-	if err := toml.Unmarshal(data, manifestToml); err != nil {
-		return nil, err
+// parseManifestToml reads the manifest.toml of dir, it returns nil if there's
+// none.
+func parseManifestToml(dir string) (*hex.Manifest, error) {
+	manifestTomlPath := filepath.Join(dir, "manifest.toml")
+	if _, err := os.Stat(manifestTomlPath); err != nil {
+		return nil, nil
 	}
-	// rewrite back for gleam tool to pick up.
-	return manifestToml, nil
+	return hex.ReadManifest(manifestTomlPath)
 }
 
-func maybePopulateRemoteCacheFromGleamTomlForExternalRepo(c *config.Config, gc *GleamConfig, repos *[]repo.Repo) error {
+// indexExternalRepoDeps returns the modules of the dependencies of the
+// gleam.toml of an external repository, downloading them.
+func indexExternalRepoDeps(c *config.Config, gc *GleamConfig) (*moduleIndex, error) {
+	modules := newModuleIndex()
 	haveGleam := false
 	for name := range c.Exts {
 		if name == "gleam" {
@@ -151,49 +307,200 @@ func maybePopulateRemoteCacheFromGleamTomlForExternalRepo(c *config.Config, gc *
 		}
 	}
 	if !haveGleam {
-		return nil
+		return modules, nil
 	}
 
 	gleamToml, err := parseGleamToml(c.RepoRoot)
 	if err != nil {
-		return err
+		return nil, err
 	}
 
 	if gleamToml == nil || len(gleamToml.Dependencies) == 0 {
-		return nil
+		return modules, nil
+	}
+
+	deps, err := downloadDeps(c, gc, gleamToml)
+	if err != nil {
+		return nil, err
+	}
+	defer deps.cleanup()
+
+	for _, gleamPackage := range deps.packages {
+		module := hexRepoName(gleamPackage)
+		externalPath := filepath.Join(deps.dirs[gleamPackage], "src")
+		// Packages built by mix have their sources in lib.
+		if _, err := os.Stat(externalPath); os.IsNotExist(err) {
+			externalPath = deps.dirs[gleamPackage]
+		} else if err != nil {
+			return nil, err
+		}
+
+		found, err := indexPackageModules(externalPath, module)
+		if err != nil {
+			return nil, err
+		}
+		modules.merge(found)
+	}
+	return modules, nil
+}
+
+// packageDirs are the directories the dependencies of an external repository
+// are downloaded in.
+type packageDirs struct {
+	// Names of the packages, in manifest.toml order.
+	packages []string
+	// Directory of each package, holding its gleam.toml and src.
+	dirs    map[string]string
+	cleanup func()
+}
+
+// downloadDeps downloads the packages of the manifest.toml of an external
+// repository from Hex, leaving dev dependencies out. Without a manifest.toml
+// there are no locked versions, and "gleam deps download" resolves and
+// downloads them instead.
+func downloadDeps(c *config.Config, gc *GleamConfig, gleamToml *GleamToml) (*packageDirs, error) {
+	manifestToml, err := parseManifestToml(c.RepoRoot)
+	if err != nil {
+		return nil, err
+	}
+	if manifestToml == nil {
+		return downloadDepsWithCompiler(c, gc)
 	}
-	
+
+	devOnly := devOnlyPackages(gleamToml, manifestToml)
+	var pkgs []hex.Package
+	for _, pkg := range manifestToml.HexPackages() {
+		if !devOnly[hexRepoName(pkg.Name)] {
+			pkgs = append(pkgs, pkg)
+		}
+	}
+	client := hex.NewClient(gc.hexMirror, gc.hexCacheDir)
+	dirs, err := client.FetchAll(context.Background(), pkgs)
+	if err != nil {
+		return nil, fmt.Errorf("failed to download deps packages: %w", err)
+	}
+
+	deps := &packageDirs{dirs: dirs, cleanup: func() {}}
+	for _, pkg := range manifestToml.Packages {
+		if devOnly[hexRepoName(pkg.Name)] {
+			continue
+		}
+		switch pkg.Origin() {
+		case hex.SourceLocal:
+			deps.dirs[pkg.Name] = filepath.Join(c.RepoRoot, filepath.FromSlash(pkg.Path))
+		case hex.SourceGit:
+			// Only the repository of the package has its checkout.
+			log.Printf("%s: modules of git package %s are not indexed", c.RepoRoot, pkg.Name)
+			continue
+		}
+		if _, ok := deps.dirs[pkg.Name]; ok {
+			deps.packages = append(deps.packages, pkg.Name)
+		}
+	}
+	return deps, nil
+}
+
+func downloadDepsWithCompiler(c *config.Config, gc *GleamConfig) (*packageDirs, error) {
+	if len(gc.gleamCompilerPath) == 0 {
+		return nil, fmt.Errorf("gleam compiler not provided.")
+	}
+
+	// Download in a temporary directory, from a copy of gleam.toml without
+	// dev dependencies (minimize fetch). The repository is left untouched.
+	downloadDir, err := os.MkdirTemp("", "gleam_deps")
+	if err != nil {
+		return nil, err
+	}
+	cleanup := func() { os.RemoveAll(downloadDir) }
+	if err := writeDownloadGleamToml(c.RepoRoot, downloadDir); err != nil {
+		cleanup()
+		return nil, err
+	}
+
 	// fetch repos
 	downloaded := exec.Command(gc.gleamCompilerPath, "deps", "download")
-	downloaded.Dir = c.RepoRoot
+	downloaded.Dir = downloadDir
 	_, err = downloaded.Output()
 	if err != nil {
-		return fmt.Errorf("failed to download deps packages %v", err)
+		cleanup()
+		return nil, fmt.Errorf("failed to download deps packages %v", err)
 	}
 
 	// parse manifest.toml since it contains all of the indirect dependencies.
-	manifestToml, err := parseManifestToml(c.RepoRoot)
+	manifestToml, err := parseManifestToml(downloadDir)
 	if err != nil {
-		return err
+		cleanup()
+		return nil, err
+	}
+	if manifestToml == nil {
+		cleanup()
+		return nil, fmt.Errorf("no manifest.toml after downloading deps packages")
 	}
-	
 
+	dirs := &packageDirs{dirs: map[string]string{}, cleanup: cleanup}
 	for _, manifestPackage := range manifestToml.Packages {
-		gleamPackage := manifestPackage.Name
-		module := fmt.Sprintf("hex_%s", gleamPackage)
-		externalPath := filepath.Join(c.RepoRoot, "build", "packages", gleamPackage, "src")
-		_, err := os.Stat(externalPath)
-		if err != nil {
-			return err
+		dirs.packages = append(dirs.packages, manifestPackage.Name)
+		dirs.dirs[manifestPackage.Name] = filepath.Join(downloadDir, "build", "packages", manifestPackage.Name)
+	}
+	return dirs, nil
+}
+
+// hexRepoName returns the name of the repository of a Hex package.
+func hexRepoName(gleamPackage string) string {
+	return fmt.Sprintf("hex_%s", gleamPackage)
+}
+
+// markDevOnlyModules marks the modules of the packages only required by the
+// dev-dependencies of the gleam.toml at the repository root as dev-only.
+func markDevOnlyModules(repoRoot string, modules *moduleIndex) error {
+	gleamToml, err := parseGleamToml(repoRoot)
+	if err != nil {
+		return err
+	}
+	if gleamToml == nil || len(gleamToml.DevDependencies) == 0 {
+		return nil
+	}
+	manifestToml, err := parseManifestToml(repoRoot)
+	if err != nil {
+		return err
+	}
+	if manifestToml == nil {
+		return nil
+	}
+
+	modules.markDevOnly(devOnlyPackages(gleamToml, manifestToml))
+	return nil
+}
+
+// devOnlyPackages returns the repository names of the packages required by
+// the dev-dependencies, directly or not, but not by the dependencies.
+func devOnlyPackages(gleamToml *GleamToml, manifestToml *hex.Manifest) map[string]bool {
+	requirements := make(map[string][]string)
+	for _, pkg := range manifestToml.Packages {
+		requirements[pkg.Name] = pkg.Requirements
+	}
+	required := func(direct []string) map[string]bool {
+		seen := make(map[string]bool)
+		for len(direct) > 0 {
+			pkg := direct[0]
+			direct = direct[1:]
+			if seen[pkg] {
+				continue
+			}
+			seen[pkg] = true
+			direct = append(direct, requirements[pkg]...)
 		}
+		return seen
+	}
 
-		foundRepos, err := walkDirForRepos(c, externalPath, module)
-		*repos = append(*repos, foundRepos...)
-		if err != nil {
-			return err
+	deps := required(collect(gleamToml.Dependencies))
+	devOnly := make(map[string]bool)
+	for pkg := range required(collect(gleamToml.DevDependencies)) {
+		if !deps[pkg] {
+			devOnly[hexRepoName(pkg)] = true
 		}
 	}
-	return nil
+	return devOnly
 }
 
 // Given: /blah/blah/external/rules_gleam++gleam+gleam_stdlib
@@ -205,7 +512,9 @@ func getRepoNameFromPath(path string) string {
 	return repoComponents[len(repoComponents)-1]
 }
 
-func maybePopulateRemoteCacheFromBzlMod(c *config.Config, repos *[]repo.Repo) error {
+// indexBzlModDeps returns the modules of the gleam_repository repositories
+// of the gleam_hex_repositories_config module.
+func indexBzlModDeps(c *config.Config) (*moduleIndex, error) {
 	configModuleName := c.ModuleToApparentName("gleam_hex_repositories_config")
 	if configModuleName == "" {
 		configModuleName = "gleam_hex_repositories_config"
@@ -213,17 +522,18 @@ func maybePopulateRemoteCacheFromBzlMod(c *config.Config, repos *[]repo.Repo) er
 	rf, _ := runfiles.New()
 	buildFile, err := rf.Rlocation(fmt.Sprintf("%s/BUILD.bazel", configModuleName))
 	if err != nil {
-		return err
+		return nil, err
 	}
 	content, err := os.ReadFile(buildFile)
 	if err != nil {
-		return err
+		return nil, err
 	}
 	buildFileParsed, err := build.ParseBuild(fmt.Sprintf("%s:BUILD.bazel", configModuleName), content)
 	if err != nil {
-		return err
+		return nil, err
 	}
 	configModuleDirName := filepath.Base(filepath.Dir(buildFile))
+	modules := newModuleIndex()
 
 	for _, gleamRepo := range buildFileParsed.Rules("gleam_repository") {
 		module := gleamRepo.AttrString("module_name")
@@ -232,17 +542,21 @@ func maybePopulateRemoteCacheFromBzlMod(c *config.Config, repos *[]repo.Repo) er
 		var mu sync.Mutex
 		var wg sync.WaitGroup
 		wg.Go(func() {
-			parallelAppendRepos(c, rf, &mu, module, moduleDirName, repos)
+			parallelIndexRepo(rf, &mu, module, moduleDirName, modules)
 		})
 		wg.Wait()
 
 	}
-	return nil
+	return modules, nil
 }
 
-func walkDirForRepos(c *config.Config, dir string, bazelModule string) (repos []repo.Repo, err error) {
-	gleamModules := []string{}
-	err = filepath.Walk(dir, func(path string, info os.FileInfo, err error) error {
+// indexPackageModules returns the Gleam, Erlang and Elixir modules of the
+// package of a remote repository. Erlang modules are named by their -module()
+// attribute, and are built by a <file>_ffi gleam_erl_library in the package
+// of their directory.
+func indexPackageModules(dir string, bazelModule string) (*moduleIndex, error) {
+	modules := newModuleIndex()
+	err := filepath.Walk(dir, func(path string, info os.FileInfo, err error) error {
 		if err != nil {
 			return err
 		}
@@ -256,30 +570,99 @@ func walkDirForRepos(c *config.Config, dir string, bazelModule string) (repos []
 			}
 			modulePath := strings.TrimSuffix(relPath, ".gleam")
 			modulePath = strings.ReplaceAll(modulePath, string(os.PathSeparator), "/")
-			gleamModules = append(gleamModules, modulePath)
+			modules.addGleam(bazelModule, modulePath)
 		} else if strings.HasSuffix(path, ".erl") {
-			relPath := filepath.Base(path)
-			importPath := fmt.Sprintf("%s%s", "erl:", strings.TrimSuffix(relPath, ".erl"))
-			gleamModules = append(gleamModules, importPath)
+			relPath, err := filepath.Rel(dir, path)
+			if err != nil {
+				return err
+			}
+			fileModule := strings.TrimSuffix(filepath.Base(relPath), ".erl")
+			// Erlang compiled from Gleam modules has no gleam_erl_library,
+			// see GenerateRules, and resolves to the Gleam module.
+			if strings.Contains(fileModule, "@") {
+				gleamModule := strings.ReplaceAll(fileModule, "@", "/")
+				modules.addErlang(bazelModule, fileModule, gleamModuleLabel(bazelModule, gleamModule))
+				return nil
+			}
+			module, err := erlModuleName(path)
+			if err != nil {
+				return err
+			}
+			pkg := filepath.ToSlash(filepath.Dir(relPath))
+			if pkg == "." {
+				pkg = ""
+			}
+			modules.addErlang(bazelModule, module, label.New(bazelModule, pkg, fmt.Sprintf("%s_ffi", fileModule)))
+		} else if strings.HasSuffix(path, ".ex") {
+			// Elixir modules of mix packages aren't built, they're only
+			// known to be provided by the package, see gleam_elixir_package.
+			elixirModules, err := elixirModuleNames(path)
+			if err != nil {
+				return err
+			}
+			for _, module := range elixirModules {
+				modules.addElixir(bazelModule, module)
+			}
 		}
 		return nil
 	})
-
 	if err != nil {
 		return nil, err
 	}
+	return modules, nil
+}
 
-	for _, gleamModule := range gleamModules {
-		repos = append(repos, repo.Repo{
-			Name:     bazelModule,
-			GoPrefix: gleamModule, // Using GoPrefix for now, will need to adjust for Gleam specific prefix if any
-		})
+// erlModuleAttr matches the -module() attribute of an Erlang source file.
+var erlModuleAttr = regexp.MustCompile(`(?m)^\s*-module\(\s*('(?:[^'\\]|\\.)*'|[a-z][a-zA-Z0-9_@]*)\s*\)`)
+
+// erlModuleName returns the name an Erlang source file declares with its
+// -module() attribute, or the name of the file without one.
+func erlModuleName(path string) (string, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return "", err
+	}
+	match := erlModuleAttr.FindSubmatch(data)
+	if match == nil {
+		return strings.TrimSuffix(filepath.Base(path), ".erl"), nil
 	}
-	return repos, nil
+	return strings.Trim(string(match[1]), "'"), nil
+}
+
+// elixirDefmodule matches the defmodule of an Elixir source file, with its
+// indentation.
+var elixirDefmodule = regexp.MustCompile(`(?m)^([ \t]*)defmodule\s+([A-Z][A-Za-z0-9_]*(?:\.[A-Z][A-Za-z0-9_]*)*)\s+do\b`)
 
+// elixirModuleNames returns the Erlang names of the modules an Elixir source
+// file defines, e.g. "Elixir.Jason.Encoder". Nested modules are named after
+// the modules they're indented in.
+func elixirModuleNames(path string) ([]string, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	type parent struct {
+		indent int
+		name   string
+	}
+	var parents []parent
+	var modules []string
+	for _, match := range elixirDefmodule.FindAllSubmatch(data, -1) {
+		indent := len(match[1])
+		for len(parents) > 0 && parents[len(parents)-1].indent >= indent {
+			parents = parents[:len(parents)-1]
+		}
+		name := string(match[2])
+		if len(parents) > 0 {
+			name = parents[len(parents)-1].name + "." + name
+		}
+		parents = append(parents, parent{indent: indent, name: name})
+		modules = append(modules, "Elixir."+name)
+	}
+	return modules, nil
 }
 
-func parallelAppendRepos(c *config.Config, rf *runfiles.Runfiles, mu *sync.Mutex, module string, moduleDirName string, repos *[]repo.Repo) {
+func parallelIndexRepo(rf *runfiles.Runfiles, mu *sync.Mutex, module string, moduleDirName string, modules *moduleIndex) {
 	moduleBuild, err := rf.Rlocation(fmt.Sprintf("%s/BUILD.bazel", moduleDirName))
 	if err != nil {
 		log.Printf("Could not find module directory for %s: %v", module, err)
@@ -290,15 +673,27 @@ func parallelAppendRepos(c *config.Config, rf *runfiles.Runfiles, mu *sync.Mutex
 		return
 	}
 
-	foundRepos, _ := walkDirForRepos(c, filepath.Dir(moduleBuild), module)
+	found, _ := indexPackageModules(filepath.Dir(moduleBuild), module)
+	if found == nil {
+		return
+	}
 	mu.Lock()
 	defer mu.Unlock()
-	*repos = append(*repos, foundRepos...)
+	modules.merge(found)
 }
 
 func (g *gleamLanguage) KnownDirectives() []string {
 	return []string{
 		"gleam_visibility",
+		"gleam_target",
+		"gleam_resolve",
+		"gleam_exclude",
+		"gleam_ignore",
+		"gleam_generation_mode",
+		"gleam_test_naming",
+		"gleam_test_mode",
+		"gleam_otp_version",
+		"gleam_elixir_package",
 	}
 }
 
@@ -307,6 +702,32 @@ func (g *gleamLanguage) KnownDirectives() []string {
 // It reads the "gleam_visibility" directive, which specifies the visibility
 // of the target. Multiple values are allowed.
 //
+// It reads the "gleam_target" directive, which specifies the compilation
+// targets (erlang, javascript or both) dependencies are generated for.
+//
+// It reads the "gleam_resolve" directive, which maps a Gleam module or an
+// "erl:" FFI import to the label to depend on, e.g.
+// "# gazelle:gleam_resolve my/wrapper //third_party/wrapper".
+//
+// It reads the "gleam_exclude" directive, a glob pattern relative to the
+// directory of files and directories to leave out, and the "gleam_ignore"
+// directive, which leaves out the directory and its subdirectories.
+//
+// It reads the "gleam_generation_mode" directive, which generates one
+// gleam_library per module (module) or per directory (package).
+//
+// It reads the "gleam_test_naming" directive, a glob pattern relative to the
+// directory of the files holding test modules, and the
+// "gleam_test_mode" directive, which generates one gleam_test per directory
+// (package) or per test module (file).
+//
+// It reads the "gleam_otp_version" directive, the OTP release whose modules
+// "erl:" imports aren't resolved for, e.g. "27".
+//
+// It reads the "gleam_elixir_package" directive, which maps a mix package of
+// manifest.toml to the label "erl:Elixir.*" imports of its modules depend on,
+// e.g. "# gazelle:gleam_elixir_package jason @elixir_deps//:jason".
+//
 // This is called per directory, child directory inherits config from the parent's.
 func (g *gleamLanguage) Configure(c *config.Config, rel string, f *rule.File) {
 	var config *GleamConfig
@@ -322,11 +743,172 @@ func (g *gleamLanguage) Configure(c *config.Config, rel string, f *rule.File) {
 			switch d.Key {
 			case "gleam_visibility":
 				config.gleamVisibility = append(config.gleamVisibility, strings.TrimSpace(d.Value))
+			case "gleam_target":
+				target := strings.TrimSpace(d.Value)
+				switch target {
+				case targetErlang, targetJavascript, targetBoth:
+					config.target = target
+				default:
+					log.Printf("%s: invalid gleam_target %q, must be one of %s, %s or %s", f.Path, d.Value, targetErlang, targetJavascript, targetBoth)
+				}
+			case "gleam_resolve":
+				args := strings.Fields(d.Value)
+				if len(args) != 2 {
+					log.Printf("%s: invalid gleam_resolve %q, expected an import and a label", f.Path, d.Value)
+					continue
+				}
+				l, err := label.Parse(args[1])
+				if err != nil {
+					log.Printf("%s: invalid gleam_resolve label %q: %v", f.Path, args[1], err)
+					continue
+				}
+				if config.resolveOverrides == nil {
+					config.resolveOverrides = make(map[string]label.Label)
+				}
+				config.resolveOverrides[args[0]] = l.Abs("", rel)
+			case "gleam_exclude":
+				pattern := path.Join(rel, strings.TrimSpace(d.Value))
+				if !doublestar.ValidatePattern(pattern) {
+					log.Printf("%s: invalid gleam_exclude pattern %q", f.Path, d.Value)
+					continue
+				}
+				config.excludes = append(config.excludes, pattern)
+			case "gleam_ignore":
+				config.ignore = true
+			case "gleam_generation_mode":
+				mode := strings.TrimSpace(d.Value)
+				switch mode {
+				case generationModeModule, generationModePackage:
+					config.mode = mode
+				default:
+					log.Printf("%s: invalid gleam_generation_mode %q, must be one of %s or %s", f.Path, d.Value, generationModeModule, generationModePackage)
+				}
+			case "gleam_test_naming":
+				pattern := path.Join(rel, strings.TrimSpace(d.Value))
+				if !doublestar.ValidatePattern(pattern) {
+					log.Printf("%s: invalid gleam_test_naming pattern %q", f.Path, d.Value)
+					continue
+				}
+				config.testNaming = append(config.testNaming, pattern)
+			case "gleam_test_mode":
+				mode := strings.TrimSpace(d.Value)
+				switch mode {
+				case testModePackage, testModeFile:
+					config.testMode = mode
+				default:
+					log.Printf("%s: invalid gleam_test_mode %q, must be one of %s or %s", f.Path, d.Value, testModePackage, testModeFile)
+				}
+			case "gleam_otp_version":
+				if config.otpRoot != "" {
+					log.Printf("%s: gleam_otp_version is ignored, the OTP modules are read from -gleam_otp_root %s", f.Path, config.otpRoot)
+					continue
+				}
+				modules, err := otp.ForVersion(d.Value)
+				if err != nil {
+					log.Printf("%s: invalid gleam_otp_version: %v", f.Path, err)
+					continue
+				}
+				config.otpModules = modules
+			case "gleam_elixir_package":
+				args := strings.Fields(d.Value)
+				if len(args) != 2 {
+					log.Printf("%s: invalid gleam_elixir_package %q, expected a package and a label", f.Path, d.Value)
+					continue
+				}
+				l, err := label.Parse(args[1])
+				if err != nil {
+					log.Printf("%s: invalid gleam_elixir_package label %q: %v", f.Path, args[1], err)
+					continue
+				}
+				if config.elixirPackages == nil {
+					config.elixirPackages = make(map[string]label.Label)
+				}
+				config.elixirPackages[args[0]] = l.Abs("", rel)
 			}
 		}
 	}
 }
 
+// generationTarget returns the compilation target(s) dependencies are
+// generated for.
+func (c *GleamConfig) generationTarget() string {
+	if c.target == "" {
+		return targetErlang
+	}
+	return c.target
+}
+
+// generationMode returns how Gleam modules are grouped into gleam_library
+// rules.
+func (c *GleamConfig) generationMode() string {
+	if c.mode == "" {
+		return generationModeModule
+	}
+	return c.mode
+}
+
+// erlangModules returns the OTP modules "erl:" imports aren't resolved for.
+func (c *GleamConfig) erlangModules() *otp.Modules {
+	if c.otpModules == nil {
+		modules, _ := otp.ForVersion(otp.DefaultVersion)
+		return modules
+	}
+	return c.otpModules
+}
+
+// isExcluded reports whether a file or directory, relative to the repository
+// root, is left out of rule generation by a gleam_exclude or gleam_ignore
+// directive. A path is also excluded when one of its parent directories is.
+func (c *GleamConfig) isExcluded(rel string) bool {
+	if c.ignore {
+		return true
+	}
+	for p := rel; p != "." && p != "/" && p != ""; p = path.Dir(p) {
+		for _, pattern := range c.excludes {
+			if matched, _ := doublestar.Match(pattern, p); matched {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// isTestFile reports whether a Gleam file, relative to the repository root,
+// holds a test module: its name ends with _test.gleam or _tests.gleam, or it
+// matches a gleam_test_naming pattern.
+func (c *GleamConfig) isTestFile(rel string) bool {
+	if strings.HasSuffix(rel, "_test.gleam") || strings.HasSuffix(rel, "_tests.gleam") {
+		return true
+	}
+	for _, pattern := range c.testNaming {
+		if matched, _ := doublestar.Match(pattern, rel); matched {
+			return true
+		}
+	}
+	return false
+}
+
+// isTestOnlyDir reports whether a directory, relative to the repository root,
+// is under a test directory, where Gleam keeps its test code. The modules
+// there that aren't test modules are only meant to be used by tests.
+func isTestOnlyDir(rel string) bool {
+	for p := rel; p != "." && p != "/" && p != ""; p = path.Dir(p) {
+		if path.Base(p) == "test" {
+			return true
+		}
+	}
+	return false
+}
+
+// generationTestMode returns how test modules are grouped into gleam_test
+// rules.
+func (c *GleamConfig) generationTestMode() string {
+	if c.testMode == "" {
+		return testModePackage
+	}
+	return c.testMode
+}
+
 func GetGleamConfig(c *config.Config) *GleamConfig {
 	return c.Exts[languageName].(*GleamConfig)
 }
@@ -1,30 +1,30 @@
 package gleam
 
 import (
+	"fmt"
 	"os"
 	"path/filepath"
-	"slices"
 	"strings"
 	"testing"
 
 	"github.com/bazelbuild/bazel-gazelle/config"
-	"github.com/bazelbuild/bazel-gazelle/repo"
+	"github.com/bazelbuild/bazel-gazelle/label"
 	"github.com/google/go-cmp/cmp"
+	"github.com/google/go-cmp/cmp/cmpopts"
+	"github.com/iocat/rules_gleam/internal/hex"
+	"github.com/iocat/rules_gleam/internal/hex/hextest"
 )
 
-func TestMaybePopulateRemoteCacheFromGleamTomlForExternalRepo(t *testing.T) {
+func TestIndexExternalRepoDeps(t *testing.T) {
 	testCases := []struct {
 		desc                string
 		gleamTomlContent    string
 		manifestTomlContent string
-		compilerPath        string
-		existingRepos       []repo.Repo
-		wantRepos           []repo.Repo
+		wantModules         map[string]label.Label
 		wantErr             bool
 	}{
 		{
 			desc:         "no gleam.toml",
-			compilerPath: "echo", // Provide a default compiler path
 			wantErr:      false,
 		},
 		{
@@ -56,12 +56,8 @@ otp_app = "gleam_stdlib"
 source = "hex"
 outer_checksum = "valid_checksum"
 `,
-			compilerPath: "echo", // Mock compiler path
-			wantRepos: []repo.Repo{
-				{
-					Name:     "hex_gleam_stdlib",
-					GoPrefix: "gleam_stdlib",
-				},
+			wantModules: map[string]label.Label{
+				"gleam_stdlib": label.New("hex_gleam_stdlib", "", "gleam_stdlib"),
 			},
 			wantErr: false,
 		},
@@ -94,24 +90,42 @@ otp_app = "gleam_otp"
 source = "hex"
 outer_checksum = "valid_checksum"
 `,
-			compilerPath: "echo", // Mock compiler path
-			wantRepos: []repo.Repo{
-				{
-					Name:     "hex_gleam_stdlib",
-					GoPrefix: "gleam_stdlib",
-				},
-				{
-					Name:     "hex_gleam_otp",
-					GoPrefix: "gleam_otp",
-				},
-				{
-					Name:     "hex_gleam_stdlib",
-					GoPrefix: "erl:gleam_stdlib_ffi",
-				},
-				{
-					Name:     "hex_gleam_otp",
-					GoPrefix: "erl:gleam_otp_ffi",
-				},
+			wantModules: map[string]label.Label{
+				"gleam_stdlib":         label.New("hex_gleam_stdlib", "", "gleam_stdlib"),
+				"gleam_otp":            label.New("hex_gleam_otp", "", "gleam_otp"),
+				"erl:gleam_stdlib_ffi": label.New("hex_gleam_stdlib", "", "gleam_stdlib_ffi_ffi"),
+				"erl:gleam_otp_ffi":    label.New("hex_gleam_otp", "", "gleam_otp_ffi_ffi"),
+			},
+			wantErr: false,
+		},
+		{
+			desc: "dev dependencies and unmodelled sections",
+			gleamTomlContent: `
+# The package.
+name = "test_package"
+version = "0.1.0"
+
+[dependencies]
+  gleam_stdlib = "0.20.4"
+
+[dev-dependencies]
+  gleeunit = "1.0.0"
+
+[javascript]
+  typescript_declarations = true
+`,
+			manifestTomlContent: `
+[[packages]]
+name = "gleam_stdlib"
+version = "0.20.4"
+build_tools = []
+requirements = []
+otp_app = "gleam_stdlib"
+source = "hex"
+outer_checksum = "valid_checksum"
+`,
+			wantModules: map[string]label.Label{
+				"gleam_stdlib": label.New("hex_gleam_stdlib", "", "gleam_stdlib"),
 			},
 			wantErr: false,
 		},
@@ -134,16 +148,9 @@ otp_app = "gleam_stdlib"
 source = "hex"
 outer_checksum = "valid_checksum"
 `,
-			compilerPath: "echo", // Mock compiler path
-			wantRepos: []repo.Repo{
-				{
-					Name:     "hex_gleam_stdlib",
-					GoPrefix: "gleam_stdlib",
-				},
-				{
-					Name:     "hex_gleam_stdlib",
-					GoPrefix: "erl:gleam_stdlib_ffi",
-				},
+			wantModules: map[string]label.Label{
+				"gleam_stdlib":         label.New("hex_gleam_stdlib", "", "gleam_stdlib"),
+				"erl:gleam_stdlib_ffi": label.New("hex_gleam_stdlib", "", "gleam_stdlib_ffi_ffi"),
 			},
 			wantErr: false,
 		},
@@ -164,28 +171,37 @@ outer_checksum = "valid_checksum"
 				}
 			}
 
+			// What "gleam deps download" writes, copied by a fake compiler. It
+			// fails if it's given the dev dependencies.
+			downloadDir := t.TempDir()
+			compilerPath := filepath.Join(t.TempDir(), "gleam")
+			compiler := fmt.Sprintf("#!/bin/sh\nif grep -q dev-dependencies gleam.toml; then exit 1; fi\ncp -R %q/. .\n", downloadDir)
+			if err := os.WriteFile(compilerPath, []byte(compiler), 0755); err != nil {
+				t.Fatalf("Failed to write fake compiler: %v", err)
+			}
+
 			if tc.manifestTomlContent != "" {
-				manifestTomlPath := filepath.Join(repoRoot, "manifest.toml")
+				manifestTomlPath := filepath.Join(downloadDir, "manifest.toml")
 				if err := os.WriteFile(manifestTomlPath, []byte(tc.manifestTomlContent), 0644); err != nil {
 					t.Fatalf("Failed to write manifest.toml: %v", err)
 				}
 			}
 
 			// Create build/packages directory and mock source files
-			if len(tc.wantRepos) > 0 {
-				buildPackagesPath := filepath.Join(repoRoot, "build", "packages")
+			if len(tc.wantModules) > 0 {
+				buildPackagesPath := filepath.Join(downloadDir, "build", "packages")
 				if err := os.MkdirAll(buildPackagesPath, 0755); err != nil {
 					t.Fatalf("Failed to create build/packages directory: %v", err)
 				}
 
-				for _, wantRepo := range tc.wantRepos {
-					packageName := strings.TrimPrefix(wantRepo.Name, "hex_")
+				for imp, l := range tc.wantModules {
+					packageName := strings.TrimPrefix(l.Repo, "hex_")
 					packageSrcPath := filepath.Join(buildPackagesPath, packageName, "src")
 					if err := os.MkdirAll(packageSrcPath, 0755); err != nil {
 						t.Fatalf("Failed to create package src directory: %v", err)
 					}
 
-					if strings.HasPrefix(wantRepo.GoPrefix, "erl:") {
+					if strings.HasPrefix(imp, "erl:") {
 						erlFfiPath := filepath.Join(packageSrcPath, packageName+"_ffi.erl")
 						if err := os.WriteFile(erlFfiPath, []byte(""), 0644); err != nil {
 							t.Fatalf("Failed to write mock .gleam file: %v", err)
@@ -205,28 +221,271 @@ outer_checksum = "valid_checksum"
 				Exts:     map[string]interface{}{"gleam": &GleamConfig{}},
 			}
 			gc := &GleamConfig{
-				gleamCompilerPath: tc.compilerPath,
+				gleamCompilerPath: compilerPath,
 				externalRepo:      true,
 			}
 
-			var repos []repo.Repo
-			repos = append(repos, tc.existingRepos...)
-
-			err = maybePopulateRemoteCacheFromGleamTomlForExternalRepo(c, gc, &repos)
+			modules, err := indexExternalRepoDeps(c, gc)
 
 			if (err != nil) != tc.wantErr {
-				t.Fatalf("maybePopulateRemoteCacheFromGleamTomlForExternalRepo() error = %v, wantErr %v", err, tc.wantErr)
+				t.Fatalf("indexExternalRepoDeps() error = %v, wantErr %v", err, tc.wantErr)
+			}
+
+			if tc.gleamTomlContent != "" {
+				gleamToml, err := os.ReadFile(filepath.Join(repoRoot, "gleam.toml"))
+				if err != nil {
+					t.Fatalf("Failed to read gleam.toml: %v", err)
+				}
+				if diff := cmp.Diff(tc.gleamTomlContent, string(gleamToml)); diff != "" {
+					t.Errorf("gleam.toml was modified (-want +got):\n%s", diff)
+				}
+			}
+			if _, err := os.Stat(filepath.Join(repoRoot, "build")); err == nil {
+				t.Errorf("deps were downloaded in the repository")
 			}
 
-			slices.SortFunc(tc.wantRepos, sortFunc)
-			slices.SortFunc(repos, sortFunc)
-			if diff := cmp.Diff(tc.wantRepos, repos); diff != "" {
-				t.Errorf("maybePopulateRemoteCacheFromGleamTomlForExternalRepo() mismatch (-want +got):\n%s", diff)
+			if diff := cmp.Diff(tc.wantModules, indexLabels(modules), cmpopts.EquateEmpty()); diff != "" {
+				t.Errorf("indexExternalRepoDeps() mismatch (-want +got):\n%s", diff)
 			}
 		})
 	}
 }
 
-func sortFunc (a, b repo.Repo) int {
-	return strings.Compare(a.GoPrefix, b.GoPrefix)
-}
\ No newline at end of file
+// indexLabels returns the labels of the modules of an index, by import.
+func indexLabels(ix *moduleIndex) map[string]label.Label {
+	labels := make(map[string]label.Label)
+	for imp, m := range ix.modules {
+		labels[imp] = m.label
+	}
+	return labels
+}
+
+func TestDevOnlyPackages(t *testing.T) {
+	gleamToml := &GleamToml{
+		Dependencies: map[string]any{
+			"gleam_stdlib": ">= 0.44.0 and < 2.0.0",
+			"gleam_json":   ">= 2.0.0 and < 3.0.0",
+		},
+		DevDependencies: map[string]any{
+			"gleeunit": ">= 1.0.0 and < 2.0.0",
+			"qcheck":   ">= 1.0.0 and < 2.0.0",
+		},
+	}
+	manifestToml := &hex.Manifest{
+		Packages: []hex.Package{
+			{Name: "gleam_json", Requirements: []string{"gleam_stdlib"}},
+			{Name: "gleam_stdlib"},
+			{Name: "gleeunit", Requirements: []string{"gleam_stdlib"}},
+			{Name: "qcheck", Requirements: []string{"exception", "gleam_stdlib", "gleam_yielder"}},
+			{Name: "exception"},
+			{Name: "gleam_yielder", Requirements: []string{"gleam_stdlib"}},
+		},
+	}
+
+	want := map[string]bool{
+		"hex_exception":     true,
+		"hex_gleam_yielder": true,
+		"hex_gleeunit":      true,
+		"hex_qcheck":        true,
+	}
+	if diff := cmp.Diff(want, devOnlyPackages(gleamToml, manifestToml)); diff != "" {
+		t.Errorf("devOnlyPackages() mismatch (-want +got):\n%s", diff)
+	}
+}
+
+func TestWriteDownloadGleamToml(t *testing.T) {
+	root := t.TempDir()
+	srcDir := filepath.Join(root, "app")
+	files := map[string]string{
+		"gleam.toml": `
+name = "app"
+
+[dependencies]
+gleam_stdlib = ">= 0.60.0 and < 2.0.0"
+shared = { path = "../shared" }
+
+[dev-dependencies]
+gleeunit = ">= 1.0.0 and < 2.0.0"
+`,
+		"manifest.toml": `
+packages = [
+  { name = "gleam_stdlib", version = "0.60.0", build_tools = ["gleam"], requirements = [], otp_app = "gleam_stdlib", source = "hex", outer_checksum = "STDLIB" },
+  { name = "shared", version = "0.1.0", build_tools = ["gleam"], requirements = ["gleam_stdlib"], otp_app = "shared", source = "local", path = "../shared" },
+]
+
+[requirements]
+gleam_stdlib = { version = ">= 0.60.0 and < 2.0.0" }
+shared = { path = "../shared" }
+`,
+	}
+	if err := os.MkdirAll(srcDir, 0755); err != nil {
+		t.Fatal(err)
+	}
+	for name, content := range files {
+		if err := os.WriteFile(filepath.Join(srcDir, name), []byte(content), 0644); err != nil {
+			t.Fatalf("Failed to write %s: %v", name, err)
+		}
+	}
+
+	dstDir := t.TempDir()
+	if err := writeDownloadGleamToml(srcDir, dstDir); err != nil {
+		t.Fatalf("writeDownloadGleamToml() failed: %v", err)
+	}
+
+	shared := filepath.Join(root, "shared")
+	gleamToml, err := parseGleamToml(dstDir)
+	if err != nil {
+		t.Fatalf("parseGleamToml() failed: %v", err)
+	}
+	wantGleamToml := &GleamToml{
+		Name: "app",
+		Dependencies: map[string]any{
+			"gleam_stdlib": ">= 0.60.0 and < 2.0.0",
+			"shared":       map[string]any{"path": shared},
+		},
+	}
+	if diff := cmp.Diff(wantGleamToml, gleamToml); diff != "" {
+		t.Errorf("gleam.toml mismatch (-want +got):\n%s", diff)
+	}
+
+	manifest, err := parseManifestToml(dstDir)
+	if err != nil {
+		t.Fatalf("parseManifestToml() failed: %v", err)
+	}
+	wantManifest := &hex.Manifest{
+		Packages: []hex.Package{
+			{Name: "gleam_stdlib", Version: "0.60.0", BuildTools: []string{"gleam"}, Requirements: []string{}, OtpApp: "gleam_stdlib", Source: "hex", OuterChecksum: "STDLIB"},
+			{Name: "shared", Version: "0.1.0", BuildTools: []string{"gleam"}, Requirements: []string{"gleam_stdlib"}, OtpApp: "shared", Source: "local", Path: shared},
+		},
+		Requirements: map[string]hex.Requirement{
+			"gleam_stdlib": {Version: ">= 0.60.0 and < 2.0.0"},
+			"shared":       {Path: shared},
+		},
+	}
+	if diff := cmp.Diff(wantManifest, manifest, cmpopts.EquateEmpty()); diff != "" {
+		t.Errorf("manifest.toml mismatch (-want +got):\n%s", diff)
+	}
+}
+
+func TestIndexExternalRepoDepsFromManifestToml(t *testing.T) {
+	stdlib, stdlibChecksum := hextest.Tarball(t, map[string]string{
+		"gleam.toml":               `name = "gleam_stdlib"`,
+		"src/gleam/list.gleam":     "",
+		"src/gleam_stdlib_ffi.erl": "",
+		"include/gleam_stdlib.hrl": "",
+	})
+	gleeunit, gleeunitChecksum := hextest.Tarball(t, map[string]string{
+		"src/gleeunit.gleam": "",
+	})
+	srv := hextest.NewServer(t, map[string][]byte{
+		"gleam_stdlib-0.60.0": stdlib,
+		"gleeunit-1.0.0":      gleeunit,
+	})
+
+	repoRoot := t.TempDir()
+	files := map[string]string{
+		"gleam.toml": `
+name = "test_package"
+version = "0.1.0"
+
+[dependencies]
+  gleam_stdlib = ">= 0.60.0 and < 2.0.0"
+  shared = { path = "vendor/shared" }
+
+[dev-dependencies]
+  gleeunit = ">= 1.0.0 and < 2.0.0"
+`,
+		"manifest.toml": fmt.Sprintf(`
+packages = [
+  { name = "gleam_stdlib", version = "0.60.0", build_tools = ["gleam"], requirements = [], otp_app = "gleam_stdlib", source = "hex", outer_checksum = "%s" },
+  { name = "gleeunit", version = "1.0.0", build_tools = ["gleam"], requirements = ["gleam_stdlib"], otp_app = "gleeunit", source = "hex", outer_checksum = "%s" },
+  { name = "shared", version = "0.1.0", build_tools = ["gleam"], requirements = ["gleam_stdlib"], otp_app = "shared", source = "local", path = "vendor/shared" },
+]
+`, stdlibChecksum, gleeunitChecksum),
+		"vendor/shared/src/shared/text.gleam": "",
+	}
+	for name, content := range files {
+		if err := os.MkdirAll(filepath.Dir(filepath.Join(repoRoot, name)), 0755); err != nil {
+			t.Fatalf("Failed to create directory of %s: %v", name, err)
+		}
+		if err := os.WriteFile(filepath.Join(repoRoot, name), []byte(content), 0644); err != nil {
+			t.Fatalf("Failed to write %s: %v", name, err)
+		}
+	}
+
+	c := &config.Config{
+		RepoRoot: repoRoot,
+		Exts:     map[string]interface{}{"gleam": &GleamConfig{}},
+	}
+	// No compiler: the locked packages are downloaded from the mirror.
+	gc := &GleamConfig{
+		externalRepo: true,
+		hexMirror:    srv.URL,
+		hexCacheDir:  t.TempDir(),
+	}
+	modules, err := indexExternalRepoDeps(c, gc)
+	if err != nil {
+		t.Fatalf("indexExternalRepoDeps() failed: %v", err)
+	}
+
+	wantModules := map[string]label.Label{
+		"gleam/list":           label.New("hex_gleam_stdlib", "gleam", "list"),
+		"erl:gleam_stdlib_ffi": label.New("hex_gleam_stdlib", "", "gleam_stdlib_ffi_ffi"),
+		"shared/text":          label.New("hex_shared", "shared", "text"),
+	}
+	if diff := cmp.Diff(wantModules, indexLabels(modules)); diff != "" {
+		t.Errorf("indexExternalRepoDeps() mismatch (-want +got):\n%s", diff)
+	}
+	// Only the dev dependency isn't downloaded.
+	if n := srv.Requests.Load(); n != 1 {
+		t.Errorf("got %d requests to the Hex mirror, want 1", n)
+	}
+}
+
+func TestIndexPackageModules(t *testing.T) {
+	dir := t.TempDir()
+	files := map[string]string{
+		"mylib.gleam":               "",
+		"mylib/internal.gleam":      "",
+		"mylib_ffi.erl":             "-module(mylib_ffi).\n",
+		"native/mylib_nif.erl":      "%% NIF loader.\n-module(mylib_native).\n-export([load/0]).\n",
+		"vendored/jsone.erl":        "-module('jsone').\n",
+		"vendored/no_attribute.erl": "-export([f/0]).\n",
+		"mylib@compiled.erl":        "-module(mylib@compiled).\n",
+		"lib/mylib/json.ex":         "defmodule MyLib.JSON do\n  defmodule Encoder do\n  end\nend\n",
+	}
+	for name, content := range files {
+		path := filepath.Join(dir, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	modules, err := indexPackageModules(dir, "hex_mylib")
+	if err != nil {
+		t.Fatalf("indexPackageModules() failed: %v", err)
+	}
+	// Elixir modules aren't built by rules_gleam, only their package is known.
+	want := map[string]label.Label{
+		"erl:Elixir.MyLib.JSON":         label.NoLabel,
+		"erl:Elixir.MyLib.JSON.Encoder": label.NoLabel,
+		"erl:jsone":                     label.New("hex_mylib", "vendored", "jsone_ffi"),
+		"erl:mylib@compiled":            label.New("hex_mylib", "mylib", "compiled"),
+		"erl:mylib_ffi":                 label.New("hex_mylib", "", "mylib_ffi_ffi"),
+		"erl:mylib_native":              label.New("hex_mylib", "native", "mylib_nif_ffi"),
+		"erl:no_attribute":              label.New("hex_mylib", "vendored", "no_attribute_ffi"),
+		"mylib":                         label.New("hex_mylib", "", "mylib"),
+		"mylib/internal":                label.New("hex_mylib", "mylib", "internal"),
+	}
+	if diff := cmp.Diff(want, indexLabels(modules)); diff != "" {
+		t.Errorf("indexPackageModules() mismatch (-want +got):\n%s", diff)
+	}
+	for imp, m := range modules.modules {
+		if m.repo != "hex_mylib" {
+			t.Errorf("indexPackageModules() indexed %s in repository %s, want hex_mylib", imp, m.repo)
+		}
+	}
+}
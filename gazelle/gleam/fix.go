@@ -0,0 +1,293 @@
+package gleam
+
+import (
+	"fmt"
+	"log"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/bazelbuild/bazel-gazelle/config"
+	"github.com/bazelbuild/bazel-gazelle/label"
+	"github.com/bazelbuild/bazel-gazelle/rule"
+)
+
+// Fix migrates BUILD files written for older versions of rules_gleam.
+//
+// Load labels, stale strip_src_prefix and missing main_module attributes are
+// always fixed. Deleting libraries whose sources are gone and renaming,
+// splitting or merging libraries into the layout of the generation mode only
+// happen with "gazelle fix", otherwise they're only reported.
+func (g *gleamLanguage) Fix(c *config.Config, f *rule.File) {
+	dir := filepath.Dir(f.Path)
+	migrateLoads(c, f)
+	fixStripSrcPrefix(c, f)
+	fixMainModule(dir, f)
+	deleteLibrariesWithoutSources(c, dir, f)
+	switch GetGleamConfig(c).generationMode() {
+	case generationModeModule:
+		splitPackageLibraries(c, f)
+	case generationModePackage:
+		mergeModuleLibraries(c, f)
+	}
+}
+
+// migrateLoads moves the Gleam rules loaded from the .bzl files of
+// rules_gleam they used to be defined in to the defs.bzl load.
+func migrateLoads(c *config.Config, f *rule.File) {
+	rulesGleam := c.ModuleToApparentName("rules_gleam")
+	if rulesGleam == "" {
+		rulesGleam = "rules_gleam"
+	}
+	defsName := fmt.Sprintf("@%s//gleam:defs.bzl", rulesGleam)
+
+	var defs *rule.Load
+	var symbols []string
+	for _, l := range f.Loads {
+		if l.Name() == defsName {
+			defs = l
+			continue
+		}
+		if !isLegacyLoad(l.Name(), rulesGleam) {
+			continue
+		}
+		for _, sym := range l.Symbols() {
+			if _, ok := gleamKinds[sym]; ok {
+				symbols = append(symbols, sym)
+				l.Remove(sym)
+			}
+		}
+		if len(l.Symbols()) == 0 {
+			l.Delete()
+		}
+	}
+	if len(symbols) == 0 {
+		return
+	}
+	if defs == nil {
+		defs = rule.NewLoad(defsName)
+		defs.Insert(f, 0)
+	}
+	for _, sym := range symbols {
+		defs.Add(sym)
+	}
+}
+
+// isLegacyLoad reports whether name is a .bzl file of the rules_gleam gleam
+// package other than defs.bzl, e.g. "@rules_gleam//gleam:gleam_library.bzl".
+func isLegacyLoad(name, rulesGleam string) bool {
+	l, err := label.Parse(name)
+	if err != nil {
+		return false
+	}
+	if l.Repo != rulesGleam && l.Repo != "rules_gleam" {
+		return false
+	}
+	return l.Pkg == "gleam" && path.Ext(l.Name) == ".bzl" && l.Name != "defs.bzl"
+}
+
+// fixStripSrcPrefix sets strip_src_prefix to the one rules are generated
+// with, removing it outside of external repositories.
+func fixStripSrcPrefix(c *config.Config, f *rule.File) {
+	want := stripSrcPrefix(c)
+	for _, r := range f.Rules {
+		if _, ok := gleamKinds[r.Kind()]; !ok || r.ShouldKeep() {
+			continue
+		}
+		attr := r.Attr("strip_src_prefix")
+		if attr == nil || rule.ShouldKeep(attr) || r.AttrString("strip_src_prefix") == want {
+			continue
+		}
+		if want == "" {
+			r.DelAttr("strip_src_prefix")
+		} else {
+			r.SetAttr("strip_src_prefix", want)
+		}
+	}
+}
+
+// fixMainModule sets main_module on binaries with several sources when it
+// can tell which of them has the main function.
+func fixMainModule(dir string, f *rule.File) {
+	for _, r := range f.Rules {
+		if r.Kind() != string(ruleKindBin) || r.ShouldKeep() || r.AttrString("main_module") != "" {
+			continue
+		}
+		srcs := filter(r.AttrStrings("srcs"), func(src string) bool {
+			return path.Ext(src) == gleamExt
+		})
+		if len(srcs) < 2 {
+			continue
+		}
+		var mainModules []string
+		for _, src := range srcs {
+			if _, err := os.Stat(filepath.Join(dir, src)); err != nil {
+				continue
+			}
+			if module := getGleamModuleInfo(dir, src, f.Pkg); module.hasMainFn {
+				mainModules = append(mainModules, module.moduleName)
+			}
+		}
+		if len(mainModules) != 1 {
+			log.Printf("%s: gleam_binary %s has %d modules with a main function, main_module must be set by hand", f.Path, r.Name(), len(mainModules))
+			continue
+		}
+		r.SetAttr("main_module", mainModules[0])
+	}
+}
+
+// deleteLibrariesWithoutSources deletes the libraries none of the sources of
+// exist anymore.
+func deleteLibrariesWithoutSources(c *config.Config, dir string, f *rule.File) {
+	deleted := false
+	for _, r := range f.Rules {
+		switch ruleKind(r.Kind()) {
+		case ruleKindLib, ruleKindErlLib, ruleKindJsLib:
+		default:
+			continue
+		}
+		srcs := r.AttrStrings("srcs")
+		if r.ShouldKeep() || len(srcs) == 0 {
+			continue
+		}
+		missing := filter(srcs, func(src string) bool {
+			_, err := os.Stat(filepath.Join(dir, src))
+			return err != nil
+		})
+		if len(missing) != len(srcs) {
+			continue
+		}
+		if !c.ShouldFix {
+			log.Printf("%s: %s %s has no sources left, run gazelle fix to delete it", f.Path, r.Kind(), r.Name())
+			continue
+		}
+		r.Delete()
+		deleted = true
+	}
+	if deleted {
+		// Drops the deleted rules from f.Rules before libraries get migrated.
+		f.Sync()
+	}
+}
+
+// splitPackageLibraries splits the libraries holding several Gleam modules,
+// from before there was one gleam_library per module, and renames single
+// module libraries after their module.
+func splitPackageLibraries(c *config.Config, f *rule.File) {
+	names := ruleNames(f)
+	for _, r := range f.Rules {
+		if r.Kind() != string(ruleKindLib) || r.ShouldKeep() {
+			continue
+		}
+		srcs := r.AttrStrings("srcs")
+		if len(srcs) == 0 || len(filter(srcs, func(src string) bool { return path.Ext(src) != gleamExt })) > 0 {
+			continue
+		}
+		if len(srcs) == 1 && r.Name() == strings.TrimSuffix(srcs[0], gleamExt) {
+			continue
+		}
+		if !c.ShouldFix {
+			log.Printf("%s: gleam_library %s does not have one module per library, run gazelle fix to split it", f.Path, r.Name())
+			continue
+		}
+
+		sort.Strings(srcs)
+		var kept []string
+		for _, src := range srcs {
+			name := strings.TrimSuffix(src, gleamExt)
+			if name == r.Name() || names[name] {
+				// Either the library is already named after the module, or
+				// another rule has its name and the module stays.
+				kept = append(kept, src)
+				continue
+			}
+			lib := rule.NewRule(string(ruleKindLib), name)
+			copyAttrs(r, lib)
+			lib.SetAttr("srcs", []string{src})
+			lib.Insert(f)
+			names[name] = true
+		}
+		if len(kept) == 0 {
+			r.Delete()
+		} else {
+			r.SetAttr("srcs", kept)
+		}
+	}
+}
+
+// mergeModuleLibraries merges the Gleam libraries of the directory into one
+// named after it. Libraries of internal modules stay on their own.
+func mergeModuleLibraries(c *config.Config, f *rule.File) {
+	name := packageLibraryName(f.Pkg)
+	var libs []*rule.Rule
+	hasName := false
+	for _, r := range f.Rules {
+		if r.Kind() != string(ruleKindLib) || r.ShouldKeep() {
+			continue
+		}
+		srcs := r.AttrStrings("srcs")
+		if len(srcs) == 0 || len(filter(srcs, func(src string) bool { return path.Ext(src) != gleamExt })) > 0 {
+			continue
+		}
+		if len(srcs) == 1 && srcs[0] == "internal"+gleamExt {
+			continue
+		}
+		libs = append(libs, r)
+		hasName = hasName || r.Name() == name
+	}
+	names := ruleNames(f)
+	// Like the generated library, it gets a _lib suffix when another rule,
+	// e.g. the binary of app/app.gleam, is named after the directory.
+	if names[name] && !hasName {
+		name += "_lib"
+	}
+	if len(libs) == 0 || (len(libs) == 1 && libs[0].Name() == name) {
+		return
+	}
+	merged := libs[0]
+	for _, r := range libs {
+		if r.Name() == name {
+			merged = r
+		}
+	}
+	if merged.Name() != name && names[name] {
+		log.Printf("%s: can't merge Gleam libraries into %s, a rule already has that name", f.Path, name)
+		return
+	}
+	if !c.ShouldFix {
+		log.Printf("%s: Gleam libraries are not merged into %s, run gazelle fix to merge them", f.Path, name)
+		return
+	}
+
+	var srcs []string
+	for _, r := range libs {
+		srcs = append(srcs, r.AttrStrings("srcs")...)
+		if r != merged {
+			r.Delete()
+		}
+	}
+	sort.Strings(srcs)
+	merged.SetName(name)
+	merged.SetAttr("srcs", srcs)
+}
+
+// ruleNames returns the names of the rules of the file.
+func ruleNames(f *rule.File) map[string]bool {
+	return asSet(mapper(f.Rules, func(r *rule.Rule) string {
+		return r.Name()
+	}))
+}
+
+// copyAttrs copies the attributes of from to to, except for the name, the
+// sources and the deps, which get resolved again.
+func copyAttrs(from, to *rule.Rule) {
+	for _, key := range from.AttrKeys() {
+		switch key {
+		case "name", "srcs", "deps":
+			continue
+		}
+		to.SetAttr(key, from.Attr(key))
+	}
+}
@@ -0,0 +1,366 @@
+package gleam
+
+import (
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/bazelbuild/bazel-gazelle/rule"
+	"github.com/bazelbuild/bazel-gazelle/testtools"
+	bzl "github.com/bazelbuild/buildtools/build"
+)
+
+type fixTestCase struct {
+	desc, pkg, old, want string
+	// Files next to the BUILD file.
+	files []testtools.FileSpec
+	// Runs like "gazelle update", which doesn't make destructive fixes.
+	update bool
+}
+
+func TestFixFile(t *testing.T) {
+	for _, tc := range []fixTestCase{
+		{
+			desc: "legacy loads",
+			old: `
+load("@rules_gleam//gleam:gleam_library.bzl", "gleam_library")
+load("@rules_gleam//gleam:gleam_test.bzl", "gleam_test")
+
+gleam_library(
+    name = "lib",
+    srcs = ["lib.gleam"],
+)
+
+gleam_test(
+    name = "lib_test",
+    srcs = ["lib_test.gleam"],
+)
+`,
+			files: []testtools.FileSpec{
+				{Path: "lib.gleam"},
+				{Path: "lib_test.gleam"},
+			},
+			want: `
+load("@rules_gleam//gleam:defs.bzl", "gleam_library", "gleam_test")
+
+gleam_library(
+    name = "lib",
+    srcs = ["lib.gleam"],
+)
+
+gleam_test(
+    name = "lib_test",
+    srcs = ["lib_test.gleam"],
+)
+`,
+		},
+		{
+			desc: "legacy loads merged into defs.bzl",
+			old: `
+load("@rules_gleam//gleam:defs.bzl", "gleam_library")
+load("@rules_gleam//gleam:gleam_binary.bzl", "gleam_binary")
+
+gleam_library(
+    name = "lib",
+    srcs = ["lib.gleam"],
+)
+
+gleam_binary(
+    name = "main",
+    srcs = ["main.gleam"],
+)
+`,
+			files: []testtools.FileSpec{
+				{Path: "lib.gleam"},
+				{Path: "main.gleam", Content: "pub fn main() {\n  Nil\n}\n"},
+			},
+			want: `
+load("@rules_gleam//gleam:defs.bzl", "gleam_binary", "gleam_library")
+
+gleam_library(
+    name = "lib",
+    srcs = ["lib.gleam"],
+)
+
+gleam_binary(
+    name = "main",
+    srcs = ["main.gleam"],
+)
+`,
+		},
+		{
+			desc: "stale strip_src_prefix",
+			old: `
+gleam_library(
+    name = "lib",
+    srcs = ["lib.gleam"],
+    strip_src_prefix = "external/rules_gleam++gleam+gleam_stdlib",
+)
+`,
+			files: []testtools.FileSpec{
+				{Path: "lib.gleam"},
+			},
+			want: `
+gleam_library(
+    name = "lib",
+    srcs = ["lib.gleam"],
+)
+`,
+		},
+		{
+			desc: "missing main_module",
+			old: `
+gleam_binary(
+    name = "cli",
+    srcs = [
+        "app.gleam",
+        "cli.gleam",
+    ],
+)
+`,
+			files: []testtools.FileSpec{
+				{Path: "app.gleam", Content: "pub fn run() {\n  Nil\n}\n"},
+				{Path: "cli.gleam", Content: "import app\n\npub fn main() {\n  app.run()\n}\n"},
+			},
+			want: `
+gleam_binary(
+    name = "cli",
+    srcs = [
+        "app.gleam",
+        "cli.gleam",
+    ],
+    main_module = "cli",
+)
+`,
+		},
+		{
+			desc: "libraries without sources",
+			old: `
+gleam_library(
+    name = "gone",
+    srcs = ["gone.gleam"],
+)
+
+gleam_erl_library(
+    name = "gone_ffi",
+    srcs = ["gone.erl"],
+)
+
+gleam_library(
+    name = "kept",
+    srcs = ["kept.gleam"],
+)
+`,
+			files: []testtools.FileSpec{
+				{Path: "kept.gleam"},
+			},
+			want: `
+gleam_library(
+    name = "kept",
+    srcs = ["kept.gleam"],
+)
+`,
+		},
+		{
+			desc: "per-directory library split into modules",
+			pkg:  "shapes",
+			old: `
+gleam_library(
+    name = "shapes",
+    srcs = [
+        "circle.gleam",
+        "square.gleam",
+    ],
+    visibility = ["//visibility:public"],
+    deps = ["//geometry"],
+)
+
+gleam_library(
+    name = "old_name",
+    srcs = ["triangle.gleam"],
+)
+`,
+			files: []testtools.FileSpec{
+				{Path: "circle.gleam"},
+				{Path: "square.gleam"},
+				{Path: "triangle.gleam"},
+			},
+			want: `
+gleam_library(
+    name = "circle",
+    srcs = ["circle.gleam"],
+    visibility = ["//visibility:public"],
+)
+
+gleam_library(
+    name = "square",
+    srcs = ["square.gleam"],
+    visibility = ["//visibility:public"],
+)
+
+gleam_library(
+    name = "triangle",
+    srcs = ["triangle.gleam"],
+)
+`,
+		},
+		{
+			desc:   "per-directory library not split on update",
+			pkg:    "shapes",
+			update: true,
+			old: `
+gleam_library(
+    name = "shapes",
+    srcs = [
+        "circle.gleam",
+        "square.gleam",
+    ],
+)
+
+gleam_library(
+    name = "gone",
+    srcs = ["gone.gleam"],
+)
+`,
+			files: []testtools.FileSpec{
+				{Path: "circle.gleam"},
+				{Path: "square.gleam"},
+			},
+			want: `
+gleam_library(
+    name = "shapes",
+    srcs = [
+        "circle.gleam",
+        "square.gleam",
+    ],
+)
+
+gleam_library(
+    name = "gone",
+    srcs = ["gone.gleam"],
+)
+`,
+		},
+		{
+			desc: "module libraries merged in package mode",
+			pkg:  "shapes",
+			old: `
+# gazelle:gleam_generation_mode package
+
+gleam_library(
+    name = "circle",
+    srcs = ["circle.gleam"],
+    visibility = ["//visibility:public"],
+)
+
+gleam_library(
+    name = "square",
+    srcs = ["square.gleam"],
+    visibility = ["//visibility:public"],
+)
+
+gleam_binary(
+    name = "draw",
+    srcs = ["draw.gleam"],
+)
+`,
+			files: []testtools.FileSpec{
+				{Path: "circle.gleam"},
+				{Path: "square.gleam"},
+				{Path: "draw.gleam", Content: "pub fn main() {\n  Nil\n}\n"},
+			},
+			want: `
+# gazelle:gleam_generation_mode package
+
+gleam_library(
+    name = "shapes",
+    srcs = [
+        "circle.gleam",
+        "square.gleam",
+    ],
+    visibility = ["//visibility:public"],
+)
+
+gleam_binary(
+    name = "draw",
+    srcs = ["draw.gleam"],
+)
+`,
+		},
+		{
+			desc: "module libraries merged next to a binary named after the package",
+			pkg:  "shapes",
+			old: `
+# gazelle:gleam_generation_mode package
+
+gleam_library(
+    name = "circle",
+    srcs = ["circle.gleam"],
+    visibility = ["//visibility:public"],
+)
+
+gleam_library(
+    name = "internal",
+    srcs = ["internal.gleam"],
+    visibility = ["//shapes:__subpackages__"],
+)
+
+gleam_binary(
+    name = "shapes",
+    srcs = ["shapes.gleam"],
+)
+`,
+			files: []testtools.FileSpec{
+				{Path: "circle.gleam"},
+				{Path: "internal.gleam"},
+				{Path: "shapes.gleam", Content: "pub fn main() {\n  Nil\n}\n"},
+			},
+			want: `
+# gazelle:gleam_generation_mode package
+
+gleam_library(
+    name = "shapes_lib",
+    srcs = ["circle.gleam"],
+    visibility = ["//visibility:public"],
+)
+
+gleam_library(
+    name = "internal",
+    srcs = ["internal.gleam"],
+    visibility = ["//shapes:__subpackages__"],
+)
+
+gleam_binary(
+    name = "shapes",
+    srcs = ["shapes.gleam"],
+)
+`,
+		},
+	} {
+		t.Run(tc.desc, func(t *testing.T) {
+			testFix(t, tc)
+		})
+	}
+}
+
+func testFix(t *testing.T, tc fixTestCase) {
+	dir, cleanup := testtools.CreateFiles(t, tc.files)
+	defer cleanup()
+
+	f, err := rule.LoadData(filepath.Join(dir, "BUILD.bazel"), tc.pkg, []byte(tc.old))
+	if err != nil {
+		t.Fatalf("error parsing old BUILD file: %v", err)
+	}
+	c, langs, _ := testConfig(t, "-repo_root="+dir)
+	c.ShouldFix = !tc.update
+	for _, lang := range langs {
+		lang.Configure(c, tc.pkg, f)
+		lang.Fix(c, f)
+	}
+	f.Sync()
+
+	want := strings.TrimSpace(tc.want)
+	if got := strings.TrimSpace(string(bzl.Format(f.File))); got != want {
+		t.Errorf("got:\n%s\nwant:\n%s", got, want)
+	}
+}
@@ -0,0 +1,5 @@
+-module(clock_ffi).
+-export([now/0]).
+
+now() ->
+    erlang:system_time(millisecond).
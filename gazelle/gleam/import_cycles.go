@@ -0,0 +1,174 @@
+package gleam
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// importGraph records the imports between Gleam modules as rules get resolved,
+// so that import cycles can be reported once every rule has been resolved.
+// Gleam forbids import cycles, and their deps would form a cycle in Bazel.
+type importGraph struct {
+	// Imports keyed by the importing module, e.g. "foo/bar".
+	imports map[string][]gleamImport
+}
+
+func newImportGraph() *importGraph {
+	return &importGraph{imports: make(map[string][]gleamImport)}
+}
+
+// add records an import. FFI imports can't be part of a cycle and are ignored.
+func (ig *importGraph) add(imp gleamImport) {
+	if strings.HasPrefix(imp.module, "erl:") || strings.HasPrefix(imp.module, "js:") {
+		return
+	}
+	from := strings.TrimSuffix(imp.file, gleamExt)
+	ig.imports[from] = append(ig.imports[from], imp)
+}
+
+// importCycle is a chain of imports where the last import leads back to the
+// module making the first one.
+type importCycle []gleamImport
+
+func (ic importCycle) String() string {
+	modules := mapper(ic, func(imp gleamImport) string {
+		return strings.TrimSuffix(imp.file, gleamExt)
+	})
+	return strings.Join(append(modules, modules[0]), " -> ")
+}
+
+// report describes the cycle along with where each of its imports is made.
+func (ic importCycle) report() string {
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "import cycle: %s", ic)
+	for _, imp := range ic {
+		fmt.Fprintf(&sb, "\n\t%s: imports %s", imp.location(), imp.module)
+	}
+	return sb.String()
+}
+
+// cycles returns one import cycle per group of modules importing each other,
+// in a stable order. Each cycle starts at its smallest module.
+func (ig *importGraph) cycles() []importCycle {
+	var cycles []importCycle
+	for _, component := range ig.stronglyConnectedComponents() {
+		inComponent := asSet(component)
+		start := component[0]
+		if len(component) == 1 && !ig.importsModule(start, start) {
+			continue
+		}
+		cycles = append(cycles, ig.shortestCycle(start, inComponent))
+	}
+	sort.Slice(cycles, func(i, j int) bool {
+		return cycles[i].String() < cycles[j].String()
+	})
+	return cycles
+}
+
+func (ig *importGraph) importsModule(from, to string) bool {
+	for _, imp := range ig.imports[from] {
+		if imp.module == to {
+			return true
+		}
+	}
+	return false
+}
+
+// sortedImports returns the imports made by a module, sorted by the imported
+// module so that the graph is always walked in the same order.
+func (ig *importGraph) sortedImports(module string) []gleamImport {
+	imports := append([]gleamImport{}, ig.imports[module]...)
+	sort.Slice(imports, func(i, j int) bool {
+		if imports[i].module != imports[j].module {
+			return imports[i].module < imports[j].module
+		}
+		return imports[i].before(imports[j])
+	})
+	return imports
+}
+
+// shortestCycle finds the shortest chain of imports from start back to
+// itself, only going through the modules of the given component.
+func (ig *importGraph) shortestCycle(start string, component map[string]bool) importCycle {
+	// The import a module was first reached by.
+	reachedBy := make(map[string]gleamImport)
+	queue := []string{start}
+	for len(queue) > 0 {
+		module := queue[0]
+		queue = queue[1:]
+		for _, imp := range ig.sortedImports(module) {
+			if !component[imp.module] {
+				continue
+			}
+			if imp.module == start {
+				cycle := importCycle{imp}
+				for from := module; from != start; {
+					prev := reachedBy[from]
+					cycle = append(importCycle{prev}, cycle...)
+					from = strings.TrimSuffix(prev.file, gleamExt)
+				}
+				return cycle
+			}
+			if _, ok := reachedBy[imp.module]; ok {
+				continue
+			}
+			reachedBy[imp.module] = imp
+			queue = append(queue, imp.module)
+		}
+	}
+	return nil
+}
+
+// stronglyConnectedComponents returns the groups of modules that import each
+// other, using Tarjan's algorithm. Modules of a component are sorted.
+func (ig *importGraph) stronglyConnectedComponents() [][]string {
+	modules := collect(ig.imports)
+	sort.Strings(modules)
+
+	index := 0
+	indices := make(map[string]int)
+	lowLinks := make(map[string]int)
+	onStack := make(map[string]bool)
+	var stack []string
+	var components [][]string
+
+	var visit func(module string)
+	visit = func(module string) {
+		indices[module] = index
+		lowLinks[module] = index
+		index++
+		stack = append(stack, module)
+		onStack[module] = true
+
+		for _, imp := range ig.sortedImports(module) {
+			if _, ok := indices[imp.module]; !ok {
+				visit(imp.module)
+				lowLinks[module] = min(lowLinks[module], lowLinks[imp.module])
+			} else if onStack[imp.module] {
+				lowLinks[module] = min(lowLinks[module], indices[imp.module])
+			}
+		}
+
+		if lowLinks[module] == indices[module] {
+			var component []string
+			for {
+				last := stack[len(stack)-1]
+				stack = stack[:len(stack)-1]
+				onStack[last] = false
+				component = append(component, last)
+				if last == module {
+					break
+				}
+			}
+			sort.Strings(component)
+			components = append(components, component)
+		}
+	}
+	for _, module := range modules {
+		if _, ok := indices[module]; !ok {
+			visit(module)
+		}
+	}
+	return components
+}
@@ -0,0 +1,135 @@
+package gleam
+
+import (
+	"testing"
+
+	"github.com/bazelbuild/bazel-gazelle/config"
+	"github.com/google/go-cmp/cmp"
+	"github.com/google/go-cmp/cmp/cmpopts"
+	"github.com/iocat/rules_gleam/gazelle/gleam/parser"
+)
+
+func TestImportCycles(t *testing.T) {
+	imp := func(file string, line int, module string) gleamImport {
+		return gleamImport{module: module, file: file, pos: parser.Position{Line: line, Col: 1}}
+	}
+	testCases := []struct {
+		desc    string
+		imports []gleamImport
+		want    []string
+	}{
+		{
+			desc: "no cycle",
+			imports: []gleamImport{
+				imp("a/b.gleam", 1, "c/d"),
+				imp("a/b.gleam", 2, "gleam/io"),
+				imp("c/d.gleam", 1, "e/f"),
+				imp("a/b.gleam", 3, "erl:a_ffi"),
+			},
+		},
+		{
+			desc: "two modules",
+			imports: []gleamImport{
+				imp("a/b.gleam", 1, "c/d"),
+				imp("c/d.gleam", 3, "a/b"),
+			},
+			want: []string{
+				"import cycle: a/b -> c/d -> a/b\n" +
+					"\ta/b.gleam:1:1: imports c/d\n" +
+					"\tc/d.gleam:3:1: imports a/b",
+			},
+		},
+		{
+			desc: "self import",
+			imports: []gleamImport{
+				imp("a.gleam", 2, "a"),
+			},
+			want: []string{
+				"import cycle: a -> a\n" +
+					"\ta.gleam:2:1: imports a",
+			},
+		},
+		{
+			desc: "shortest cycle is reported",
+			imports: []gleamImport{
+				imp("a.gleam", 1, "b"),
+				imp("b.gleam", 1, "c"),
+				imp("c.gleam", 1, "d"),
+				imp("d.gleam", 1, "a"),
+				imp("c.gleam", 2, "a"),
+			},
+			want: []string{
+				"import cycle: a -> b -> c -> a\n" +
+					"\ta.gleam:1:1: imports b\n" +
+					"\tb.gleam:1:1: imports c\n" +
+					"\tc.gleam:2:1: imports a",
+			},
+		},
+		{
+			desc: "separate cycles",
+			imports: []gleamImport{
+				imp("z/y.gleam", 1, "z/x"),
+				imp("z/x.gleam", 1, "z/y"),
+				imp("z/x.gleam", 2, "m/n"),
+				imp("m/n.gleam", 1, "m/o"),
+				imp("m/o.gleam", 1, "m/n"),
+			},
+			want: []string{
+				"import cycle: m/n -> m/o -> m/n\n" +
+					"\tm/n.gleam:1:1: imports m/o\n" +
+					"\tm/o.gleam:1:1: imports m/n",
+				"import cycle: z/x -> z/y -> z/x\n" +
+					"\tz/x.gleam:1:1: imports z/y\n" +
+					"\tz/y.gleam:1:1: imports z/x",
+			},
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.desc, func(t *testing.T) {
+			ig := newImportGraph()
+			for _, imp := range tc.imports {
+				ig.add(imp)
+			}
+			got := mapper(ig.cycles(), func(cycle importCycle) string { return cycle.report() })
+			if diff := cmp.Diff(tc.want, got, cmpopts.EquateEmpty()); diff != "" {
+				t.Errorf("(-want, +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+// Modules of a bundle importing the same module have their imports merged into
+// one dep, but each of them is part of the import graph.
+func TestImportCyclesOfBundle(t *testing.T) {
+	imp := func(file string, line int, module string) gleamImport {
+		return gleamImport{module: module, file: file, pos: parser.Position{Line: line, Col: 1, Offset: line}}
+	}
+	c := &config.Config{Exts: map[string]interface{}{languageName: &GleamConfig{}}}
+	bundles := []*gleamModuleBundle{
+		{kind: ruleKindLib, name: "ab", c: c, modules: map[string]gleamModuleInfo{
+			"a": {moduleName: "a", file: "a.gleam", imports: []gleamImport{imp("a.gleam", 1, "c")}},
+			"b": {moduleName: "b", file: "b.gleam", imports: []gleamImport{imp("b.gleam", 1, "c")}},
+		}},
+		{kind: ruleKindLib, name: "c", c: c, modules: map[string]gleamModuleInfo{
+			"c": {moduleName: "c", file: "c.gleam", imports: []gleamImport{imp("c.gleam", 1, "b")}},
+		}},
+	}
+
+	ig := newImportGraph()
+	for _, bundle := range bundles {
+		for _, imports := range bundle.generateImports() {
+			for _, imp := range imports.(ruleImports).modules {
+				ig.add(imp)
+			}
+		}
+	}
+	want := []string{
+		"import cycle: b -> c -> b\n" +
+			"\tb.gleam:1:1: imports c\n" +
+			"\tc.gleam:1:1: imports b",
+	}
+	got := mapper(ig.cycles(), func(cycle importCycle) string { return cycle.report() })
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("(-want, +got):\n%s", diff)
+	}
+}
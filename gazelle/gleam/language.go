@@ -1,16 +1,24 @@
 package gleam
 
 import (
+	"context"
 	"fmt"
+	"log"
 
-	"github.com/bazelbuild/bazel-gazelle/config"
 	lang "github.com/bazelbuild/bazel-gazelle/language"
 	"github.com/bazelbuild/bazel-gazelle/rule"
 )
 
 const languageName = "gleam"
 
-type gleamLanguage struct{}
+type gleamLanguage struct {
+	lang.BaseLifecycleManager
+
+	// Imports between modules, recorded while resolving rules.
+	importGraph *importGraph
+	// Whether import cycles fail the run, see -gleam_fail_on_import_cycle.
+	failOnImportCycle bool
+}
 
 var gleamKinds = map[string]rule.KindInfo{
 	"gleam_library": {
@@ -19,7 +27,7 @@ var gleamKinds = map[string]rule.KindInfo{
 		MergeableAttrs: map[string]bool{
 			"srcs": true,
 		},
-		ResolveAttrs: map[string]bool{"deps": true},
+		ResolveAttrs: map[string]bool{"deps": true, "otp_applications": true},
 	},
 	"gleam_binary": {
 		MatchAttrs:    []string{"srcs"},
@@ -27,7 +35,7 @@ var gleamKinds = map[string]rule.KindInfo{
 		MergeableAttrs: map[string]bool{
 			"srcs": true,
 		},
-		ResolveAttrs: map[string]bool{"deps": true},
+		ResolveAttrs: map[string]bool{"deps": true, "otp_applications": true},
 	},
 	"gleam_test": {
 		MatchAttrs:    []string{"srcs"},
@@ -35,7 +43,7 @@ var gleamKinds = map[string]rule.KindInfo{
 		MergeableAttrs: map[string]bool{
 			"srcs": true,
 		},
-		ResolveAttrs: map[string]bool{"deps": true},
+		ResolveAttrs: map[string]bool{"deps": true, "otp_applications": true},
 	},
 	"gleam_erl_library": {
 		MatchAttrs:    []string{"srcs"},
@@ -44,6 +52,13 @@ var gleamKinds = map[string]rule.KindInfo{
 			"srcs": true,
 		},
 	},
+	"gleam_js_library": {
+		MatchAttrs:    []string{"srcs"},
+		NonEmptyAttrs: map[string]bool{"srcs": true},
+		MergeableAttrs: map[string]bool{
+			"srcs": true,
+		},
+	},
 }
 
 func (g *gleamLanguage) Kinds() map[string]rule.KindInfo {
@@ -62,6 +77,7 @@ func (g *gleamLanguage) ApparentLoads(moduleToApparentName func(string) string)
 				"gleam_library",
 				"gleam_binary",
 				"gleam_erl_library",
+				"gleam_js_library",
 				"gleam_test",
 			},
 		},
@@ -73,11 +89,22 @@ func (g *gleamLanguage) Loads() []rule.LoadInfo {
 	panic("ApparentLoads should be called instead")
 }
 
-// We don't fix anything, (yet :).
-func (g *gleamLanguage) Fix(c *config.Config, f *rule.File) {
+func (g *gleamLanguage) Before(ctx context.Context) {
+	g.importGraph = newImportGraph()
+}
 
+// AfterResolvingDeps reports the import cycles found while resolving rules.
+// With -gleam_fail_on_import_cycle, it exits before any BUILD file is written.
+func (g *gleamLanguage) AfterResolvingDeps(ctx context.Context) {
+	cycles := g.importGraph.cycles()
+	for _, cycle := range cycles {
+		log.Print(cycle.report())
+	}
+	if len(cycles) > 0 && g.failOnImportCycle {
+		log.Fatalf("found %d import cycle(s), Gleam does not allow import cycles", len(cycles))
+	}
 }
 
 func NewLanguage() lang.Language {
-	return &gleamLanguage{}
+	return &gleamLanguage{importGraph: newImportGraph()}
 }
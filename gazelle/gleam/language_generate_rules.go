@@ -11,7 +11,6 @@ import (
 	"github.com/bazelbuild/bazel-gazelle/pathtools"
 	"github.com/bazelbuild/bazel-gazelle/rule"
 	"github.com/iocat/rules_gleam/gazelle/gleam/parser"
-	"github.com/kr/pretty"
 
 	"path"
 	"path/filepath"
@@ -23,10 +22,124 @@ type gleamModuleInfo struct {
 	moduleName    string
 	file          string
 
-	imports   []string
+	imports   []gleamImport
 	hasMainFn bool
 }
 
+// gleamImport is a single import made by a Gleam module, along with where it
+// was made so that resolution errors can point back at the source.
+type gleamImport struct {
+	// The imported Gleam module, e.g. "gleam/io", "erl:<module>" for an
+	// Erlang FFI module, or "js:<path>" for a JavaScript FFI file where path
+	// is relative to the repository root.
+	module string
+	// Path of the importing file, relative to the repository root.
+	file string
+	pos  parser.Position
+	// The compilation target the import is made for, targetErlang or
+	// targetJavascript. Empty when the import is made for every target.
+	target string
+}
+
+const (
+	targetErlang     = "erlang"
+	targetJavascript = "javascript"
+	targetBoth       = "both"
+)
+
+const (
+	// Each module gets its own gleam_library.
+	generationModeModule = "module"
+	// All library modules of a directory go into one gleam_library.
+	generationModePackage = "package"
+)
+
+const (
+	// All test modules of a directory go into one gleam_test.
+	testModePackage = "package"
+	// Each test module gets its own gleam_test.
+	testModeFile = "file"
+)
+
+// forTarget reports whether the import is needed when generating for the
+// given target, one of targetErlang, targetJavascript or targetBoth.
+func (gi gleamImport) forTarget(target string) bool {
+	return gi.target == "" || target == targetBoth || gi.target == target
+}
+
+// merge combines two imports of the same module, keeping the first one in
+// source order. An import made for different targets is needed by all of them.
+func (gi gleamImport) merge(other gleamImport) gleamImport {
+	first := gi
+	if other.before(gi) {
+		first = other
+	}
+	if gi.target != other.target {
+		first.target = ""
+	}
+	return first
+}
+
+func (gi gleamImport) location() string {
+	return fmt.Sprintf("%s:%s", gi.file, gi.pos)
+}
+
+func (gi gleamImport) before(other gleamImport) bool {
+	if gi.file != other.file {
+		return gi.file < other.file
+	}
+	return gi.pos.Offset < other.pos.Offset
+}
+
+func importModules(imports []gleamImport) []string {
+	return mapper(imports, func(imp gleamImport) string { return imp.module })
+}
+
+// importAttr returns the value of the private imports attribute of a rule:
+// the imported module, prefixed with "@target(<target>) " when it's only
+// imported for one target.
+func importAttr(imports []gleamImport) []string {
+	return mapper(imports, func(imp gleamImport) string {
+		if imp.target == "" {
+			return imp.module
+		}
+		return fmt.Sprintf("@target(%s) %s", imp.target, imp.module)
+	})
+}
+
+// ModuleImport is an import made by a Gleam module, as found when generating
+// rules. It lets tools outside of the extension inspect the import graph.
+type ModuleImport struct {
+	// The importing module, e.g. "foo/bar".
+	Module string
+	// The imported module, e.g. "gleam/io" or "erl:<module>".
+	Import string
+	// Where the import was made, "<file>:<line>:<col>".
+	Location string
+}
+
+// ruleImports are the imports GenerateRules returns for a rule, for Resolve.
+type ruleImports struct {
+	// One import per imported module, to resolve the deps of the rule.
+	deps []gleamImport
+	// Every import of every module of the rule, sorted by importing file and
+	// position. Modules importing the same module each keep their import.
+	modules []gleamImport
+}
+
+// ModuleImports returns the imports of a rule generated by the Gleam language,
+// given the opaque imports GenerateRules returned for it.
+func ModuleImports(imports any) []ModuleImport {
+	ri, _ := imports.(ruleImports)
+	return mapper(ri.modules, func(imp gleamImport) ModuleImport {
+		return ModuleImport{
+			Module:   strings.TrimSuffix(imp.file, gleamExt),
+			Import:   imp.module,
+			Location: imp.location(),
+		}
+	})
+}
+
 type ruleKind string
 
 var (
@@ -34,6 +147,7 @@ var (
 	ruleKindBin    ruleKind = "gleam_binary"
 	ruleKindTest   ruleKind = "gleam_test"
 	ruleKindErlLib ruleKind = "gleam_erl_library"
+	ruleKindJsLib  ruleKind = "gleam_js_library"
 )
 
 type gleamModuleBundle struct {
@@ -48,11 +162,15 @@ type gleamModuleBundle struct {
 	c   *config.Config
 }
 
-func (gmb *gleamModuleBundle) imports(filterModule func(string) bool) []string {
+// Returns one import per imported module needed by the configured targets,
+// sorted by module. When several sources import the same module, the first
+// one in source order is kept.
+func (gmb *gleamModuleBundle) imports(filterModule func(string) bool) []gleamImport {
 	if gmb == nil {
-		return []string{}
+		return []gleamImport{}
 	}
-	imports := make(map[string]bool)
+	target := GetGleamConfig(gmb.c).generationTarget()
+	imports := make(map[string]gleamImport)
 	for _, module := range gmb.modules {
 		if !filterModule(module.moduleName) {
 			continue
@@ -61,16 +179,45 @@ func (gmb *gleamModuleBundle) imports(filterModule func(string) bool) []string {
 			continue
 		}
 		for _, imp := range module.imports {
-			imports[imp] = true
+			if !imp.forTarget(target) {
+				continue
+			}
+			if prev, ok := imports[imp.module]; ok {
+				imp = prev.merge(imp)
+			}
+			imports[imp.module] = imp
 		}
 	}
 
-	var importStrings []string
-	for imp := range imports {
-		importStrings = append(importStrings, imp)
+	var importList []gleamImport
+	for _, imp := range imports {
+		importList = append(importList, imp)
+	}
+	sort.Slice(importList, func(i, j int) bool {
+		return importList[i].module < importList[j].module
+	})
+	return importList
+}
+
+// moduleImports returns the imports of every module of the bundle needed by
+// the configured targets. Unlike imports, they aren't merged across modules.
+func (gmb *gleamModuleBundle) moduleImports() []gleamImport {
+	if gmb == nil {
+		return []gleamImport{}
+	}
+	target := GetGleamConfig(gmb.c).generationTarget()
+	importList := []gleamImport{}
+	for _, module := range gmb.modules {
+		for _, imp := range module.imports {
+			if imp.forTarget(target) {
+				importList = append(importList, imp)
+			}
+		}
 	}
-	sort.Strings(importStrings)
-	return importStrings
+	sort.Slice(importList, func(i, j int) bool {
+		return importList[i].before(importList[j])
+	})
+	return importList
 }
 
 func (gmb *gleamModuleBundle) sources() []string {
@@ -86,7 +233,7 @@ func (gmb *gleamModuleBundle) relsToIndex() []string {
 		return true
 	})
 	rel := []string{}
-	for _, imp := range imports {
+	for _, imp := range importModules(imports) {
 		// stdlib does not need to be indexed.
 		if strings.HasPrefix(imp, "gleam/") {
 			continue
@@ -122,24 +269,45 @@ func (gmb *gleamModuleBundle) nonInternalVisibility() []string {
 	return visibility
 }
 
+// isInternalModule reports whether the bundle only holds "internal" modules.
+// A directory library also holding public modules stays visible.
 func (gmb *gleamModuleBundle) isInternalModule() bool {
 	for _, module := range gmb.modules {
-		if module.moduleName == "internal" {
-			return true
+		if module.moduleName != "internal" {
+			return false
 		}
 	}
-	return false
+	return len(gmb.modules) > 0
 }
 
 func (gmb *gleamModuleBundle) setSourcePrefix(r *rule.Rule) {
-	if GetGleamConfig(gmb.c).externalRepo {
-		externalIndex := strings.LastIndex(gmb.c.RepoRoot, "external/")
-		if externalIndex < 0 {
-			return
-		}
-		externalIndex += len("external/")
-		r.SetAttr("strip_src_prefix", fmt.Sprintf("%s%s", "external/", gmb.c.RepoRoot[externalIndex:]))
+	if prefix := stripSrcPrefix(gmb.c); prefix != "" {
+		r.SetAttr("strip_src_prefix", prefix)
+	}
+}
+
+// stripSrcPrefix returns the strip_src_prefix of the rules generated for an
+// external Gleam (Hex) repository, or "" when rules don't need one.
+func stripSrcPrefix(c *config.Config) string {
+	if !GetGleamConfig(c).externalRepo {
+		return ""
 	}
+	externalIndex := strings.LastIndex(c.RepoRoot, "external/")
+	if externalIndex < 0 {
+		return ""
+	}
+	externalIndex += len("external/")
+	return fmt.Sprintf("%s%s", "external/", c.RepoRoot[externalIndex:])
+}
+
+// packageLibraryName returns the name of the library holding the modules of
+// a whole directory, used in package generation mode.
+func packageLibraryName(rel string) string {
+	name := path.Base(rel)
+	if len(name) == 0 || name == "." {
+		return "gleam_lib"
+	}
+	return name
 }
 
 func (gmb *gleamModuleBundle) generateRules() []*rule.Rule {
@@ -151,6 +319,10 @@ func (gmb *gleamModuleBundle) generateRules() []*rule.Rule {
 	}))
 
 	gmb.setSourcePrefix(r)
+	if gmb.kind != ruleKindTest && isTestOnlyDir(gmb.rel) {
+		// Helpers of the tests next to them.
+		r.SetAttr("testonly", true)
+	}
 	switch gmb.kind {
 	case ruleKindBin:
 		r.SetAttr("visibility", []string{"//visibility:private"})
@@ -166,7 +338,7 @@ func (gmb *gleamModuleBundle) generateRules() []*rule.Rule {
 		if mainModule != "" && len(r.AttrStrings("srcs")) > 1 {
 			r.SetAttr("main_module", mainModule)
 		}
-	case ruleKindLib, ruleKindErlLib:
+	case ruleKindLib, ruleKindErlLib, ruleKindJsLib:
 		if isInternalModule {
 			// r.SetAttr("visibility", gmb.nonInternalVisibility())
 			r.SetAttr("visibility", []string{fmt.Sprintf("//%s:__subpackages__", gmb.rel)})
@@ -183,14 +355,17 @@ func (gmb *gleamModuleBundle) generateRules() []*rule.Rule {
 	for i, r := range rules {
 		// Like go implementation, we set this private useable for testing.
 		// After merging phase, this attribute will be removed.
-		r.SetPrivateAttr(config.GazelleImportsKey, imports[i].([]string))
+		r.SetPrivateAttr(config.GazelleImportsKey, importAttr(imports[i].(ruleImports).deps))
 	}
 	return rules
 }
 
 /** Returns import, must be of the same size as generate rules returned. */
 func (gmb *gleamModuleBundle) generateImports() []any {
-	imports := []any{gmb.imports(func(m string) bool { return true })}
+	imports := []any{ruleImports{
+		deps:    gmb.imports(func(m string) bool { return true }),
+		modules: gmb.moduleImports(),
+	}}
 	return imports
 }
 
@@ -201,41 +376,43 @@ func (g *gleamLanguage) GenerateRules(args lang.GenerateArgs) lang.GenerateResul
 		filepath.Dir(args.Rel) == "build") {
 		return lang.GenerateResult{}
 	}
-	name := path.Base(args.Rel)
-	if len(name) == 0 || name == "." {
-		name = "gleam_lib"
+	gleamConfig := GetGleamConfig(args.Config)
+	if gleamConfig.isExcluded(args.Rel) {
+		return lang.GenerateResult{}
 	}
+	name := packageLibraryName(args.Rel)
 
 	var gleamBundle, gleamTestBundle *gleamModuleBundle
-	var ffiBundles []*gleamModuleBundle
+	var ffiBundles, testBundles []*gleamModuleBundle
 	// For each of the Gleam file in the directory. Create a
 	for _, file := range args.RegularFiles {
+		if gleamConfig.isExcluded(path.Join(args.Rel, file)) {
+			continue
+		}
 		ext := path.Ext(file)
-		filename := file
-		if strings.HasSuffix(filename, "_test.gleam") || strings.HasSuffix(filename, "_tests.gleam") {
+		if ext == gleamExt && gleamConfig.isTestFile(path.Join(args.Rel, file)) {
 			ext = gleamTestExt
 		}
 
 		switch ext {
 		case gleamTestExt:
+			module := getGleamModuleInfo(args.Dir, file, args.Rel)
+			if gleamConfig.generationTestMode() == testModeFile {
+				testBundle := &gleamModuleBundle{kind: ruleKindTest, name: module.moduleName, modules: make(map[string]gleamModuleInfo), c: args.Config, rel: args.Rel}
+				testBundle.modules[module.moduleName] = *module
+				testBundles = append(testBundles, testBundle)
+				continue
+			}
 			if gleamTestBundle == nil {
 				gleamTestBundle = &gleamModuleBundle{kind: ruleKindTest, name: fmt.Sprintf("%s_test", name), modules: make(map[string]gleamModuleInfo), c: args.Config, rel: args.Rel}
-			}
-			module, err := getGleamModuleInfo(args.Dir, file, args.Rel)
-			if err != nil {
-				log.Print(err)
-				return lang.GenerateResult{}
+				testBundles = append(testBundles, gleamTestBundle)
 			}
 			gleamTestBundle.modules[module.moduleName] = *module
 		case gleamExt:
 			if gleamBundle == nil {
 				gleamBundle = &gleamModuleBundle{kind: ruleKindLib, name: name, modules: make(map[string]gleamModuleInfo), c: args.Config, rel: args.Rel}
 			}
-			module, err := getGleamModuleInfo(args.Dir, file, args.Rel)
-			if err != nil {
-				log.Print(err)
-				return lang.GenerateResult{}
-			}
+			module := getGleamModuleInfo(args.Dir, file, args.Rel)
 			gleamBundle.modules[module.moduleName] = *module
 			if module.hasMainFn {
 				gleamBundle.kind = ruleKindBin
@@ -258,13 +435,67 @@ func (g *gleamLanguage) GenerateRules(args lang.GenerateArgs) lang.GenerateResul
 				moduleName:    nonNsModule,
 				file:          file,
 				moduleParents: []string{},
-				imports:       []string{}, // No imports for Erl FFI
+				imports:       []gleamImport{}, // No imports for Erl FFI
+			}
+			ffiBundles = append(ffiBundles, ffiBundle)
+		case mjsExt, jsExt:
+			nonNsModule := strings.TrimSuffix(filepath.Base(file), ext)
+			ffiBundle := &gleamModuleBundle{
+				kind:    ruleKindJsLib,
+				name:    fmt.Sprintf("%s_js_ffi", nonNsModule),
+				modules: make(map[string]gleamModuleInfo),
+				c:       args.Config,
+				rel:     args.Rel,
+			}
+			ffiBundle.modules[nonNsModule] = gleamModuleInfo{
+				moduleName:    nonNsModule,
+				file:          file,
+				moduleParents: []string{},
+				imports:       []gleamImport{}, // No imports for JS FFI
 			}
 			ffiBundles = append(ffiBundles, ffiBundle)
 		}
 	}
 
-	if gleamBundle != nil {
+	if gleamBundle != nil && gleamConfig.generationMode() == generationModePackage {
+		// One library for the whole directory, named after it. Modules with a
+		// main function still get their own binary, and internal modules
+		// their own library so that they stay restricted.
+		libBundle := &gleamModuleBundle{
+			kind:    ruleKindLib,
+			name:    name,
+			modules: make(map[string]gleamModuleInfo),
+			rel:     gleamBundle.rel,
+			c:       gleamBundle.c,
+		}
+		for modName, moduleInfo := range gleamBundle.modules {
+			if !moduleInfo.hasMainFn && modName != "internal" {
+				libBundle.modules[modName] = moduleInfo
+				continue
+			}
+			kind := ruleKindLib
+			if moduleInfo.hasMainFn {
+				kind = ruleKindBin
+			}
+			bundles = append(bundles, &gleamModuleBundle{
+				kind:    kind,
+				name:    modName,
+				modules: map[string]gleamModuleInfo{modName: moduleInfo},
+				rel:     gleamBundle.rel,
+				c:       gleamBundle.c,
+			})
+		}
+		if len(libBundle.modules) > 0 {
+			// A module named after the directory may already have its own
+			// rule, e.g. the binary of app/app.gleam.
+			_, inDir := gleamBundle.modules[name]
+			_, inLib := libBundle.modules[name]
+			if inDir && !inLib {
+				libBundle.name += "_lib"
+			}
+			bundles = append(bundles, libBundle)
+		}
+	} else if gleamBundle != nil {
 		if gleamBundle.kind == ruleKindLib {
 			for modName, moduleInfo := range gleamBundle.modules {
 				libBundle := &gleamModuleBundle{
@@ -305,9 +536,7 @@ func (g *gleamLanguage) GenerateRules(args lang.GenerateArgs) lang.GenerateResul
 	if len(ffiBundles) > 0 {
 		bundles = append(bundles, ffiBundles...)
 	}
-	if gleamTestBundle != nil {
-		bundles = append(bundles, gleamTestBundle)
-	}
+	bundles = append(bundles, testBundles...)
 
 	importsList := []any{}
 	rulesList := []*rule.Rule{}
@@ -335,26 +564,39 @@ func (g *gleamLanguage) GenerateRules(args lang.GenerateArgs) lang.GenerateResul
 	}
 }
 
-func getGleamModuleInfo(dir, file string, rel string) (*gleamModuleInfo, error) {
+// getGleamModuleInfo parses a Gleam source file for its imports and main
+// function. Statements that can't be parsed are reported and skipped, so a
+// file being edited keeps the imports that could be recovered. If the file
+// can't be parsed at all, the module is returned without imports.
+func getGleamModuleInfo(dir, file string, rel string) *gleamModuleInfo {
 	filePath := path.Clean(path.Join(dir, file))
+	relPath := path.Join(rel, file)
 
-	imports := map[string]bool{}
+	imports := map[string]gleamImport{}
+	addImport := func(module string, pos parser.Position, target string) {
+		imp := gleamImport{module: module, file: relPath, pos: pos, target: target}
+		if prev, ok := imports[module]; ok {
+			imp = prev.merge(imp)
+		}
+		imports[module] = imp
+	}
 	hasMainFunction := false
-	parseTree, err := parser.ParseFile(filePath, parser.Debug(false))
-	if err != nil || parseTree == nil {
-		log.Printf("failed to parse file %s: %v", filePath, err)
-		return nil, nil
-	}
-	printTree := false
-	if parseTree != nil {
-		for _, stmt := range parseTree.(parser.SourceFile).Statements {
+	parseTree, err := parser.ParseFile(filePath, parser.Recover(true))
+	if err != nil {
+		log.Printf("%s: failed to parse, no imports are generated: %v", relPath, err)
+	} else {
+		sourceFile := parseTree.(parser.SourceFile)
+		for _, syntaxErr := range sourceFile.Errors {
+			log.Printf("%s:%s: syntax error: %s, skipped", relPath, syntaxErr.Start, syntaxErr.Message)
+		}
+		for _, stmt := range sourceFile.Statements {
 			switch s := stmt.(type) {
 			case parser.Import:
-				if s.Module == "gleam/erlang/actor" {
-					fmt.Println(s, dir, file, rel)
-					printTree = true
+				target := ""
+				if s.Target != nil {
+					target = s.Target.TargetLang
 				}
-				imports[s.Module] = true
+				addImport(s.Module, s.Start, target)
 			case parser.Function:
 				if s.Name == "main" && s.Public && len(s.Parameters) == 0 {
 					hasMainFunction = true
@@ -363,17 +605,27 @@ func getGleamModuleInfo(dir, file string, rel string) (*gleamModuleInfo, error)
 					erlImports := filter(s.ExternalAttributes, func(a parser.ExternalAttribute) bool { return a.TargetLang == "erlang" })
 					if len(erlImports) > 0 {
 						erlImport := erlImports[0]
-						imports[fmt.Sprintf("erl:%s", erlImport.Module)] = true
+						addImport(fmt.Sprintf("erl:%s", erlImport.Module), erlImport.Start, targetErlang)
+					}
+					// Only relative paths point at FFI files in this repository,
+					// anything else is a JavaScript package or a runtime module.
+					jsImports := filter(s.ExternalAttributes, func(a parser.ExternalAttribute) bool {
+						return a.TargetLang == "javascript" && (strings.HasPrefix(a.Module, "./") || strings.HasPrefix(a.Module, "../"))
+					})
+					if len(jsImports) > 0 {
+						jsImport := jsImports[0]
+						addImport(fmt.Sprintf("js:%s", path.Join(rel, jsImport.Module)), jsImport.Start, targetJavascript)
 					}
 				}
 			}
 		}
 	}
-	if printTree {
-		pretty.Println(parseTree)
-	}
 
 	moduleParents := filepath.SplitList(rel)
 	moduleName := strings.TrimSuffix(file, gleamExt)
-	return &gleamModuleInfo{imports: collect(imports), moduleParents: moduleParents, moduleName: moduleName, hasMainFn: hasMainFunction, file: file}, nil
+	moduleImports := make([]gleamImport, 0, len(imports))
+	for _, imp := range imports {
+		moduleImports = append(moduleImports, imp)
+	}
+	return &gleamModuleInfo{imports: moduleImports, moduleParents: moduleParents, moduleName: moduleName, hasMainFn: hasMainFunction, file: file}
 }
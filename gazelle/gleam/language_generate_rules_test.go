@@ -15,6 +15,7 @@ import (
 	bzl "github.com/bazelbuild/buildtools/build"
 
 	"github.com/google/go-cmp/cmp"
+	"github.com/iocat/rules_gleam/gazelle/gleam/parser"
 )
 
 func TestGenerateRules(t *testing.T) {
@@ -88,6 +89,40 @@ func TestGenerateRules(t *testing.T) {
 	})
 }
 
+func TestModuleImports(t *testing.T) {
+	imp := func(file string, offset int, module string) gleamImport {
+		return gleamImport{module: module, file: file, pos: parser.Position{Line: offset + 1, Col: 1, Offset: offset}}
+	}
+	c := &config.Config{Exts: map[string]interface{}{languageName: &GleamConfig{}}}
+	gmb := &gleamModuleBundle{
+		kind: ruleKindLib,
+		name: "app",
+		modules: map[string]gleamModuleInfo{
+			"app/b": {moduleName: "app/b", file: "app/b.gleam", imports: []gleamImport{
+				imp("app/b.gleam", 0, "app/c"),
+				imp("app/b.gleam", 1, "gleam/io"),
+			}},
+			"app/a": {moduleName: "app/a", file: "app/a.gleam", imports: []gleamImport{
+				imp("app/a.gleam", 0, "app/c"),
+			}},
+		},
+		c: c,
+	}
+
+	imports := gmb.generateImports()
+	if len(imports) != 1 {
+		t.Fatalf("generateImports() returned %d imports, want 1", len(imports))
+	}
+	want := []ModuleImport{
+		{Module: "app/a", Import: "app/c", Location: "app/a.gleam:1:1"},
+		{Module: "app/b", Import: "app/c", Location: "app/b.gleam:1:1"},
+		{Module: "app/b", Import: "gleam/io", Location: "app/b.gleam:2:1"},
+	}
+	if diff := cmp.Diff(want, ModuleImports(imports[0])); diff != "" {
+		t.Errorf("ModuleImports() (-want, +got): %s", diff)
+	}
+}
+
 func convertImportsAttrs(f *rule.File) {
 	for _, r := range f.Rules {
 		v := r.PrivateAttr(config.GazelleImportsKey)
@@ -0,0 +1,103 @@
+package gleam
+
+import (
+	"log"
+	"path"
+	"strings"
+
+	"github.com/bazelbuild/bazel-gazelle/label"
+)
+
+// moduleIndex maps the imports of the modules of the dependencies, e.g.
+// "gleam/list", "erl:gleam_stdlib_ffi" or "erl:Elixir.Jason", to where
+// they're built. Imports only resolve to a module of exactly their path, so
+// "gleam/json_extra" never resolves to the repository of "gleam/json".
+//
+// The index is built once by CheckFlags and shared by the configs of every
+// directory, it isn't modified afterwards.
+type moduleIndex struct {
+	modules map[string]indexedModule
+}
+
+// indexedModule is a module of the package of a remote repository.
+type indexedModule struct {
+	// Repository of the package, e.g. hex_gleam_stdlib.
+	repo string
+	// Rule building the module. Unset for the Elixir modules of mix
+	// packages, which rules_gleam doesn't build.
+	label label.Label
+	// Whether the package is only required by the dev-dependencies of
+	// gleam.toml, so that only gleam_test rules may import the module.
+	devOnly bool
+}
+
+func newModuleIndex() *moduleIndex {
+	return &moduleIndex{modules: make(map[string]indexedModule)}
+}
+
+// addGleam indexes a Gleam module of a repository, e.g. "gleam/list", built
+// by the rule named after it in the package of its directory.
+func (ix *moduleIndex) addGleam(repo, module string) {
+	ix.add(module, indexedModule{repo: repo, label: gleamModuleLabel(repo, module)})
+}
+
+// addErlang indexes an Erlang module of a repository, built by the rule of
+// the given label.
+func (ix *moduleIndex) addErlang(repo, module string, l label.Label) {
+	ix.add("erl:"+module, indexedModule{repo: repo, label: l})
+}
+
+// addElixir indexes an Elixir module of a mix package, e.g. "Elixir.Jason".
+func (ix *moduleIndex) addElixir(repo, module string) {
+	ix.add("erl:"+module, indexedModule{repo: repo, label: label.NoLabel})
+}
+
+// add indexes the module of an import. Modules share one namespace, so a
+// module of two repositories is reported and the first one is kept.
+func (ix *moduleIndex) add(imp string, m indexedModule) {
+	if existing, ok := ix.modules[imp]; ok {
+		if existing != m {
+			log.Printf("module %s is provided by both %s and %s, using %s", imp, existing.repo, m.repo, existing.repo)
+		}
+		return
+	}
+	ix.modules[imp] = m
+}
+
+// merge adds the modules of another index.
+func (ix *moduleIndex) merge(other *moduleIndex) {
+	for imp, m := range other.modules {
+		ix.add(imp, m)
+	}
+}
+
+// markDevOnly marks the modules of the given repositories as only available
+// to gleam_test rules.
+func (ix *moduleIndex) markDevOnly(repos map[string]bool) {
+	for imp, m := range ix.modules {
+		if repos[m.repo] {
+			m.devOnly = true
+			ix.modules[imp] = m
+		}
+	}
+}
+
+// lookup returns the module of an import. Modules of dev-only packages are
+// only returned with dev, for gleam_test rules.
+func (ix *moduleIndex) lookup(imp string, dev bool) (indexedModule, bool) {
+	if ix == nil {
+		return indexedModule{}, false
+	}
+	m, ok := ix.modules[imp]
+	if !ok || (m.devOnly && !dev) {
+		return indexedModule{}, false
+	}
+	return m, true
+}
+
+// gleamModuleLabel returns the label of the rule building a Gleam module of
+// a repository, e.g. @hex_gleam_stdlib//gleam:list for "gleam/list".
+func gleamModuleLabel(repo, module string) label.Label {
+	pkg, name := path.Split(module)
+	return label.New(repo, strings.TrimSuffix(pkg, "/"), name)
+}
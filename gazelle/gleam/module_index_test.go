@@ -0,0 +1,41 @@
+package gleam
+
+import (
+	"testing"
+
+	"github.com/bazelbuild/bazel-gazelle/label"
+)
+
+func TestModuleIndexLookup(t *testing.T) {
+	modules := newModuleIndex()
+	modules.addGleam("hex_gleam_json", "gleam/json")
+	modules.addGleam("hex_gleam_json_extra", "gleam/json_extra")
+	modules.addGleam("hex_other_json", "gleam/json")
+	modules.addGleam("hex_qcheck", "qcheck")
+	modules.markDevOnly(map[string]bool{"hex_qcheck": true})
+
+	testCases := []struct {
+		imp       string
+		dev       bool
+		wantLabel label.Label
+		wantOk    bool
+	}{
+		{imp: "gleam/json", wantLabel: label.New("hex_gleam_json", "gleam", "json"), wantOk: true},
+		{imp: "gleam/json_extra", wantLabel: label.New("hex_gleam_json_extra", "gleam", "json_extra"), wantOk: true},
+		{imp: "gleam/json/extra"},
+		{imp: "gleam"},
+		{imp: "qcheck"},
+		{imp: "qcheck", dev: true, wantLabel: label.New("hex_qcheck", "", "qcheck"), wantOk: true},
+	}
+	for _, tc := range testCases {
+		m, ok := modules.lookup(tc.imp, tc.dev)
+		if ok != tc.wantOk || m.label != tc.wantLabel {
+			t.Errorf("lookup(%q, %v) = %v, %v, want %v, %v", tc.imp, tc.dev, m.label, ok, tc.wantLabel, tc.wantOk)
+		}
+	}
+
+	var unset *moduleIndex
+	if _, ok := unset.lookup("gleam/json", true); ok {
+		t.Errorf("lookup() of an unset index found a module")
+	}
+}
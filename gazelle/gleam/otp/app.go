@@ -0,0 +1,100 @@
+package otp
+
+import (
+	"fmt"
+	"strings"
+	"unicode"
+)
+
+// parseApp returns the name and the modules of an application resource
+// file, e.g.
+//
+//	{application, stdlib,
+//	 [{description, "ERTS  CXC 138 10"},
+//	  {vsn, "6.2"},
+//	  {modules, [array, base64, ...]},
+//	  ...]}.
+//
+// Only the atoms, strings and punctuation needed to find them are
+// understood; other terms are skipped.
+func parseApp(data string) (string, []string, error) {
+	tokens, err := tokenizeTerms(data)
+	if err != nil {
+		return "", nil, err
+	}
+	if len(tokens) < 5 || tokens[0] != "{" || tokens[1] != "application" || tokens[2] != "," || tokens[4] != "," {
+		return "", nil, fmt.Errorf("not an application resource file")
+	}
+	name := tokens[3]
+	for i := 5; i+3 < len(tokens); i++ {
+		if tokens[i] != "{" || tokens[i+1] != "modules" || tokens[i+2] != "," || tokens[i+3] != "[" {
+			continue
+		}
+		var modules []string
+		for _, token := range tokens[i+4:] {
+			switch token {
+			case "]":
+				return name, modules, nil
+			case ",":
+			default:
+				modules = append(modules, token)
+			}
+		}
+		return "", nil, fmt.Errorf("unterminated modules list of application %s", name)
+	}
+	return name, nil, nil
+}
+
+// tokenizeTerms splits Erlang terms into atoms, with quoted atoms unquoted,
+// punctuation and other literals. Comments are dropped, and strings are
+// kept quoted so they can't be mistaken for atoms.
+func tokenizeTerms(data string) ([]string, error) {
+	var tokens []string
+	for i := 0; i < len(data); {
+		c := data[i]
+		switch {
+		case c == '%':
+			for i < len(data) && data[i] != '\n' {
+				i++
+			}
+		case unicode.IsSpace(rune(c)):
+			i++
+		case strings.IndexByte("{}[],.", c) >= 0:
+			tokens = append(tokens, string(c))
+			i++
+		case c == '\'' || c == '"':
+			j := i + 1
+			var b strings.Builder
+			for ; j < len(data) && data[j] != c; j++ {
+				if data[j] == '\\' && j+1 < len(data) {
+					j++
+				}
+				b.WriteByte(data[j])
+			}
+			if j == len(data) {
+				return nil, fmt.Errorf("unterminated %c at offset %d", c, i)
+			}
+			if c == '"' {
+				tokens = append(tokens, `"`+b.String()+`"`)
+			} else {
+				tokens = append(tokens, b.String())
+			}
+			i = j + 1
+		default:
+			j := i
+			for j < len(data) && !unicode.IsSpace(rune(data[j])) && strings.IndexByte("{}[],%'\"", data[j]) < 0 {
+				j++
+			}
+			// A dot ending the term isn't part of the atom.
+			if j > i+1 && data[j-1] == '.' {
+				j--
+			}
+			if j == i {
+				j++
+			}
+			tokens = append(tokens, data[i:j])
+			i = j
+		}
+	}
+	return tokens, nil
+}
@@ -0,0 +1,582 @@
+package otp
+
+// module is a documented module of an OTP application, present from OTP
+// release since (0 for every supported release).
+type module struct {
+	name        string
+	application string
+	since       int
+}
+
+// modules of the supported OTP releases, by application, as documented for
+// full releases: https://www.erlang.org/doc/man_index.html. Only the
+// modules added by a release are tracked, not the ones it removed, and
+// installations may be built without some applications, e.g. wx without
+// wxWidgets. ReadRoot reads the modules actually installed.
+var modules = []module{
+	// asn1
+	{"asn1ct", "asn1", 0},
+	// common_test
+	{"ct", "common_test", 0},
+	{"ct_cover", "common_test", 0},
+	{"ct_ftp", "common_test", 0},
+	{"ct_hooks", "common_test", 0},
+	{"ct_master", "common_test", 0},
+	{"ct_netconfc", "common_test", 0},
+	{"ct_property_test", "common_test", 0},
+	{"ct_rpc", "common_test", 0},
+	{"ct_slave", "common_test", 0},
+	{"ct_snmp", "common_test", 0},
+	{"ct_ssh", "common_test", 0},
+	{"ct_suite", "common_test", 0},
+	{"ct_telnet", "common_test", 0},
+	{"ct_testspec", "common_test", 0},
+	{"unix_telnet", "common_test", 0},
+	// compiler
+	{"cerl", "compiler", 0},
+	{"cerl_clauses", "compiler", 0},
+	{"cerl_trees", "compiler", 0},
+	{"compile", "compiler", 0},
+	// crypto
+	{"crypto", "crypto", 0},
+	// debugger
+	{"debugger", "debugger", 0},
+	{"i", "debugger", 0},
+	{"int", "debugger", 0},
+	// dialyzer
+	{"dialyzer", "dialyzer", 0},
+	// diameter
+	{"diameter", "diameter", 0},
+	{"diameter_app", "diameter", 0},
+	{"diameter_codec", "diameter", 0},
+	{"diameter_make", "diameter", 0},
+	{"diameter_sctp", "diameter", 0},
+	{"diameter_tcp", "diameter", 0},
+	{"diameter_transport", "diameter", 0},
+	// edoc
+	{"edoc", "edoc", 0},
+	{"edoc_doclet", "edoc", 0},
+	{"edoc_doclet_chunks", "edoc", 0},
+	{"edoc_doclet_markdown", "edoc", 27},
+	{"edoc_extract", "edoc", 0},
+	{"edoc_html_to_markdown", "edoc", 27},
+	{"edoc_layout", "edoc", 0},
+	{"edoc_layout_chunks", "edoc", 0},
+	{"edoc_lib", "edoc", 0},
+	{"edoc_run", "edoc", 0},
+	// eldap
+	{"eldap", "eldap", 0},
+	// erts
+	{"atomics", "erts", 0},
+	{"counters", "erts", 0},
+	{"erl_prim_loader", "erts", 0},
+	{"erl_tracer", "erts", 0},
+	{"erlang", "erts", 0},
+	{"init", "erts", 0},
+	{"persistent_term", "erts", 0},
+	{"zlib", "erts", 0},
+	// et
+	{"et", "et", 0},
+	{"et_collector", "et", 0},
+	{"et_selector", "et", 0},
+	{"et_viewer", "et", 0},
+	// eunit
+	{"eunit", "eunit", 0},
+	{"eunit_surefire", "eunit", 0},
+	// ftp
+	{"ftp", "ftp", 0},
+	// inets
+	{"http_uri", "inets", 0},
+	{"httpc", "inets", 0},
+	{"httpd", "inets", 0},
+	{"httpd_custom_api", "inets", 0},
+	{"httpd_socket", "inets", 0},
+	{"httpd_util", "inets", 0},
+	{"inets", "inets", 0},
+	{"mod_alias", "inets", 0},
+	{"mod_auth", "inets", 0},
+	{"mod_esi", "inets", 0},
+	{"mod_security", "inets", 0},
+	// kernel
+	{"application", "kernel", 0},
+	{"auth", "kernel", 0},
+	{"code", "kernel", 0},
+	{"disk_log", "kernel", 0},
+	{"erl_boot_server", "kernel", 0},
+	{"erl_ddll", "kernel", 0},
+	{"erl_debugger", "kernel", 28},
+	{"erl_epmd", "kernel", 0},
+	{"erpc", "kernel", 0},
+	{"error_handler", "kernel", 0},
+	{"error_logger", "kernel", 0},
+	{"file", "kernel", 0},
+	{"gen_sctp", "kernel", 0},
+	{"gen_tcp", "kernel", 0},
+	{"gen_udp", "kernel", 0},
+	{"global", "kernel", 0},
+	{"global_group", "kernel", 0},
+	{"heart", "kernel", 0},
+	{"inet", "kernel", 0},
+	{"inet_res", "kernel", 0},
+	{"logger", "kernel", 0},
+	{"logger_disk_log_h", "kernel", 0},
+	{"logger_filters", "kernel", 0},
+	{"logger_formatter", "kernel", 0},
+	{"logger_handler", "kernel", 0},
+	{"logger_std_h", "kernel", 0},
+	{"net", "kernel", 0},
+	{"net_adm", "kernel", 0},
+	{"net_kernel", "kernel", 0},
+	{"os", "kernel", 0},
+	{"pg", "kernel", 0},
+	{"rpc", "kernel", 0},
+	{"seq_trace", "kernel", 0},
+	{"socket", "kernel", 0},
+	{"trace", "kernel", 27},
+	{"wrap_log_reader", "kernel", 0},
+	// megaco
+	{"megaco", "megaco", 0},
+	{"megaco_codec_meas", "megaco", 0},
+	{"megaco_codec_mstone1", "megaco", 0},
+	{"megaco_codec_mstone2", "megaco", 0},
+	{"megaco_codec_transform", "megaco", 0},
+	{"megaco_digit_map", "megaco", 0},
+	{"megaco_edist_compress", "megaco", 0},
+	{"megaco_encoder", "megaco", 0},
+	{"megaco_flex_scanner", "megaco", 0},
+	{"megaco_sdp", "megaco", 0},
+	{"megaco_tcp", "megaco", 0},
+	{"megaco_transport", "megaco", 0},
+	{"megaco_udp", "megaco", 0},
+	{"megaco_user", "megaco", 0},
+	// mnesia
+	{"mnesia", "mnesia", 0},
+	{"mnesia_frag_hash", "mnesia", 0},
+	{"mnesia_registry", "mnesia", 0},
+	// observer
+	{"crashdump_viewer", "observer", 0},
+	{"etop", "observer", 0},
+	{"observer", "observer", 0},
+	{"ttb", "observer", 0},
+	// odbc
+	{"odbc", "odbc", 0},
+	// os_mon
+	{"cpu_sup", "os_mon", 0},
+	{"disksup", "os_mon", 0},
+	{"memsup", "os_mon", 0},
+	{"nteventlog", "os_mon", 0},
+	{"os_sup", "os_mon", 0},
+	// parsetools
+	{"leex", "parsetools", 0},
+	{"yecc", "parsetools", 0},
+	// public_key
+	{"public_key", "public_key", 0},
+	// reltool
+	{"reltool", "reltool", 0},
+	// runtime_tools
+	{"dbg", "runtime_tools", 0},
+	{"dyntrace", "runtime_tools", 0},
+	{"msacc", "runtime_tools", 0},
+	{"scheduler", "runtime_tools", 0},
+	{"system_information", "runtime_tools", 0},
+	// sasl
+	{"alarm_handler", "sasl", 0},
+	{"rb", "sasl", 0},
+	{"release_handler", "sasl", 0},
+	{"systools", "sasl", 0},
+	// snmp
+	{"snmp", "snmp", 0},
+	{"snmp_community_mib", "snmp", 0},
+	{"snmp_framework_mib", "snmp", 0},
+	{"snmp_generic", "snmp", 0},
+	{"snmp_index", "snmp", 0},
+	{"snmp_notification_mib", "snmp", 0},
+	{"snmp_pdus", "snmp", 0},
+	{"snmp_standard_mib", "snmp", 0},
+	{"snmp_target_mib", "snmp", 0},
+	{"snmp_user_based_sm_mib", "snmp", 0},
+	{"snmp_view_based_acm_mib", "snmp", 0},
+	{"snmpa", "snmp", 0},
+	{"snmpa_conf", "snmp", 0},
+	{"snmpa_discovery_handler", "snmp", 0},
+	{"snmpa_error", "snmp", 0},
+	{"snmpa_error_io", "snmp", 0},
+	{"snmpa_error_logger", "snmp", 0},
+	{"snmpa_error_report", "snmp", 0},
+	{"snmpa_local_db", "snmp", 0},
+	{"snmpa_mib_data", "snmp", 0},
+	{"snmpa_mib_storage", "snmp", 0},
+	{"snmpa_mpd", "snmp", 0},
+	{"snmpa_network_interface", "snmp", 0},
+	{"snmpa_network_interface_filter", "snmp", 0},
+	{"snmpa_notification_delivery_info_receiver", "snmp", 0},
+	{"snmpa_notification_filter", "snmp", 0},
+	{"snmpa_supervisor", "snmp", 0},
+	{"snmpc", "snmp", 0},
+	{"snmpm", "snmp", 0},
+	{"snmpm_conf", "snmp", 0},
+	{"snmpm_mpd", "snmp", 0},
+	{"snmpm_network_interface", "snmp", 0},
+	{"snmpm_network_interface_filter", "snmp", 0},
+	{"snmpm_user", "snmp", 0},
+	// ssh
+	{"ssh", "ssh", 0},
+	{"ssh_agent", "ssh", 0},
+	{"ssh_client_channel", "ssh", 0},
+	{"ssh_client_key_api", "ssh", 0},
+	{"ssh_connection", "ssh", 0},
+	{"ssh_file", "ssh", 0},
+	{"ssh_server_channel", "ssh", 0},
+	{"ssh_server_key_api", "ssh", 0},
+	{"ssh_sftp", "ssh", 0},
+	{"ssh_sftpd", "ssh", 0},
+	// ssl
+	{"ssl", "ssl", 0},
+	{"ssl_crl_cache", "ssl", 0},
+	{"ssl_crl_cache_api", "ssl", 0},
+	{"ssl_session_cache_api", "ssl", 0},
+	// stdlib
+	{"argparse", "stdlib", 26},
+	{"array", "stdlib", 0},
+	{"base64", "stdlib", 0},
+	{"beam_lib", "stdlib", 0},
+	{"binary", "stdlib", 0},
+	{"c", "stdlib", 0},
+	{"calendar", "stdlib", 0},
+	{"dets", "stdlib", 0},
+	{"dict", "stdlib", 0},
+	{"digraph", "stdlib", 0},
+	{"digraph_utils", "stdlib", 0},
+	{"edlin", "stdlib", 0},
+	{"edlin_expand", "stdlib", 0},
+	{"epp", "stdlib", 0},
+	{"erl_anno", "stdlib", 0},
+	{"erl_error", "stdlib", 0},
+	{"erl_eval", "stdlib", 0},
+	{"erl_expand_records", "stdlib", 0},
+	{"erl_features", "stdlib", 0},
+	{"erl_id_trans", "stdlib", 0},
+	{"erl_internal", "stdlib", 0},
+	{"erl_lint", "stdlib", 0},
+	{"erl_parse", "stdlib", 0},
+	{"erl_pp", "stdlib", 0},
+	{"erl_scan", "stdlib", 0},
+	{"erl_tar", "stdlib", 0},
+	{"escript", "stdlib", 0},
+	{"ets", "stdlib", 0},
+	{"file_sorter", "stdlib", 0},
+	{"filelib", "stdlib", 0},
+	{"filename", "stdlib", 0},
+	{"gb_sets", "stdlib", 0},
+	{"gb_trees", "stdlib", 0},
+	{"gen_event", "stdlib", 0},
+	{"gen_fsm", "stdlib", 0},
+	{"gen_server", "stdlib", 0},
+	{"gen_statem", "stdlib", 0},
+	{"io", "stdlib", 0},
+	{"io_lib", "stdlib", 0},
+	{"json", "stdlib", 27},
+	{"lists", "stdlib", 0},
+	{"log_mf_h", "stdlib", 0},
+	{"maps", "stdlib", 0},
+	{"math", "stdlib", 0},
+	{"ms_transform", "stdlib", 0},
+	{"orddict", "stdlib", 0},
+	{"ordsets", "stdlib", 0},
+	{"peer", "stdlib", 0},
+	{"pool", "stdlib", 0},
+	{"proc_lib", "stdlib", 0},
+	{"proplists", "stdlib", 0},
+	{"qlc", "stdlib", 0},
+	{"queue", "stdlib", 0},
+	{"rand", "stdlib", 0},
+	{"random", "stdlib", 0},
+	{"re", "stdlib", 0},
+	{"sets", "stdlib", 0},
+	{"shell", "stdlib", 0},
+	{"shell_default", "stdlib", 0},
+	{"shell_docs", "stdlib", 0},
+	{"slave", "stdlib", 0},
+	{"sofs", "stdlib", 0},
+	{"string", "stdlib", 0},
+	{"supervisor", "stdlib", 0},
+	{"supervisor_bridge", "stdlib", 0},
+	{"sys", "stdlib", 0},
+	{"timer", "stdlib", 0},
+	{"unicode", "stdlib", 0},
+	{"uri_string", "stdlib", 0},
+	{"win32reg", "stdlib", 0},
+	{"zip", "stdlib", 0},
+	{"zstd", "stdlib", 28},
+	// syntax_tools
+	{"epp_dodger", "syntax_tools", 0},
+	{"erl_comment_scan", "syntax_tools", 0},
+	{"erl_prettypr", "syntax_tools", 0},
+	{"erl_recomment", "syntax_tools", 0},
+	{"erl_syntax", "syntax_tools", 0},
+	{"erl_syntax_lib", "syntax_tools", 0},
+	{"merl", "syntax_tools", 0},
+	{"merl_transform", "syntax_tools", 0},
+	{"prettypr", "syntax_tools", 0},
+	// tftp
+	{"tftp", "tftp", 0},
+	{"tftp_logger", "tftp", 0},
+	// tools
+	{"cover", "tools", 0},
+	{"cprof", "tools", 0},
+	{"eprof", "tools", 0},
+	{"fprof", "tools", 0},
+	{"instrument", "tools", 0},
+	{"lcnt", "tools", 0},
+	{"make", "tools", 0},
+	{"tags", "tools", 0},
+	{"tprof", "tools", 27},
+	{"xref", "tools", 0},
+	// wx
+	{"gl", "wx", 0},
+	{"glu", "wx", 0},
+	{"wx", "wx", 0},
+	{"wxAcceleratorEntry", "wx", 0},
+	{"wxAcceleratorTable", "wx", 0},
+	{"wxActivateEvent", "wx", 0},
+	{"wxArtProvider", "wx", 0},
+	{"wxAuiDockArt", "wx", 0},
+	{"wxAuiManager", "wx", 0},
+	{"wxAuiManagerEvent", "wx", 0},
+	{"wxAuiNotebook", "wx", 0},
+	{"wxAuiNotebookEvent", "wx", 0},
+	{"wxAuiPaneInfo", "wx", 0},
+	{"wxAuiSimpleTabArt", "wx", 0},
+	{"wxAuiTabArt", "wx", 0},
+	{"wxBitmap", "wx", 0},
+	{"wxBitmapButton", "wx", 0},
+	{"wxBitmapDataObject", "wx", 0},
+	{"wxBookCtrlBase", "wx", 0},
+	{"wxBookCtrlEvent", "wx", 0},
+	{"wxBoxSizer", "wx", 0},
+	{"wxBrush", "wx", 0},
+	{"wxBufferedDC", "wx", 0},
+	{"wxBufferedPaintDC", "wx", 0},
+	{"wxButton", "wx", 0},
+	{"wxCalendarCtrl", "wx", 0},
+	{"wxCalendarDateAttr", "wx", 0},
+	{"wxCalendarEvent", "wx", 0},
+	{"wxCaret", "wx", 0},
+	{"wxCheckBox", "wx", 0},
+	{"wxCheckListBox", "wx", 0},
+	{"wxChildFocusEvent", "wx", 0},
+	{"wxChoice", "wx", 0},
+	{"wxChoicebook", "wx", 0},
+	{"wxClientDC", "wx", 0},
+	{"wxClipboard", "wx", 0},
+	{"wxClipboardTextEvent", "wx", 0},
+	{"wxCloseEvent", "wx", 0},
+	{"wxColourData", "wx", 0},
+	{"wxColourDialog", "wx", 0},
+	{"wxColourPickerCtrl", "wx", 0},
+	{"wxColourPickerEvent", "wx", 0},
+	{"wxComboBox", "wx", 0},
+	{"wxCommandEvent", "wx", 0},
+	{"wxContextMenuEvent", "wx", 0},
+	{"wxControl", "wx", 0},
+	{"wxControlWithItems", "wx", 0},
+	{"wxCursor", "wx", 0},
+	{"wxDC", "wx", 0},
+	{"wxDCOverlay", "wx", 0},
+	{"wxDataObject", "wx", 0},
+	{"wxDateEvent", "wx", 0},
+	{"wxDatePickerCtrl", "wx", 0},
+	{"wxDialog", "wx", 0},
+	{"wxDirDialog", "wx", 0},
+	{"wxDirPickerCtrl", "wx", 0},
+	{"wxDisplay", "wx", 0},
+	{"wxDisplayChangedEvent", "wx", 0},
+	{"wxDropFilesEvent", "wx", 0},
+	{"wxEraseEvent", "wx", 0},
+	{"wxEvent", "wx", 0},
+	{"wxEvtHandler", "wx", 0},
+	{"wxFileDataObject", "wx", 0},
+	{"wxFileDialog", "wx", 0},
+	{"wxFileDirPickerEvent", "wx", 0},
+	{"wxFilePickerCtrl", "wx", 0},
+	{"wxFindReplaceData", "wx", 0},
+	{"wxFindReplaceDialog", "wx", 0},
+	{"wxFlexGridSizer", "wx", 0},
+	{"wxFocusEvent", "wx", 0},
+	{"wxFont", "wx", 0},
+	{"wxFontData", "wx", 0},
+	{"wxFontDialog", "wx", 0},
+	{"wxFontPickerCtrl", "wx", 0},
+	{"wxFontPickerEvent", "wx", 0},
+	{"wxFrame", "wx", 0},
+	{"wxGBSizerItem", "wx", 0},
+	{"wxGCDC", "wx", 0},
+	{"wxGLCanvas", "wx", 0},
+	{"wxGLContext", "wx", 0},
+	{"wxGauge", "wx", 0},
+	{"wxGenericDirCtrl", "wx", 0},
+	{"wxGraphicsBrush", "wx", 0},
+	{"wxGraphicsContext", "wx", 0},
+	{"wxGraphicsFont", "wx", 0},
+	{"wxGraphicsGradientStops", "wx", 0},
+	{"wxGraphicsMatrix", "wx", 0},
+	{"wxGraphicsObject", "wx", 0},
+	{"wxGraphicsPath", "wx", 0},
+	{"wxGraphicsPen", "wx", 0},
+	{"wxGraphicsRenderer", "wx", 0},
+	{"wxGrid", "wx", 0},
+	{"wxGridBagSizer", "wx", 0},
+	{"wxGridCellAttr", "wx", 0},
+	{"wxGridCellBoolEditor", "wx", 0},
+	{"wxGridCellBoolRenderer", "wx", 0},
+	{"wxGridCellChoiceEditor", "wx", 0},
+	{"wxGridCellEditor", "wx", 0},
+	{"wxGridCellFloatEditor", "wx", 0},
+	{"wxGridCellFloatRenderer", "wx", 0},
+	{"wxGridCellNumberEditor", "wx", 0},
+	{"wxGridCellNumberRenderer", "wx", 0},
+	{"wxGridCellRenderer", "wx", 0},
+	{"wxGridCellStringRenderer", "wx", 0},
+	{"wxGridCellTextEditor", "wx", 0},
+	{"wxGridEvent", "wx", 0},
+	{"wxGridSizer", "wx", 0},
+	{"wxHelpEvent", "wx", 0},
+	{"wxHtmlEasyPrinting", "wx", 0},
+	{"wxHtmlLinkEvent", "wx", 0},
+	{"wxHtmlWindow", "wx", 0},
+	{"wxIcon", "wx", 0},
+	{"wxIconBundle", "wx", 0},
+	{"wxIconizeEvent", "wx", 0},
+	{"wxIdleEvent", "wx", 0},
+	{"wxImage", "wx", 0},
+	{"wxImageList", "wx", 0},
+	{"wxInitDialogEvent", "wx", 0},
+	{"wxJoystickEvent", "wx", 0},
+	{"wxKeyEvent", "wx", 0},
+	{"wxLayoutAlgorithm", "wx", 0},
+	{"wxListBox", "wx", 0},
+	{"wxListCtrl", "wx", 0},
+	{"wxListEvent", "wx", 0},
+	{"wxListItem", "wx", 0},
+	{"wxListItemAttr", "wx", 0},
+	{"wxListView", "wx", 0},
+	{"wxListbook", "wx", 0},
+	{"wxLocale", "wx", 0},
+	{"wxLogNull", "wx", 0},
+	{"wxMDIChildFrame", "wx", 0},
+	{"wxMDIClientWindow", "wx", 0},
+	{"wxMDIParentFrame", "wx", 0},
+	{"wxMask", "wx", 0},
+	{"wxMaximizeEvent", "wx", 0},
+	{"wxMemoryDC", "wx", 0},
+	{"wxMenu", "wx", 0},
+	{"wxMenuBar", "wx", 0},
+	{"wxMenuEvent", "wx", 0},
+	{"wxMenuItem", "wx", 0},
+	{"wxMessageDialog", "wx", 0},
+	{"wxMiniFrame", "wx", 0},
+	{"wxMirrorDC", "wx", 0},
+	{"wxMouseCaptureChangedEvent", "wx", 0},
+	{"wxMouseCaptureLostEvent", "wx", 0},
+	{"wxMouseEvent", "wx", 0},
+	{"wxMoveEvent", "wx", 0},
+	{"wxMultiChoiceDialog", "wx", 0},
+	{"wxNavigationKeyEvent", "wx", 0},
+	{"wxNotebook", "wx", 0},
+	{"wxNotificationMessage", "wx", 0},
+	{"wxNotifyEvent", "wx", 0},
+	{"wxOverlay", "wx", 0},
+	{"wxPageSetupDialog", "wx", 0},
+	{"wxPageSetupDialogData", "wx", 0},
+	{"wxPaintDC", "wx", 0},
+	{"wxPaintEvent", "wx", 0},
+	{"wxPalette", "wx", 0},
+	{"wxPaletteChangedEvent", "wx", 0},
+	{"wxPanel", "wx", 0},
+	{"wxPasswordEntryDialog", "wx", 0},
+	{"wxPen", "wx", 0},
+	{"wxPickerBase", "wx", 0},
+	{"wxPopupTransientWindow", "wx", 0},
+	{"wxPopupWindow", "wx", 0},
+	{"wxPostScriptDC", "wx", 0},
+	{"wxPreviewCanvas", "wx", 0},
+	{"wxPreviewControlBar", "wx", 0},
+	{"wxPreviewFrame", "wx", 0},
+	{"wxPrintData", "wx", 0},
+	{"wxPrintDialog", "wx", 0},
+	{"wxPrintDialogData", "wx", 0},
+	{"wxPrintPreview", "wx", 0},
+	{"wxPrinter", "wx", 0},
+	{"wxPrintout", "wx", 0},
+	{"wxProgressDialog", "wx", 0},
+	{"wxQueryNewPaletteEvent", "wx", 0},
+	{"wxRadioBox", "wx", 0},
+	{"wxRadioButton", "wx", 0},
+	{"wxRegion", "wx", 0},
+	{"wxSashEvent", "wx", 0},
+	{"wxSashLayoutWindow", "wx", 0},
+	{"wxSashWindow", "wx", 0},
+	{"wxScreenDC", "wx", 0},
+	{"wxScrollBar", "wx", 0},
+	{"wxScrollEvent", "wx", 0},
+	{"wxScrollWinEvent", "wx", 0},
+	{"wxScrolledWindow", "wx", 0},
+	{"wxSetCursorEvent", "wx", 0},
+	{"wxShowEvent", "wx", 0},
+	{"wxSingleChoiceDialog", "wx", 0},
+	{"wxSizeEvent", "wx", 0},
+	{"wxSizer", "wx", 0},
+	{"wxSizerFlags", "wx", 0},
+	{"wxSizerItem", "wx", 0},
+	{"wxSlider", "wx", 0},
+	{"wxSpinButton", "wx", 0},
+	{"wxSpinCtrl", "wx", 0},
+	{"wxSpinEvent", "wx", 0},
+	{"wxSplashScreen", "wx", 0},
+	{"wxSplitterEvent", "wx", 0},
+	{"wxSplitterWindow", "wx", 0},
+	{"wxStaticBitmap", "wx", 0},
+	{"wxStaticBox", "wx", 0},
+	{"wxStaticBoxSizer", "wx", 0},
+	{"wxStaticLine", "wx", 0},
+	{"wxStaticText", "wx", 0},
+	{"wxStatusBar", "wx", 0},
+	{"wxStdDialogButtonSizer", "wx", 0},
+	{"wxStyledTextCtrl", "wx", 0},
+	{"wxStyledTextEvent", "wx", 0},
+	{"wxSysColourChangedEvent", "wx", 0},
+	{"wxSystemOptions", "wx", 0},
+	{"wxSystemSettings", "wx", 0},
+	{"wxTaskBarIcon", "wx", 0},
+	{"wxTaskBarIconEvent", "wx", 0},
+	{"wxTextAttr", "wx", 0},
+	{"wxTextCtrl", "wx", 0},
+	{"wxTextDataObject", "wx", 0},
+	{"wxTextEntryDialog", "wx", 0},
+	{"wxToggleButton", "wx", 0},
+	{"wxToolBar", "wx", 0},
+	{"wxToolTip", "wx", 0},
+	{"wxToolbook", "wx", 0},
+	{"wxTopLevelWindow", "wx", 0},
+	{"wxTreeCtrl", "wx", 0},
+	{"wxTreeEvent", "wx", 0},
+	{"wxTreebook", "wx", 0},
+	{"wxUpdateUIEvent", "wx", 0},
+	{"wxWebView", "wx", 0},
+	{"wxWebViewEvent", "wx", 0},
+	{"wxWindow", "wx", 0},
+	{"wxWindowCreateEvent", "wx", 0},
+	{"wxWindowDC", "wx", 0},
+	{"wxWindowDestroyEvent", "wx", 0},
+	{"wxXmlResource", "wx", 0},
+	{"wx_misc", "wx", 0},
+	{"wx_object", "wx", 0},
+	// xmerl
+	{"xmerl", "xmerl", 0},
+	{"xmerl_eventp", "xmerl", 0},
+	{"xmerl_sax_parser", "xmerl", 0},
+	{"xmerl_scan", "xmerl", 0},
+	{"xmerl_xpath", "xmerl", 0},
+	{"xmerl_xs", "xmerl", 0},
+	{"xmerl_xsd", "xmerl", 0},
+}
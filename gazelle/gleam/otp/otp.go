@@ -0,0 +1,158 @@
+// Package otp knows which Erlang modules come with OTP and which OTP
+// application each of them belongs to, so that "erl:" imports of them aren't
+// resolved to Bazel targets.
+package otp
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+const (
+	// DefaultVersion is the OTP release of the default Erlang toolchain.
+	DefaultVersion = "27"
+
+	// Range of the OTP releases ForVersion knows about.
+	minVersion = 25
+	maxVersion = 28
+)
+
+// releases caches the modules of the OTP releases ForVersion returned.
+var releases sync.Map
+
+// Modules maps the modules of an OTP release to their application.
+type Modules struct {
+	// Release the modules come from, e.g. "27" or "27.3.3". Empty when an
+	// installation doesn't tell.
+	version string
+	// Root directory of the installation the modules were read from, if any.
+	root         string
+	applications map[string]string
+}
+
+// ForVersion returns the modules of an OTP release. The version may be a
+// major version or a full one, e.g. "27", "27.3.3", "v27.3.3" or
+// "OTP-27.3.3".
+func ForVersion(version string) (*Modules, error) {
+	major, err := majorVersion(version)
+	if err != nil {
+		return nil, err
+	}
+	if major < minVersion || major > maxVersion {
+		return nil, fmt.Errorf("unsupported OTP version %q, must be between %d and %d", version, minVersion, maxVersion)
+	}
+	if m, ok := releases.Load(major); ok {
+		return m.(*Modules), nil
+	}
+	m := &Modules{version: strconv.Itoa(major), applications: make(map[string]string)}
+	for _, mod := range modules {
+		if mod.since > major {
+			continue
+		}
+		m.applications[mod.name] = mod.application
+	}
+	cached, _ := releases.LoadOrStore(major, m)
+	return cached.(*Modules), nil
+}
+
+// Releases returns the OTP application a module belongs to in the releases
+// ForVersion knows about, and the major versions it's in. There are none if
+// it's not an OTP module.
+func Releases(module string) (string, []string) {
+	var app string
+	var versions []string
+	for major := minVersion; major <= maxVersion; major++ {
+		m, _ := ForVersion(strconv.Itoa(major))
+		if a, ok := m.Application(module); ok {
+			app = a
+			versions = append(versions, m.Version())
+		}
+	}
+	return app, versions
+}
+
+// majorVersion returns the major version of an OTP release.
+func majorVersion(version string) (int, error) {
+	v := strings.TrimPrefix(strings.TrimPrefix(strings.TrimSpace(version), "OTP-"), "v")
+	major, _, _ := strings.Cut(v, ".")
+	n, err := strconv.Atoi(major)
+	if err != nil {
+		return 0, fmt.Errorf("invalid OTP version %q", version)
+	}
+	return n, nil
+}
+
+// ReadRoot returns the modules of the OTP installation at root, e.g. the
+// one of an Erlang toolchain, from its lib/*/ebin/*.app application
+// resource files. Its release is read from releases/*/OTP_VERSION.
+func ReadRoot(root string) (*Modules, error) {
+	files, err := filepath.Glob(filepath.Join(root, "lib", "*", "ebin", "*.app"))
+	if err != nil {
+		return nil, err
+	}
+	if len(files) == 0 {
+		return nil, fmt.Errorf("no application resource files in %s", filepath.Join(root, "lib", "*", "ebin"))
+	}
+	m := &Modules{root: root, applications: make(map[string]string)}
+	if versions, _ := filepath.Glob(filepath.Join(root, "releases", "*", "OTP_VERSION")); len(versions) > 0 {
+		data, err := os.ReadFile(versions[0])
+		if err != nil {
+			return nil, err
+		}
+		m.version = strings.TrimSpace(string(data))
+	}
+	for _, file := range files {
+		data, err := os.ReadFile(file)
+		if err != nil {
+			return nil, err
+		}
+		app, mods, err := parseApp(string(data))
+		if err != nil {
+			return nil, fmt.Errorf("%s: %v", file, err)
+		}
+		for _, mod := range mods {
+			m.applications[mod] = app
+		}
+	}
+	return m, nil
+}
+
+// Version returns the OTP release of the modules, or "" when the
+// installation they were read from doesn't tell.
+func (m *Modules) Version() string {
+	return m.version
+}
+
+// String describes where the modules come from, e.g. "OTP 27" or
+// "OTP 27.3.3 at /usr/lib/erlang".
+func (m *Modules) String() string {
+	switch {
+	case m.root == "":
+		return "OTP " + m.version
+	case m.version == "":
+		return "the OTP installation at " + m.root
+	}
+	return fmt.Sprintf("OTP %s at %s", m.version, m.root)
+}
+
+// Application returns the OTP application a module belongs to, and whether
+// it's an OTP module at all.
+func (m *Modules) Application(module string) (string, bool) {
+	app, ok := m.applications[module]
+	return app, ok
+}
+
+// Applications returns the sorted OTP applications of the modules.
+func (m *Modules) Applications() []string {
+	var apps []string
+	for _, app := range m.applications {
+		apps = append(apps, app)
+	}
+	slices.Sort(apps)
+	return slices.Compact(apps)
+}
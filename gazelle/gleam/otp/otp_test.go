@@ -0,0 +1,112 @@
+package otp
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestForVersion(t *testing.T) {
+	testCases := []struct {
+		version string
+		module  string
+		wantApp string
+		wantOK  bool
+	}{
+		{version: "27", module: "lists", wantApp: "stdlib", wantOK: true},
+		{version: "v27.3.3", module: "json", wantApp: "stdlib", wantOK: true},
+		{version: "OTP-26.2.5.3", module: "json"},
+		{version: "26", module: "argparse", wantApp: "stdlib", wantOK: true},
+		{version: "25", module: "argparse"},
+		{version: "28", module: "zstd", wantApp: "stdlib", wantOK: true},
+		{version: "27", module: "wxFrame", wantApp: "wx", wantOK: true},
+		{version: "27", module: "gleam_stdlib"},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.version+"/"+tc.module, func(t *testing.T) {
+			m, err := ForVersion(tc.version)
+			if err != nil {
+				t.Fatalf("ForVersion(%q) failed: %v", tc.version, err)
+			}
+			app, ok := m.Application(tc.module)
+			if app != tc.wantApp || ok != tc.wantOK {
+				t.Errorf("Application(%q) = %q, %v, want %q, %v", tc.module, app, ok, tc.wantApp, tc.wantOK)
+			}
+		})
+	}
+}
+
+func TestForVersionInvalid(t *testing.T) {
+	for _, version := range []string{"", "latest", "24", "29.0"} {
+		if _, err := ForVersion(version); err == nil {
+			t.Errorf("ForVersion(%q) succeeded, want an error", version)
+		}
+	}
+}
+
+func TestReadRoot(t *testing.T) {
+	root := t.TempDir()
+	files := map[string]string{
+		"lib/stdlib-6.2/ebin/stdlib.app": `%% stdlib
+{application, stdlib,
+ [{description, "ERTS  CXC 138 10"},
+  {vsn, "6.2"},
+  {modules, [array, base64, 'json', % the new one
+             lists]},
+  {registered,[timer_server]},
+  {applications, [kernel]},
+  {env, []}]}.
+`,
+		"lib/elixir-1.18.0/ebin/elixir.app": `{application,elixir,[{modules,['Elixir.Enum','Elixir.String.Chars',elixir]},{vsn,"1.18.0"}]}.`,
+		"releases/27/OTP_VERSION":           "27.3.3\n",
+	}
+	for name, content := range files {
+		path := filepath.Join(root, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	m, err := ReadRoot(root)
+	if err != nil {
+		t.Fatalf("ReadRoot failed: %v", err)
+	}
+	want := map[string]string{
+		"array":               "stdlib",
+		"base64":              "stdlib",
+		"json":                "stdlib",
+		"lists":               "stdlib",
+		"Elixir.Enum":         "elixir",
+		"Elixir.String.Chars": "elixir",
+		"elixir":              "elixir",
+	}
+	if diff := cmp.Diff(want, m.applications); diff != "" {
+		t.Errorf("modules mismatch (-want +got):\n%s", diff)
+	}
+	if diff := cmp.Diff([]string{"elixir", "stdlib"}, m.Applications()); diff != "" {
+		t.Errorf("applications mismatch (-want +got):\n%s", diff)
+	}
+	if got, want := m.String(), "OTP 27.3.3 at "+root; got != want {
+		t.Errorf("String() = %q, want %q", got, want)
+	}
+
+	// Without OTP_VERSION, the release isn't known.
+	if err := os.RemoveAll(filepath.Join(root, "releases")); err != nil {
+		t.Fatal(err)
+	}
+	if m, err = ReadRoot(root); err != nil {
+		t.Fatalf("ReadRoot failed: %v", err)
+	}
+	if got, want := m.String(), "the OTP installation at "+root; m.Version() != "" || got != want {
+		t.Errorf("Version(), String() = %q, %q, want \"\", %q", m.Version(), got, want)
+	}
+
+	if _, err := ReadRoot(t.TempDir()); err == nil {
+		t.Errorf("ReadRoot of an empty directory succeeded, want an error")
+	}
+}
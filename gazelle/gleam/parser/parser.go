@@ -61,6 +61,28 @@ func parseFloat(text []byte) (float64, error) {
 
 func unquoteString(text []byte) (string, error) { return strconv.Unquote(string(text)) }
 
+// typeAlias tells a type alias apart from a custom type body while building a
+// TypeDefinition.
+type typeAlias struct{ aliased Type }
+
+// span returns the source range matched by the current expression. Trailing
+// whitespace swallowed by the rule is not part of the node.
+func (c *current) span() Span {
+	text := bytes.TrimRight(c.text, " \t\r\n")
+	start := Position{Line: c.pos.line, Col: c.pos.col, Offset: c.pos.offset}
+	end := start
+	for _, r := range string(text) {
+		if r == '\n' {
+			end.Line++
+			end.Col = 1
+		} else {
+			end.Col++
+		}
+	}
+	end.Offset += len(text)
+	return Span{Start: start, End: end}
+}
+
 // -----------------------------------------------------------------------------
 // ## AST Node Definitions
 // -----------------------------------------------------------------------------
@@ -71,14 +93,50 @@ type Expression interface{ Node }
 type Type interface{ Node }
 type Pattern interface{ Node }
 
-type SourceFile struct{ Statements []Node }
-type Identifier struct{ Name string }
-type Discard struct{ Name string }
+// Position is a location in a Gleam source file. Line and Col are 1-based,
+// Offset is the 0-based byte offset.
+type Position struct {
+	Line, Col, Offset int
+}
+
+func (p Position) String() string { return fmt.Sprintf("%d:%d", p.Line, p.Col) }
+
+// Span is the source range a node was parsed from.
+type Span struct {
+	Start, End Position
+}
+
+type SourceFile struct {
+	Span
+	Statements []Node
+	// Statements that could not be parsed and were skipped.
+	Errors []SyntaxError
+}
+
+// SyntaxError is a top-level statement that could not be parsed. The parser
+// skips it up to the next line starting a statement and carries on.
+type SyntaxError struct {
+	Span
+	Message string
+}
+
+func (e SyntaxError) Error() string { return fmt.Sprintf("%s: %s", e.Start, e.Message) }
+
+type Identifier struct {
+	Span
+	Name string
+}
+type Discard struct {
+	Span
+	Name string
+}
 type Parameter struct {
+	Span
 	Label, Name string
 	Type        Type
 }
 type Function struct {
+	Span
 	Public             bool
 	Name               string
 	Parameters         []Parameter
@@ -86,22 +144,92 @@ type Function struct {
 	ExternalAttributes []ExternalAttribute
 }
 type TargetAttribute struct {
+	Span
 	TargetLang string
 }
 type Import struct {
+	Span
 	Module      string
 	Unqualified []UnqualifiedImport
 	Alias       string
 	Target      *TargetAttribute
 }
 type ExternalAttribute struct {
+	Span
 	TargetLang string
 	Module     string
 	Function   string
 }
 
+// TypeDefinition is a custom type `type A { ... }`, a type alias `type A = B`
+// or an external type `type A`.
+type TypeDefinition struct {
+	Span
+	Public bool
+	Opaque bool
+	Name   string
+	// Generic parameters, e.g. `a` and `b` in `type Pair(a, b)`.
+	Parameters []string
+	// Variants of a custom type. Empty for type aliases and external types.
+	Constructors []TypeConstructor
+	// Set for type aliases, AliasedType is the type on the right hand side.
+	Alias       bool
+	AliasedType Type
+	Target      *TargetAttribute
+}
+
+// TypeConstructor is a single variant of a custom type, e.g. `User(name: String)`.
+type TypeConstructor struct {
+	Span
+	Name   string
+	Fields []TypeField
+}
+
+// TypeField is a labelled or positional argument of a type constructor.
+type TypeField struct {
+	Span
+	Label string
+	Type  Type
+}
+
+// NamedType is a type referenced by name, e.g. `Int`, `List(String)` or
+// `option.Option(Int)`. Module is the qualifier as written, the imported
+// module's last path segment or its alias. Type variables are NamedTypes with
+// a lowercase Name.
+type NamedType struct {
+	Span
+	Module string
+	Name   string
+	// Type arguments, e.g. `String` in `List(String)`.
+	Args []Type
+}
+
+// TupleType is a tuple type, e.g. `#(Int, String)`.
+type TupleType struct {
+	Span
+	Elements []Type
+}
+
+// FunctionType is a function type, e.g. `fn(Int) -> String`.
+type FunctionType struct {
+	Span
+	Parameters []Type
+	Return     Type
+}
+
+// Constant is a module constant, `const name: Type = value`. The value itself
+// is not parsed.
+type Constant struct {
+	Span
+	Public bool
+	Name   string
+	Type   Type
+	Target *TargetAttribute
+}
+
 // Represents a single item in an unqualified import list, e.g., `MyType` in `import gleam.{MyType}`.
 type UnqualifiedImport struct {
+	Span
 	Name   string
 	Alias  string
 	IsType bool // Differentiates `type MyType` from `MyFunction`
@@ -111,152 +239,296 @@ var g = &grammar{
 	rules: []*rule{
 		{
 			name: "SourceFile",
-			pos:  position{line: 96, col: 1, offset: 2628},
+			pos:  position{line: 217, col: 1, offset: 5743},
 			expr: &actionExpr{
-				pos: position{line: 96, col: 15, offset: 2642},
+				pos: position{line: 217, col: 15, offset: 5757},
 				run: (*parser).callonSourceFile1,
 				expr: &seqExpr{
-					pos: position{line: 96, col: 15, offset: 2642},
+					pos: position{line: 217, col: 15, offset: 5757},
 					exprs: []any{
 						&ruleRefExpr{
-							pos:  position{line: 96, col: 15, offset: 2642},
+							pos:  position{line: 217, col: 15, offset: 5757},
 							name: "_",
 						},
 						&labeledExpr{
-							pos:   position{line: 96, col: 17, offset: 2644},
+							pos:   position{line: 217, col: 17, offset: 5759},
 							label: "first",
 							expr: &zeroOrOneExpr{
-								pos: position{line: 96, col: 23, offset: 2650},
-								expr: &choiceExpr{
-									pos: position{line: 96, col: 24, offset: 2651},
-									alternatives: []any{
-										&ruleRefExpr{
-											pos:  position{line: 96, col: 24, offset: 2651},
-											name: "Import",
-										},
-										&ruleRefExpr{
-											pos:  position{line: 96, col: 33, offset: 2660},
-											name: "IgnoredType",
-										},
-										&ruleRefExpr{
-											pos:  position{line: 96, col: 47, offset: 2674},
-											name: "Function",
-										},
-										&ruleRefExpr{
-											pos:  position{line: 96, col: 58, offset: 2685},
-											name: "IgnoredContent",
-										},
-									},
+								pos: position{line: 217, col: 23, offset: 5765},
+								expr: &ruleRefExpr{
+									pos:  position{line: 217, col: 23, offset: 5765},
+									name: "TopLevel",
 								},
 							},
 						},
 						&labeledExpr{
-							pos:   position{line: 96, col: 75, offset: 2702},
+							pos:   position{line: 217, col: 33, offset: 5775},
 							label: "rest",
 							expr: &zeroOrMoreExpr{
-								pos: position{line: 96, col: 80, offset: 2707},
+								pos: position{line: 217, col: 38, offset: 5780},
 								expr: &seqExpr{
-									pos: position{line: 96, col: 81, offset: 2708},
+									pos: position{line: 217, col: 39, offset: 5781},
 									exprs: []any{
 										&ruleRefExpr{
-											pos:  position{line: 96, col: 81, offset: 2708},
+											pos:  position{line: 217, col: 39, offset: 5781},
 											name: "_",
 										},
-										&choiceExpr{
-											pos: position{line: 96, col: 84, offset: 2711},
-											alternatives: []any{
-												&ruleRefExpr{
-													pos:  position{line: 96, col: 84, offset: 2711},
-													name: "Import",
-												},
-												&ruleRefExpr{
-													pos:  position{line: 96, col: 93, offset: 2720},
-													name: "IgnoredType",
-												},
-												&ruleRefExpr{
-													pos:  position{line: 96, col: 107, offset: 2734},
-													name: "Function",
-												},
-												&ruleRefExpr{
-													pos:  position{line: 96, col: 118, offset: 2745},
-													name: "IgnoredContent",
-												},
-											},
+										&ruleRefExpr{
+											pos:  position{line: 217, col: 41, offset: 5783},
+											name: "TopLevel",
 										},
 									},
 								},
 							},
 						},
 						&ruleRefExpr{
-							pos:  position{line: 96, col: 136, offset: 2763},
+							pos:  position{line: 217, col: 52, offset: 5794},
 							name: "_",
 						},
 						&ruleRefExpr{
-							pos:  position{line: 96, col: 138, offset: 2765},
+							pos:  position{line: 217, col: 54, offset: 5796},
 							name: "EOF",
 						},
 					},
 				},
 			},
 		},
+		{
+			name: "TopLevel",
+			pos:  position{line: 244, col: 1, offset: 6543},
+			expr: &recoveryExpr{
+				pos: position{line: 244, col: 13, offset: 6555},
+				expr: &choiceExpr{
+					pos: position{line: 244, col: 14, offset: 6556},
+					alternatives: []any{
+						&ruleRefExpr{
+							pos:  position{line: 244, col: 14, offset: 6556},
+							name: "Import",
+						},
+						&ruleRefExpr{
+							pos:  position{line: 244, col: 23, offset: 6565},
+							name: "TypeDefinition",
+						},
+						&ruleRefExpr{
+							pos:  position{line: 244, col: 40, offset: 6582},
+							name: "Constant",
+						},
+						&ruleRefExpr{
+							pos:  position{line: 244, col: 51, offset: 6593},
+							name: "Function",
+						},
+						&ruleRefExpr{
+							pos:  position{line: 244, col: 62, offset: 6604},
+							name: "IgnoredContent",
+						},
+						&throwExpr{
+							pos:   position{line: 244, col: 79, offset: 6621},
+							label: "statement",
+						},
+					},
+				},
+				recoverExpr: &ruleRefExpr{
+					pos:  position{line: 244, col: 107, offset: 6649},
+					name: "SkippedStatement",
+				},
+				failureLabel: []string{
+					"statement",
+				},
+			},
+		},
+		{
+			name: "SkippedStatement",
+			pos:  position{line: 246, col: 1, offset: 6667},
+			expr: &actionExpr{
+				pos: position{line: 246, col: 21, offset: 6687},
+				run: (*parser).callonSkippedStatement1,
+				expr: &seqExpr{
+					pos: position{line: 246, col: 21, offset: 6687},
+					exprs: []any{
+						&anyMatcher{
+							line: 246, col: 21, offset: 6687,
+						},
+						&zeroOrMoreExpr{
+							pos: position{line: 246, col: 23, offset: 6689},
+							expr: &seqExpr{
+								pos: position{line: 246, col: 24, offset: 6690},
+								exprs: []any{
+									&notExpr{
+										pos: position{line: 246, col: 24, offset: 6690},
+										expr: &seqExpr{
+											pos: position{line: 246, col: 26, offset: 6692},
+											exprs: []any{
+												&litMatcher{
+													pos:        position{line: 246, col: 26, offset: 6692},
+													val:        "\n",
+													ignoreCase: false,
+													want:       "\"\\n\"",
+												},
+												&ruleRefExpr{
+													pos:  position{line: 246, col: 31, offset: 6697},
+													name: "StatementStart",
+												},
+											},
+										},
+									},
+									&anyMatcher{
+										line: 246, col: 47, offset: 6713,
+									},
+								},
+							},
+						},
+					},
+				},
+			},
+		},
+		{
+			name: "StatementStart",
+			pos:  position{line: 252, col: 1, offset: 6962},
+			expr: &choiceExpr{
+				pos: position{line: 252, col: 19, offset: 6980},
+				alternatives: []any{
+					&seqExpr{
+						pos: position{line: 252, col: 19, offset: 6980},
+						exprs: []any{
+							&litMatcher{
+								pos:        position{line: 252, col: 19, offset: 6980},
+								val:        "import",
+								ignoreCase: false,
+								want:       "\"import\"",
+							},
+							&ruleRefExpr{
+								pos:  position{line: 252, col: 28, offset: 6989},
+								name: "__",
+							},
+						},
+					},
+					&seqExpr{
+						pos: position{line: 252, col: 33, offset: 6994},
+						exprs: []any{
+							&litMatcher{
+								pos:        position{line: 252, col: 33, offset: 6994},
+								val:        "pub",
+								ignoreCase: false,
+								want:       "\"pub\"",
+							},
+							&ruleRefExpr{
+								pos:  position{line: 252, col: 39, offset: 7000},
+								name: "__",
+							},
+						},
+					},
+					&seqExpr{
+						pos: position{line: 252, col: 44, offset: 7005},
+						exprs: []any{
+							&litMatcher{
+								pos:        position{line: 252, col: 44, offset: 7005},
+								val:        "fn",
+								ignoreCase: false,
+								want:       "\"fn\"",
+							},
+							&ruleRefExpr{
+								pos:  position{line: 252, col: 49, offset: 7010},
+								name: "__",
+							},
+						},
+					},
+					&seqExpr{
+						pos: position{line: 252, col: 54, offset: 7015},
+						exprs: []any{
+							&litMatcher{
+								pos:        position{line: 252, col: 54, offset: 7015},
+								val:        "type",
+								ignoreCase: false,
+								want:       "\"type\"",
+							},
+							&ruleRefExpr{
+								pos:  position{line: 252, col: 61, offset: 7022},
+								name: "__",
+							},
+						},
+					},
+					&seqExpr{
+						pos: position{line: 252, col: 66, offset: 7027},
+						exprs: []any{
+							&litMatcher{
+								pos:        position{line: 252, col: 66, offset: 7027},
+								val:        "const",
+								ignoreCase: false,
+								want:       "\"const\"",
+							},
+							&ruleRefExpr{
+								pos:  position{line: 252, col: 74, offset: 7035},
+								name: "__",
+							},
+						},
+					},
+					&litMatcher{
+						pos:        position{line: 252, col: 79, offset: 7040},
+						val:        "@",
+						ignoreCase: false,
+						want:       "\"@\"",
+					},
+				},
+			},
+		},
 		{
 			name: "Function",
-			pos:  position{line: 113, col: 1, offset: 3187},
+			pos:  position{line: 254, col: 1, offset: 7045},
 			expr: &actionExpr{
-				pos: position{line: 113, col: 13, offset: 3199},
+				pos: position{line: 254, col: 13, offset: 7057},
 				run: (*parser).callonFunction1,
 				expr: &seqExpr{
-					pos: position{line: 113, col: 13, offset: 3199},
+					pos: position{line: 254, col: 13, offset: 7057},
 					exprs: []any{
 						&labeledExpr{
-							pos:   position{line: 113, col: 13, offset: 3199},
+							pos:   position{line: 254, col: 13, offset: 7057},
 							label: "targetAttrs1",
 							expr: &zeroOrMoreExpr{
-								pos: position{line: 113, col: 26, offset: 3212},
+								pos: position{line: 254, col: 26, offset: 7070},
 								expr: &ruleRefExpr{
-									pos:  position{line: 113, col: 27, offset: 3213},
+									pos:  position{line: 254, col: 27, offset: 7071},
 									name: "TargetAttribute",
 								},
 							},
 						},
 						&labeledExpr{
-							pos:   position{line: 113, col: 45, offset: 3231},
+							pos:   position{line: 254, col: 45, offset: 7089},
 							label: "exattrs",
 							expr: &zeroOrMoreExpr{
-								pos: position{line: 113, col: 53, offset: 3239},
+								pos: position{line: 254, col: 53, offset: 7097},
 								expr: &ruleRefExpr{
-									pos:  position{line: 113, col: 54, offset: 3240},
+									pos:  position{line: 254, col: 54, offset: 7098},
 									name: "ExternalAttribute",
 								},
 							},
 						},
 						&labeledExpr{
-							pos:   position{line: 113, col: 74, offset: 3260},
+							pos:   position{line: 254, col: 74, offset: 7118},
 							label: "targetAttrs2",
 							expr: &zeroOrMoreExpr{
-								pos: position{line: 113, col: 87, offset: 3273},
+								pos: position{line: 254, col: 87, offset: 7131},
 								expr: &ruleRefExpr{
-									pos:  position{line: 113, col: 88, offset: 3274},
+									pos:  position{line: 254, col: 88, offset: 7132},
 									name: "TargetAttribute",
 								},
 							},
 						},
 						&labeledExpr{
-							pos:   position{line: 113, col: 106, offset: 3292},
+							pos:   position{line: 254, col: 106, offset: 7150},
 							label: "pub",
 							expr: &zeroOrOneExpr{
-								pos: position{line: 113, col: 110, offset: 3296},
+								pos: position{line: 254, col: 110, offset: 7154},
 								expr: &seqExpr{
-									pos: position{line: 113, col: 111, offset: 3297},
+									pos: position{line: 254, col: 111, offset: 7155},
 									exprs: []any{
 										&litMatcher{
-											pos:        position{line: 113, col: 111, offset: 3297},
+											pos:        position{line: 254, col: 111, offset: 7155},
 											val:        "pub",
 											ignoreCase: false,
 											want:       "\"pub\"",
 										},
 										&ruleRefExpr{
-											pos:  position{line: 113, col: 117, offset: 3303},
+											pos:  position{line: 254, col: 117, offset: 7161},
 											name: "__",
 										},
 									},
@@ -264,76 +536,76 @@ var g = &grammar{
 							},
 						},
 						&litMatcher{
-							pos:        position{line: 113, col: 122, offset: 3308},
+							pos:        position{line: 254, col: 122, offset: 7166},
 							val:        "fn",
 							ignoreCase: false,
 							want:       "\"fn\"",
 						},
 						&ruleRefExpr{
-							pos:  position{line: 113, col: 127, offset: 3313},
+							pos:  position{line: 254, col: 127, offset: 7171},
 							name: "__",
 						},
 						&labeledExpr{
-							pos:   position{line: 113, col: 130, offset: 3316},
+							pos:   position{line: 254, col: 130, offset: 7174},
 							label: "name",
 							expr: &ruleRefExpr{
-								pos:  position{line: 113, col: 135, offset: 3321},
+								pos:  position{line: 254, col: 135, offset: 7179},
 								name: "Name",
 							},
 						},
 						&ruleRefExpr{
-							pos:  position{line: 113, col: 140, offset: 3326},
+							pos:  position{line: 254, col: 140, offset: 7184},
 							name: "_",
 						},
 						&labeledExpr{
-							pos:   position{line: 113, col: 142, offset: 3328},
+							pos:   position{line: 254, col: 142, offset: 7186},
 							label: "params",
 							expr: &ruleRefExpr{
-								pos:  position{line: 113, col: 149, offset: 3335},
+								pos:  position{line: 254, col: 149, offset: 7193},
 								name: "FunctionParameters",
 							},
 						},
 						&labeledExpr{
-							pos:   position{line: 113, col: 168, offset: 3354},
+							pos:   position{line: 254, col: 168, offset: 7212},
 							label: "returnGroup",
 							expr: &zeroOrOneExpr{
-								pos: position{line: 113, col: 180, offset: 3366},
+								pos: position{line: 254, col: 180, offset: 7224},
 								expr: &seqExpr{
-									pos: position{line: 113, col: 181, offset: 3367},
+									pos: position{line: 254, col: 181, offset: 7225},
 									exprs: []any{
 										&ruleRefExpr{
-											pos:  position{line: 113, col: 181, offset: 3367},
+											pos:  position{line: 254, col: 181, offset: 7225},
 											name: "_",
 										},
 										&litMatcher{
-											pos:        position{line: 113, col: 183, offset: 3369},
+											pos:        position{line: 254, col: 183, offset: 7227},
 											val:        "->",
 											ignoreCase: false,
 											want:       "\"->\"",
 										},
 										&ruleRefExpr{
-											pos:  position{line: 113, col: 188, offset: 3374},
+											pos:  position{line: 254, col: 188, offset: 7232},
 											name: "_",
 										},
 										&ruleRefExpr{
-											pos:  position{line: 113, col: 190, offset: 3376},
-											name: "Type",
+											pos:  position{line: 254, col: 190, offset: 7234},
+											name: "TypeAnnotationContent",
 										},
 									},
 								},
 							},
 						},
 						&ruleRefExpr{
-							pos:  position{line: 113, col: 197, offset: 3383},
+							pos:  position{line: 254, col: 214, offset: 7258},
 							name: "_",
 						},
 						&labeledExpr{
-							pos:   position{line: 113, col: 199, offset: 3385},
+							pos:   position{line: 254, col: 216, offset: 7260},
 							label: "body",
 							expr: &zeroOrOneExpr{
-								pos: position{line: 113, col: 204, offset: 3390},
+								pos: position{line: 254, col: 221, offset: 7265},
 								expr: &ruleRefExpr{
-									pos:  position{line: 113, col: 204, offset: 3390},
+									pos:  position{line: 254, col: 221, offset: 7265},
 									name: "IgnoredBlock",
 								},
 							},
@@ -344,63 +616,63 @@ var g = &grammar{
 		},
 		{
 			name: "ExternalAttribute",
-			pos:  position{line: 125, col: 1, offset: 3813},
+			pos:  position{line: 266, col: 1, offset: 7704},
 			expr: &actionExpr{
-				pos: position{line: 125, col: 22, offset: 3834},
+				pos: position{line: 266, col: 22, offset: 7725},
 				run: (*parser).callonExternalAttribute1,
 				expr: &seqExpr{
-					pos: position{line: 125, col: 22, offset: 3834},
+					pos: position{line: 266, col: 22, offset: 7725},
 					exprs: []any{
 						&litMatcher{
-							pos:        position{line: 125, col: 22, offset: 3834},
+							pos:        position{line: 266, col: 22, offset: 7725},
 							val:        "@",
 							ignoreCase: false,
 							want:       "\"@\"",
 						},
 						&ruleRefExpr{
-							pos:  position{line: 125, col: 26, offset: 3838},
+							pos:  position{line: 266, col: 26, offset: 7729},
 							name: "_",
 						},
 						&litMatcher{
-							pos:        position{line: 125, col: 28, offset: 3840},
+							pos:        position{line: 266, col: 28, offset: 7731},
 							val:        "external",
 							ignoreCase: false,
 							want:       "\"external\"",
 						},
 						&ruleRefExpr{
-							pos:  position{line: 125, col: 39, offset: 3851},
+							pos:  position{line: 266, col: 39, offset: 7742},
 							name: "_",
 						},
 						&litMatcher{
-							pos:        position{line: 125, col: 41, offset: 3853},
+							pos:        position{line: 266, col: 41, offset: 7744},
 							val:        "(",
 							ignoreCase: false,
 							want:       "\"(\"",
 						},
 						&ruleRefExpr{
-							pos:  position{line: 125, col: 45, offset: 3857},
+							pos:  position{line: 266, col: 45, offset: 7748},
 							name: "_",
 						},
 						&labeledExpr{
-							pos:   position{line: 125, col: 47, offset: 3859},
+							pos:   position{line: 266, col: 47, offset: 7750},
 							label: "args",
 							expr: &ruleRefExpr{
-								pos:  position{line: 125, col: 52, offset: 3864},
+								pos:  position{line: 266, col: 52, offset: 7755},
 								name: "ExternalArgs",
 							},
 						},
 						&ruleRefExpr{
-							pos:  position{line: 125, col: 65, offset: 3877},
+							pos:  position{line: 266, col: 65, offset: 7768},
 							name: "_",
 						},
 						&litMatcher{
-							pos:        position{line: 125, col: 67, offset: 3879},
+							pos:        position{line: 266, col: 67, offset: 7770},
 							val:        ")",
 							ignoreCase: false,
 							want:       "\")\"",
 						},
 						&ruleRefExpr{
-							pos:  position{line: 125, col: 71, offset: 3883},
+							pos:  position{line: 266, col: 71, offset: 7774},
 							name: "_",
 						},
 					},
@@ -409,27 +681,27 @@ var g = &grammar{
 		},
 		{
 			name: "ExternalArgs",
-			pos:  position{line: 130, col: 1, offset: 3979},
+			pos:  position{line: 273, col: 1, offset: 7926},
 			expr: &actionExpr{
-				pos: position{line: 130, col: 17, offset: 3995},
+				pos: position{line: 273, col: 17, offset: 7942},
 				run: (*parser).callonExternalArgs1,
 				expr: &seqExpr{
-					pos: position{line: 130, col: 17, offset: 3995},
+					pos: position{line: 273, col: 17, offset: 7942},
 					exprs: []any{
 						&labeledExpr{
-							pos:   position{line: 130, col: 17, offset: 3995},
+							pos:   position{line: 273, col: 17, offset: 7942},
 							label: "target",
 							expr: &choiceExpr{
-								pos: position{line: 130, col: 25, offset: 4003},
+								pos: position{line: 273, col: 25, offset: 7950},
 								alternatives: []any{
 									&litMatcher{
-										pos:        position{line: 130, col: 25, offset: 4003},
+										pos:        position{line: 273, col: 25, offset: 7950},
 										val:        "erlang",
 										ignoreCase: false,
 										want:       "\"erlang\"",
 									},
 									&litMatcher{
-										pos:        position{line: 130, col: 36, offset: 4014},
+										pos:        position{line: 273, col: 36, offset: 7961},
 										val:        "javascript",
 										ignoreCase: false,
 										want:       "\"javascript\"",
@@ -438,46 +710,46 @@ var g = &grammar{
 							},
 						},
 						&ruleRefExpr{
-							pos:  position{line: 130, col: 50, offset: 4028},
+							pos:  position{line: 273, col: 50, offset: 7975},
 							name: "_",
 						},
 						&litMatcher{
-							pos:        position{line: 130, col: 52, offset: 4030},
+							pos:        position{line: 273, col: 52, offset: 7977},
 							val:        ",",
 							ignoreCase: false,
 							want:       "\",\"",
 						},
 						&ruleRefExpr{
-							pos:  position{line: 130, col: 56, offset: 4034},
+							pos:  position{line: 273, col: 56, offset: 7981},
 							name: "_",
 						},
 						&labeledExpr{
-							pos:   position{line: 130, col: 58, offset: 4036},
+							pos:   position{line: 273, col: 58, offset: 7983},
 							label: "module",
 							expr: &ruleRefExpr{
-								pos:  position{line: 130, col: 65, offset: 4043},
+								pos:  position{line: 273, col: 65, offset: 7990},
 								name: "StringArg",
 							},
 						},
 						&ruleRefExpr{
-							pos:  position{line: 130, col: 75, offset: 4053},
+							pos:  position{line: 273, col: 75, offset: 8000},
 							name: "_",
 						},
 						&litMatcher{
-							pos:        position{line: 130, col: 77, offset: 4055},
+							pos:        position{line: 273, col: 77, offset: 8002},
 							val:        ",",
 							ignoreCase: false,
 							want:       "\",\"",
 						},
 						&ruleRefExpr{
-							pos:  position{line: 130, col: 81, offset: 4059},
+							pos:  position{line: 273, col: 81, offset: 8006},
 							name: "_",
 						},
 						&labeledExpr{
-							pos:   position{line: 130, col: 83, offset: 4061},
+							pos:   position{line: 273, col: 83, offset: 8008},
 							label: "function",
 							expr: &ruleRefExpr{
-								pos:  position{line: 130, col: 92, offset: 4070},
+								pos:  position{line: 273, col: 92, offset: 8017},
 								name: "StringArg",
 							},
 						},
@@ -487,23 +759,23 @@ var g = &grammar{
 		},
 		{
 			name: "StringArg",
-			pos:  position{line: 138, col: 1, offset: 4262},
+			pos:  position{line: 281, col: 1, offset: 8209},
 			expr: &actionExpr{
-				pos: position{line: 138, col: 14, offset: 4275},
+				pos: position{line: 281, col: 14, offset: 8222},
 				run: (*parser).callonStringArg1,
 				expr: &seqExpr{
-					pos: position{line: 138, col: 14, offset: 4275},
+					pos: position{line: 281, col: 14, offset: 8222},
 					exprs: []any{
 						&litMatcher{
-							pos:        position{line: 138, col: 14, offset: 4275},
+							pos:        position{line: 281, col: 14, offset: 8222},
 							val:        "\"",
 							ignoreCase: false,
 							want:       "\"\\\"\"",
 						},
 						&zeroOrMoreExpr{
-							pos: position{line: 138, col: 19, offset: 4280},
+							pos: position{line: 281, col: 19, offset: 8227},
 							expr: &charClassMatcher{
-								pos:        position{line: 138, col: 19, offset: 4280},
+								pos:        position{line: 281, col: 19, offset: 8227},
 								val:        "[^\"]",
 								chars:      []rune{'"'},
 								ignoreCase: false,
@@ -511,7 +783,7 @@ var g = &grammar{
 							},
 						},
 						&litMatcher{
-							pos:        position{line: 138, col: 25, offset: 4286},
+							pos:        position{line: 281, col: 25, offset: 8233},
 							val:        "\"",
 							ignoreCase: false,
 							want:       "\"\\\"\"",
@@ -522,58 +794,58 @@ var g = &grammar{
 		},
 		{
 			name: "FunctionParameters",
-			pos:  position{line: 140, col: 1, offset: 4323},
+			pos:  position{line: 283, col: 1, offset: 8270},
 			expr: &actionExpr{
-				pos: position{line: 140, col: 23, offset: 4345},
+				pos: position{line: 283, col: 23, offset: 8292},
 				run: (*parser).callonFunctionParameters1,
 				expr: &seqExpr{
-					pos: position{line: 140, col: 23, offset: 4345},
+					pos: position{line: 283, col: 23, offset: 8292},
 					exprs: []any{
 						&litMatcher{
-							pos:        position{line: 140, col: 23, offset: 4345},
+							pos:        position{line: 283, col: 23, offset: 8292},
 							val:        "(",
 							ignoreCase: false,
 							want:       "\"(\"",
 						},
 						&ruleRefExpr{
-							pos:  position{line: 140, col: 27, offset: 4349},
+							pos:  position{line: 283, col: 27, offset: 8296},
 							name: "_",
 						},
 						&labeledExpr{
-							pos:   position{line: 140, col: 29, offset: 4351},
+							pos:   position{line: 283, col: 29, offset: 8298},
 							label: "first",
 							expr: &zeroOrOneExpr{
-								pos: position{line: 140, col: 35, offset: 4357},
+								pos: position{line: 283, col: 35, offset: 8304},
 								expr: &ruleRefExpr{
-									pos:  position{line: 140, col: 35, offset: 4357},
+									pos:  position{line: 283, col: 35, offset: 8304},
 									name: "FunctionParameter",
 								},
 							},
 						},
 						&labeledExpr{
-							pos:   position{line: 140, col: 54, offset: 4376},
+							pos:   position{line: 283, col: 54, offset: 8323},
 							label: "rest",
 							expr: &zeroOrMoreExpr{
-								pos: position{line: 140, col: 59, offset: 4381},
+								pos: position{line: 283, col: 59, offset: 8328},
 								expr: &seqExpr{
-									pos: position{line: 140, col: 60, offset: 4382},
+									pos: position{line: 283, col: 60, offset: 8329},
 									exprs: []any{
 										&ruleRefExpr{
-											pos:  position{line: 140, col: 60, offset: 4382},
+											pos:  position{line: 283, col: 60, offset: 8329},
 											name: "_",
 										},
 										&litMatcher{
-											pos:        position{line: 140, col: 62, offset: 4384},
+											pos:        position{line: 283, col: 62, offset: 8331},
 											val:        ",",
 											ignoreCase: false,
 											want:       "\",\"",
 										},
 										&ruleRefExpr{
-											pos:  position{line: 140, col: 66, offset: 4388},
+											pos:  position{line: 283, col: 66, offset: 8335},
 											name: "_",
 										},
 										&ruleRefExpr{
-											pos:  position{line: 140, col: 68, offset: 4390},
+											pos:  position{line: 283, col: 68, offset: 8337},
 											name: "FunctionParameter",
 										},
 									},
@@ -581,24 +853,24 @@ var g = &grammar{
 							},
 						},
 						&ruleRefExpr{
-							pos:  position{line: 140, col: 88, offset: 4410},
+							pos:  position{line: 283, col: 88, offset: 8357},
 							name: "_",
 						},
 						&zeroOrOneExpr{
-							pos: position{line: 140, col: 90, offset: 4412},
+							pos: position{line: 283, col: 90, offset: 8359},
 							expr: &litMatcher{
-								pos:        position{line: 140, col: 90, offset: 4412},
+								pos:        position{line: 283, col: 90, offset: 8359},
 								val:        ",",
 								ignoreCase: false,
 								want:       "\",\"",
 							},
 						},
 						&ruleRefExpr{
-							pos:  position{line: 140, col: 95, offset: 4417},
+							pos:  position{line: 283, col: 95, offset: 8364},
 							name: "_",
 						},
 						&litMatcher{
-							pos:        position{line: 140, col: 97, offset: 4419},
+							pos:        position{line: 283, col: 97, offset: 8366},
 							val:        ")",
 							ignoreCase: false,
 							want:       "\")\"",
@@ -609,68 +881,68 @@ var g = &grammar{
 		},
 		{
 			name: "LambdaFunction",
-			pos:  position{line: 156, col: 1, offset: 4846},
+			pos:  position{line: 299, col: 1, offset: 8793},
 			expr: &actionExpr{
-				pos: position{line: 156, col: 19, offset: 4864},
+				pos: position{line: 299, col: 19, offset: 8811},
 				run: (*parser).callonLambdaFunction1,
 				expr: &seqExpr{
-					pos: position{line: 156, col: 19, offset: 4864},
+					pos: position{line: 299, col: 19, offset: 8811},
 					exprs: []any{
 						&litMatcher{
-							pos:        position{line: 156, col: 19, offset: 4864},
+							pos:        position{line: 299, col: 19, offset: 8811},
 							val:        "fn",
 							ignoreCase: false,
 							want:       "\"fn\"",
 						},
 						&ruleRefExpr{
-							pos:  position{line: 156, col: 24, offset: 4869},
+							pos:  position{line: 299, col: 24, offset: 8816},
 							name: "_",
 						},
 						&litMatcher{
-							pos:        position{line: 156, col: 26, offset: 4871},
+							pos:        position{line: 299, col: 26, offset: 8818},
 							val:        "(",
 							ignoreCase: false,
 							want:       "\"(\"",
 						},
 						&ruleRefExpr{
-							pos:  position{line: 156, col: 30, offset: 4875},
+							pos:  position{line: 299, col: 30, offset: 8822},
 							name: "_",
 						},
 						&labeledExpr{
-							pos:   position{line: 156, col: 32, offset: 4877},
+							pos:   position{line: 299, col: 32, offset: 8824},
 							label: "first",
 							expr: &zeroOrOneExpr{
-								pos: position{line: 156, col: 38, offset: 4883},
+								pos: position{line: 299, col: 38, offset: 8830},
 								expr: &ruleRefExpr{
-									pos:  position{line: 156, col: 38, offset: 4883},
+									pos:  position{line: 299, col: 38, offset: 8830},
 									name: "LambdaFunctionParameter",
 								},
 							},
 						},
 						&labeledExpr{
-							pos:   position{line: 156, col: 63, offset: 4908},
+							pos:   position{line: 299, col: 63, offset: 8855},
 							label: "rest",
 							expr: &zeroOrMoreExpr{
-								pos: position{line: 156, col: 68, offset: 4913},
+								pos: position{line: 299, col: 68, offset: 8860},
 								expr: &seqExpr{
-									pos: position{line: 156, col: 69, offset: 4914},
+									pos: position{line: 299, col: 69, offset: 8861},
 									exprs: []any{
 										&ruleRefExpr{
-											pos:  position{line: 156, col: 69, offset: 4914},
+											pos:  position{line: 299, col: 69, offset: 8861},
 											name: "_",
 										},
 										&litMatcher{
-											pos:        position{line: 156, col: 71, offset: 4916},
+											pos:        position{line: 299, col: 71, offset: 8863},
 											val:        ",",
 											ignoreCase: false,
 											want:       "\",\"",
 										},
 										&ruleRefExpr{
-											pos:  position{line: 156, col: 75, offset: 4920},
+											pos:  position{line: 299, col: 75, offset: 8867},
 											name: "_",
 										},
 										&ruleRefExpr{
-											pos:  position{line: 156, col: 77, offset: 4922},
+											pos:  position{line: 299, col: 77, offset: 8869},
 											name: "LambdaFunctionParameter",
 										},
 									},
@@ -678,52 +950,52 @@ var g = &grammar{
 							},
 						},
 						&ruleRefExpr{
-							pos:  position{line: 156, col: 104, offset: 4949},
+							pos:  position{line: 299, col: 104, offset: 8896},
 							name: "_",
 						},
 						&zeroOrOneExpr{
-							pos: position{line: 156, col: 106, offset: 4951},
+							pos: position{line: 299, col: 106, offset: 8898},
 							expr: &litMatcher{
-								pos:        position{line: 156, col: 106, offset: 4951},
+								pos:        position{line: 299, col: 106, offset: 8898},
 								val:        ",",
 								ignoreCase: false,
 								want:       "\",\"",
 							},
 						},
 						&ruleRefExpr{
-							pos:  position{line: 156, col: 111, offset: 4956},
+							pos:  position{line: 299, col: 111, offset: 8903},
 							name: "_",
 						},
 						&litMatcher{
-							pos:        position{line: 156, col: 113, offset: 4958},
+							pos:        position{line: 299, col: 113, offset: 8905},
 							val:        ")",
 							ignoreCase: false,
 							want:       "\")\"",
 						},
 						&labeledExpr{
-							pos:   position{line: 156, col: 117, offset: 4962},
+							pos:   position{line: 299, col: 117, offset: 8909},
 							label: "returnGroup",
 							expr: &zeroOrOneExpr{
-								pos: position{line: 156, col: 129, offset: 4974},
+								pos: position{line: 299, col: 129, offset: 8921},
 								expr: &seqExpr{
-									pos: position{line: 156, col: 130, offset: 4975},
+									pos: position{line: 299, col: 130, offset: 8922},
 									exprs: []any{
 										&ruleRefExpr{
-											pos:  position{line: 156, col: 130, offset: 4975},
+											pos:  position{line: 299, col: 130, offset: 8922},
 											name: "_",
 										},
 										&litMatcher{
-											pos:        position{line: 156, col: 132, offset: 4977},
+											pos:        position{line: 299, col: 132, offset: 8924},
 											val:        "->",
 											ignoreCase: false,
 											want:       "\"->\"",
 										},
 										&ruleRefExpr{
-											pos:  position{line: 156, col: 137, offset: 4982},
+											pos:  position{line: 299, col: 137, offset: 8929},
 											name: "_",
 										},
 										&ruleRefExpr{
-											pos:  position{line: 156, col: 139, offset: 4984},
+											pos:  position{line: 299, col: 139, offset: 8931},
 											name: "LambdaFunctionParameter",
 										},
 									},
@@ -736,26 +1008,26 @@ var g = &grammar{
 		},
 		{
 			name: "LambdaFunctionParameter",
-			pos:  position{line: 159, col: 1, offset: 5034},
+			pos:  position{line: 308, col: 1, offset: 9264},
 			expr: &actionExpr{
-				pos: position{line: 159, col: 28, offset: 5061},
+				pos: position{line: 308, col: 28, offset: 9291},
 				run: (*parser).callonLambdaFunctionParameter1,
 				expr: &labeledExpr{
-					pos:   position{line: 159, col: 28, offset: 5061},
+					pos:   position{line: 308, col: 28, offset: 9291},
 					label: "t",
 					expr: &choiceExpr{
-						pos: position{line: 159, col: 31, offset: 5064},
+						pos: position{line: 308, col: 31, offset: 9294},
 						alternatives: []any{
 							&ruleRefExpr{
-								pos:  position{line: 159, col: 31, offset: 5064},
+								pos:  position{line: 308, col: 31, offset: 9294},
 								name: "LambdaFunction",
 							},
 							&ruleRefExpr{
-								pos:  position{line: 159, col: 48, offset: 5081},
+								pos:  position{line: 308, col: 48, offset: 9311},
 								name: "Type",
 							},
 							&ruleRefExpr{
-								pos:  position{line: 159, col: 55, offset: 5088},
+								pos:  position{line: 308, col: 55, offset: 9318},
 								name: "TupleType",
 							},
 						},
@@ -765,41 +1037,41 @@ var g = &grammar{
 		},
 		{
 			name: "FunctionParameter",
-			pos:  position{line: 161, col: 1, offset: 5118},
+			pos:  position{line: 310, col: 1, offset: 9348},
 			expr: &actionExpr{
-				pos: position{line: 161, col: 22, offset: 5139},
+				pos: position{line: 310, col: 22, offset: 9369},
 				run: (*parser).callonFunctionParameter1,
 				expr: &seqExpr{
-					pos: position{line: 161, col: 22, offset: 5139},
+					pos: position{line: 310, col: 22, offset: 9369},
 					exprs: []any{
 						&labeledExpr{
-							pos:   position{line: 161, col: 22, offset: 5139},
+							pos:   position{line: 310, col: 22, offset: 9369},
 							label: "p",
 							expr: &choiceExpr{
-								pos: position{line: 161, col: 25, offset: 5142},
+								pos: position{line: 310, col: 25, offset: 9372},
 								alternatives: []any{
 									&ruleRefExpr{
-										pos:  position{line: 161, col: 25, offset: 5142},
+										pos:  position{line: 310, col: 25, offset: 9372},
 										name: "LabeledNameParam",
 									},
 									&ruleRefExpr{
-										pos:  position{line: 161, col: 44, offset: 5161},
+										pos:  position{line: 310, col: 44, offset: 9391},
 										name: "NameParam",
 									},
 									&ruleRefExpr{
-										pos:  position{line: 161, col: 56, offset: 5173},
+										pos:  position{line: 310, col: 56, offset: 9403},
 										name: "DiscardParam",
 									},
 								},
 							},
 						},
 						&labeledExpr{
-							pos:   position{line: 161, col: 70, offset: 5187},
+							pos:   position{line: 310, col: 70, offset: 9417},
 							label: "typ",
 							expr: &zeroOrOneExpr{
-								pos: position{line: 161, col: 74, offset: 5191},
+								pos: position{line: 310, col: 74, offset: 9421},
 								expr: &ruleRefExpr{
-									pos:  position{line: 161, col: 74, offset: 5191},
+									pos:  position{line: 310, col: 74, offset: 9421},
 									name: "TypeAnnotation",
 								},
 							},
@@ -810,28 +1082,28 @@ var g = &grammar{
 		},
 		{
 			name: "TypeAnnotation",
-			pos:  position{line: 169, col: 1, offset: 5344},
+			pos:  position{line: 319, col: 1, offset: 9597},
 			expr: &actionExpr{
-				pos: position{line: 169, col: 19, offset: 5362},
+				pos: position{line: 319, col: 19, offset: 9615},
 				run: (*parser).callonTypeAnnotation1,
 				expr: &seqExpr{
-					pos: position{line: 169, col: 19, offset: 5362},
+					pos: position{line: 319, col: 19, offset: 9615},
 					exprs: []any{
 						&litMatcher{
-							pos:        position{line: 169, col: 19, offset: 5362},
+							pos:        position{line: 319, col: 19, offset: 9615},
 							val:        ":",
 							ignoreCase: false,
 							want:       "\":\"",
 						},
 						&ruleRefExpr{
-							pos:  position{line: 169, col: 23, offset: 5366},
+							pos:  position{line: 319, col: 23, offset: 9619},
 							name: "_",
 						},
 						&labeledExpr{
-							pos:   position{line: 169, col: 25, offset: 5368},
+							pos:   position{line: 319, col: 25, offset: 9621},
 							label: "t",
 							expr: &ruleRefExpr{
-								pos:  position{line: 169, col: 28, offset: 5371},
+								pos:  position{line: 319, col: 28, offset: 9624},
 								name: "TypeAnnotationContent",
 							},
 						},
@@ -841,26 +1113,26 @@ var g = &grammar{
 		},
 		{
 			name: "TypeAnnotationContent",
-			pos:  position{line: 170, col: 1, offset: 5412},
+			pos:  position{line: 320, col: 1, offset: 9665},
 			expr: &actionExpr{
-				pos: position{line: 170, col: 26, offset: 5437},
+				pos: position{line: 320, col: 26, offset: 9690},
 				run: (*parser).callonTypeAnnotationContent1,
 				expr: &labeledExpr{
-					pos:   position{line: 170, col: 26, offset: 5437},
+					pos:   position{line: 320, col: 26, offset: 9690},
 					label: "t",
 					expr: &choiceExpr{
-						pos: position{line: 170, col: 29, offset: 5440},
+						pos: position{line: 320, col: 29, offset: 9693},
 						alternatives: []any{
 							&ruleRefExpr{
-								pos:  position{line: 170, col: 29, offset: 5440},
+								pos:  position{line: 320, col: 29, offset: 9693},
 								name: "LambdaFunction",
 							},
 							&ruleRefExpr{
-								pos:  position{line: 170, col: 46, offset: 5457},
+								pos:  position{line: 320, col: 46, offset: 9710},
 								name: "TupleType",
 							},
 							&ruleRefExpr{
-								pos:  position{line: 170, col: 58, offset: 5469},
+								pos:  position{line: 320, col: 58, offset: 9722},
 								name: "Type",
 							},
 						},
@@ -870,59 +1142,59 @@ var g = &grammar{
 		},
 		{
 			name: "TupleType",
-			pos:  position{line: 171, col: 1, offset: 5492},
+			pos:  position{line: 321, col: 1, offset: 9745},
 			expr: &actionExpr{
-				pos: position{line: 171, col: 14, offset: 5505},
+				pos: position{line: 321, col: 14, offset: 9758},
 				run: (*parser).callonTupleType1,
 				expr: &seqExpr{
-					pos: position{line: 171, col: 14, offset: 5505},
+					pos: position{line: 321, col: 14, offset: 9758},
 					exprs: []any{
 						&litMatcher{
-							pos:        position{line: 171, col: 14, offset: 5505},
+							pos:        position{line: 321, col: 14, offset: 9758},
 							val:        "#(",
 							ignoreCase: false,
 							want:       "\"#(\"",
 						},
 						&ruleRefExpr{
-							pos:  position{line: 171, col: 19, offset: 5510},
+							pos:  position{line: 321, col: 19, offset: 9763},
 							name: "_",
 						},
 						&labeledExpr{
-							pos:   position{line: 171, col: 21, offset: 5512},
+							pos:   position{line: 321, col: 21, offset: 9765},
 							label: "first",
 							expr: &ruleRefExpr{
-								pos:  position{line: 171, col: 28, offset: 5519},
+								pos:  position{line: 321, col: 28, offset: 9772},
 								name: "TypeAnnotationContent",
 							},
 						},
 						&labeledExpr{
-							pos:   position{line: 171, col: 51, offset: 5542},
+							pos:   position{line: 321, col: 51, offset: 9795},
 							label: "rest",
 							expr: &zeroOrMoreExpr{
-								pos: position{line: 171, col: 56, offset: 5547},
+								pos: position{line: 321, col: 56, offset: 9800},
 								expr: &seqExpr{
-									pos: position{line: 171, col: 57, offset: 5548},
+									pos: position{line: 321, col: 57, offset: 9801},
 									exprs: []any{
 										&ruleRefExpr{
-											pos:  position{line: 171, col: 57, offset: 5548},
+											pos:  position{line: 321, col: 57, offset: 9801},
 											name: "_",
 										},
 										&litMatcher{
-											pos:        position{line: 171, col: 59, offset: 5550},
+											pos:        position{line: 321, col: 59, offset: 9803},
 											val:        ",",
 											ignoreCase: false,
 											want:       "\",\"",
 										},
 										&ruleRefExpr{
-											pos:  position{line: 171, col: 63, offset: 5554},
+											pos:  position{line: 321, col: 63, offset: 9807},
 											name: "_",
 										},
 										&ruleRefExpr{
-											pos:  position{line: 171, col: 65, offset: 5556},
+											pos:  position{line: 321, col: 65, offset: 9809},
 											name: "TypeAnnotationContent",
 										},
 										&ruleRefExpr{
-											pos:  position{line: 171, col: 87, offset: 5578},
+											pos:  position{line: 321, col: 87, offset: 9831},
 											name: "_",
 										},
 									},
@@ -930,11 +1202,11 @@ var g = &grammar{
 							},
 						},
 						&ruleRefExpr{
-							pos:  position{line: 171, col: 91, offset: 5582},
+							pos:  position{line: 321, col: 91, offset: 9835},
 							name: "_",
 						},
 						&litMatcher{
-							pos:        position{line: 171, col: 93, offset: 5584},
+							pos:        position{line: 321, col: 93, offset: 9837},
 							val:        ")",
 							ignoreCase: false,
 							want:       "\")\"",
@@ -945,214 +1217,1067 @@ var g = &grammar{
 		},
 		{
 			name: "Type",
-			pos:  position{line: 172, col: 1, offset: 5608},
+			pos:  position{line: 328, col: 1, offset: 10042},
 			expr: &actionExpr{
-				pos: position{line: 172, col: 9, offset: 5616},
+				pos: position{line: 328, col: 9, offset: 10050},
 				run: (*parser).callonType1,
 				expr: &seqExpr{
-					pos: position{line: 172, col: 9, offset: 5616},
+					pos: position{line: 328, col: 9, offset: 10050},
 					exprs: []any{
 						&labeledExpr{
-							pos:   position{line: 172, col: 9, offset: 5616},
+							pos:   position{line: 328, col: 9, offset: 10050},
 							label: "mod",
 							expr: &zeroOrOneExpr{
-								pos: position{line: 172, col: 13, offset: 5620},
+								pos: position{line: 328, col: 13, offset: 10054},
 								expr: &seqExpr{
-									pos: position{line: 172, col: 14, offset: 5621},
+									pos: position{line: 328, col: 14, offset: 10055},
 									exprs: []any{
 										&ruleRefExpr{
-											pos:  position{line: 172, col: 14, offset: 5621},
+											pos:  position{line: 328, col: 14, offset: 10055},
 											name: "Name",
 										},
-										&litMatcher{
-											pos:        position{line: 172, col: 19, offset: 5626},
-											val:        ".",
+										&litMatcher{
+											pos:        position{line: 328, col: 19, offset: 10060},
+											val:        ".",
+											ignoreCase: false,
+											want:       "\".\"",
+										},
+									},
+								},
+							},
+						},
+						&labeledExpr{
+							pos:   position{line: 328, col: 25, offset: 10066},
+							label: "name",
+							expr: &choiceExpr{
+								pos: position{line: 328, col: 31, offset: 10072},
+								alternatives: []any{
+									&ruleRefExpr{
+										pos:  position{line: 328, col: 31, offset: 10072},
+										name: "Name",
+									},
+									&ruleRefExpr{
+										pos:  position{line: 328, col: 38, offset: 10079},
+										name: "UpName",
+									},
+								},
+							},
+						},
+						&labeledExpr{
+							pos:   position{line: 328, col: 46, offset: 10087},
+							label: "args",
+							expr: &zeroOrOneExpr{
+								pos: position{line: 328, col: 51, offset: 10092},
+								expr: &seqExpr{
+									pos: position{line: 328, col: 52, offset: 10093},
+									exprs: []any{
+										&ruleRefExpr{
+											pos:  position{line: 328, col: 52, offset: 10093},
+											name: "_",
+										},
+										&ruleRefExpr{
+											pos:  position{line: 328, col: 54, offset: 10095},
+											name: "GenericParams",
+										},
+									},
+								},
+							},
+						},
+					},
+				},
+			},
+		},
+		{
+			name: "TypeDefinition",
+			pos:  position{line: 334, col: 1, offset: 10303},
+			expr: &actionExpr{
+				pos: position{line: 334, col: 19, offset: 10321},
+				run: (*parser).callonTypeDefinition1,
+				expr: &seqExpr{
+					pos: position{line: 334, col: 19, offset: 10321},
+					exprs: []any{
+						&labeledExpr{
+							pos:   position{line: 334, col: 19, offset: 10321},
+							label: "target",
+							expr: &zeroOrOneExpr{
+								pos: position{line: 334, col: 26, offset: 10328},
+								expr: &ruleRefExpr{
+									pos:  position{line: 334, col: 26, offset: 10328},
+									name: "TargetAttribute",
+								},
+							},
+						},
+						&ruleRefExpr{
+							pos:  position{line: 334, col: 43, offset: 10345},
+							name: "_",
+						},
+						&labeledExpr{
+							pos:   position{line: 334, col: 45, offset: 10347},
+							label: "pub",
+							expr: &zeroOrOneExpr{
+								pos: position{line: 334, col: 49, offset: 10351},
+								expr: &seqExpr{
+									pos: position{line: 334, col: 50, offset: 10352},
+									exprs: []any{
+										&litMatcher{
+											pos:        position{line: 334, col: 50, offset: 10352},
+											val:        "pub",
+											ignoreCase: false,
+											want:       "\"pub\"",
+										},
+										&ruleRefExpr{
+											pos:  position{line: 334, col: 56, offset: 10358},
+											name: "__",
+										},
+									},
+								},
+							},
+						},
+						&labeledExpr{
+							pos:   position{line: 334, col: 61, offset: 10363},
+							label: "opaque",
+							expr: &zeroOrOneExpr{
+								pos: position{line: 334, col: 68, offset: 10370},
+								expr: &seqExpr{
+									pos: position{line: 334, col: 69, offset: 10371},
+									exprs: []any{
+										&litMatcher{
+											pos:        position{line: 334, col: 69, offset: 10371},
+											val:        "opaque",
+											ignoreCase: false,
+											want:       "\"opaque\"",
+										},
+										&ruleRefExpr{
+											pos:  position{line: 334, col: 78, offset: 10380},
+											name: "__",
+										},
+									},
+								},
+							},
+						},
+						&litMatcher{
+							pos:        position{line: 334, col: 83, offset: 10385},
+							val:        "type",
+							ignoreCase: false,
+							want:       "\"type\"",
+						},
+						&ruleRefExpr{
+							pos:  position{line: 334, col: 90, offset: 10392},
+							name: "__",
+						},
+						&labeledExpr{
+							pos:   position{line: 334, col: 93, offset: 10395},
+							label: "name",
+							expr: &ruleRefExpr{
+								pos:  position{line: 334, col: 98, offset: 10400},
+								name: "UpName",
+							},
+						},
+						&labeledExpr{
+							pos:   position{line: 334, col: 105, offset: 10407},
+							label: "params",
+							expr: &zeroOrOneExpr{
+								pos: position{line: 334, col: 112, offset: 10414},
+								expr: &seqExpr{
+									pos: position{line: 334, col: 113, offset: 10415},
+									exprs: []any{
+										&ruleRefExpr{
+											pos:  position{line: 334, col: 113, offset: 10415},
+											name: "_",
+										},
+										&ruleRefExpr{
+											pos:  position{line: 334, col: 115, offset: 10417},
+											name: "TypeParameters",
+										},
+									},
+								},
+							},
+						},
+						&labeledExpr{
+							pos:   position{line: 334, col: 132, offset: 10434},
+							label: "body",
+							expr: &zeroOrOneExpr{
+								pos: position{line: 334, col: 137, offset: 10439},
+								expr: &choiceExpr{
+									pos: position{line: 334, col: 138, offset: 10440},
+									alternatives: []any{
+										&seqExpr{
+											pos: position{line: 334, col: 138, offset: 10440},
+											exprs: []any{
+												&ruleRefExpr{
+													pos:  position{line: 334, col: 138, offset: 10440},
+													name: "_",
+												},
+												&ruleRefExpr{
+													pos:  position{line: 334, col: 140, offset: 10442},
+													name: "TypeBody",
+												},
+											},
+										},
+										&seqExpr{
+											pos: position{line: 334, col: 151, offset: 10453},
+											exprs: []any{
+												&ruleRefExpr{
+													pos:  position{line: 334, col: 151, offset: 10453},
+													name: "_",
+												},
+												&ruleRefExpr{
+													pos:  position{line: 334, col: 153, offset: 10455},
+													name: "TypeAlias",
+												},
+											},
+										},
+									},
+								},
+							},
+						},
+					},
+				},
+			},
+		},
+		{
+			name: "TypeParameters",
+			pos:  position{line: 353, col: 1, offset: 11017},
+			expr: &actionExpr{
+				pos: position{line: 353, col: 19, offset: 11035},
+				run: (*parser).callonTypeParameters1,
+				expr: &seqExpr{
+					pos: position{line: 353, col: 19, offset: 11035},
+					exprs: []any{
+						&litMatcher{
+							pos:        position{line: 353, col: 19, offset: 11035},
+							val:        "(",
+							ignoreCase: false,
+							want:       "\"(\"",
+						},
+						&ruleRefExpr{
+							pos:  position{line: 353, col: 23, offset: 11039},
+							name: "_",
+						},
+						&labeledExpr{
+							pos:   position{line: 353, col: 25, offset: 11041},
+							label: "first",
+							expr: &ruleRefExpr{
+								pos:  position{line: 353, col: 31, offset: 11047},
+								name: "Name",
+							},
+						},
+						&labeledExpr{
+							pos:   position{line: 353, col: 36, offset: 11052},
+							label: "rest",
+							expr: &zeroOrMoreExpr{
+								pos: position{line: 353, col: 41, offset: 11057},
+								expr: &seqExpr{
+									pos: position{line: 353, col: 42, offset: 11058},
+									exprs: []any{
+										&ruleRefExpr{
+											pos:  position{line: 353, col: 42, offset: 11058},
+											name: "_",
+										},
+										&litMatcher{
+											pos:        position{line: 353, col: 44, offset: 11060},
+											val:        ",",
+											ignoreCase: false,
+											want:       "\",\"",
+										},
+										&ruleRefExpr{
+											pos:  position{line: 353, col: 48, offset: 11064},
+											name: "_",
+										},
+										&ruleRefExpr{
+											pos:  position{line: 353, col: 50, offset: 11066},
+											name: "Name",
+										},
+									},
+								},
+							},
+						},
+						&ruleRefExpr{
+							pos:  position{line: 353, col: 57, offset: 11073},
+							name: "_",
+						},
+						&zeroOrOneExpr{
+							pos: position{line: 353, col: 59, offset: 11075},
+							expr: &litMatcher{
+								pos:        position{line: 353, col: 59, offset: 11075},
+								val:        ",",
+								ignoreCase: false,
+								want:       "\",\"",
+							},
+						},
+						&ruleRefExpr{
+							pos:  position{line: 353, col: 64, offset: 11080},
+							name: "_",
+						},
+						&litMatcher{
+							pos:        position{line: 353, col: 66, offset: 11082},
+							val:        ")",
+							ignoreCase: false,
+							want:       "\")\"",
+						},
+					},
+				},
+			},
+		},
+		{
+			name: "TypeBody",
+			pos:  position{line: 362, col: 1, offset: 11394},
+			expr: &choiceExpr{
+				pos: position{line: 362, col: 13, offset: 11406},
+				alternatives: []any{
+					&actionExpr{
+						pos: position{line: 362, col: 13, offset: 11406},
+						run: (*parser).callonTypeBody2,
+						expr: &seqExpr{
+							pos: position{line: 362, col: 13, offset: 11406},
+							exprs: []any{
+								&litMatcher{
+									pos:        position{line: 362, col: 13, offset: 11406},
+									val:        "{",
+									ignoreCase: false,
+									want:       "\"{\"",
+								},
+								&ruleRefExpr{
+									pos:  position{line: 362, col: 17, offset: 11410},
+									name: "_",
+								},
+								&labeledExpr{
+									pos:   position{line: 362, col: 19, offset: 11412},
+									label: "ctors",
+									expr: &zeroOrMoreExpr{
+										pos: position{line: 362, col: 25, offset: 11418},
+										expr: &seqExpr{
+											pos: position{line: 362, col: 26, offset: 11419},
+											exprs: []any{
+												&ruleRefExpr{
+													pos:  position{line: 362, col: 26, offset: 11419},
+													name: "TypeConstructor",
+												},
+												&ruleRefExpr{
+													pos:  position{line: 362, col: 42, offset: 11435},
+													name: "_",
+												},
+											},
+										},
+									},
+								},
+								&litMatcher{
+									pos:        position{line: 362, col: 46, offset: 11439},
+									val:        "}",
+									ignoreCase: false,
+									want:       "\"}\"",
+								},
+							},
+						},
+					},
+					&actionExpr{
+						pos: position{line: 368, col: 5, offset: 11644},
+						run: (*parser).callonTypeBody12,
+						expr: &ruleRefExpr{
+							pos:  position{line: 368, col: 5, offset: 11644},
+							name: "IgnoredBlock",
+						},
+					},
+				},
+			},
+		},
+		{
+			name: "TypeAlias",
+			pos:  position{line: 371, col: 1, offset: 11697},
+			expr: &actionExpr{
+				pos: position{line: 371, col: 14, offset: 11710},
+				run: (*parser).callonTypeAlias1,
+				expr: &seqExpr{
+					pos: position{line: 371, col: 14, offset: 11710},
+					exprs: []any{
+						&litMatcher{
+							pos:        position{line: 371, col: 14, offset: 11710},
+							val:        "=",
+							ignoreCase: false,
+							want:       "\"=\"",
+						},
+						&ruleRefExpr{
+							pos:  position{line: 371, col: 18, offset: 11714},
+							name: "_",
+						},
+						&labeledExpr{
+							pos:   position{line: 371, col: 20, offset: 11716},
+							label: "aliased",
+							expr: &ruleRefExpr{
+								pos:  position{line: 371, col: 28, offset: 11724},
+								name: "TypeAnnotationContent",
+							},
+						},
+					},
+				},
+			},
+		},
+		{
+			name: "TypeConstructor",
+			pos:  position{line: 374, col: 1, offset: 11794},
+			expr: &actionExpr{
+				pos: position{line: 374, col: 20, offset: 11813},
+				run: (*parser).callonTypeConstructor1,
+				expr: &seqExpr{
+					pos: position{line: 374, col: 20, offset: 11813},
+					exprs: []any{
+						&zeroOrMoreExpr{
+							pos: position{line: 374, col: 20, offset: 11813},
+							expr: &seqExpr{
+								pos: position{line: 374, col: 21, offset: 11814},
+								exprs: []any{
+									&ruleRefExpr{
+										pos:  position{line: 374, col: 21, offset: 11814},
+										name: "ConstructorAttribute",
+									},
+									&ruleRefExpr{
+										pos:  position{line: 374, col: 42, offset: 11835},
+										name: "_",
+									},
+								},
+							},
+						},
+						&labeledExpr{
+							pos:   position{line: 374, col: 46, offset: 11839},
+							label: "name",
+							expr: &ruleRefExpr{
+								pos:  position{line: 374, col: 51, offset: 11844},
+								name: "UpName",
+							},
+						},
+						&labeledExpr{
+							pos:   position{line: 374, col: 58, offset: 11851},
+							label: "fields",
+							expr: &zeroOrOneExpr{
+								pos: position{line: 374, col: 65, offset: 11858},
+								expr: &seqExpr{
+									pos: position{line: 374, col: 66, offset: 11859},
+									exprs: []any{
+										&ruleRefExpr{
+											pos:  position{line: 374, col: 66, offset: 11859},
+											name: "_",
+										},
+										&litMatcher{
+											pos:        position{line: 374, col: 68, offset: 11861},
+											val:        "(",
+											ignoreCase: false,
+											want:       "\"(\"",
+										},
+										&ruleRefExpr{
+											pos:  position{line: 374, col: 72, offset: 11865},
+											name: "_",
+										},
+										&zeroOrOneExpr{
+											pos: position{line: 374, col: 74, offset: 11867},
+											expr: &ruleRefExpr{
+												pos:  position{line: 374, col: 74, offset: 11867},
+												name: "TypeFields",
+											},
+										},
+										&ruleRefExpr{
+											pos:  position{line: 374, col: 86, offset: 11879},
+											name: "_",
+										},
+										&litMatcher{
+											pos:        position{line: 374, col: 88, offset: 11881},
+											val:        ")",
+											ignoreCase: false,
+											want:       "\")\"",
+										},
+									},
+								},
+							},
+						},
+					},
+				},
+			},
+		},
+		{
+			name: "ConstructorAttribute",
+			pos:  position{line: 383, col: 1, offset: 12104},
+			expr: &seqExpr{
+				pos: position{line: 383, col: 25, offset: 12128},
+				exprs: []any{
+					&litMatcher{
+						pos:        position{line: 383, col: 25, offset: 12128},
+						val:        "@",
+						ignoreCase: false,
+						want:       "\"@\"",
+					},
+					&ruleRefExpr{
+						pos:  position{line: 383, col: 29, offset: 12132},
+						name: "Name",
+					},
+					&zeroOrOneExpr{
+						pos: position{line: 383, col: 34, offset: 12137},
+						expr: &seqExpr{
+							pos: position{line: 383, col: 35, offset: 12138},
+							exprs: []any{
+								&ruleRefExpr{
+									pos:  position{line: 383, col: 35, offset: 12138},
+									name: "_",
+								},
+								&litMatcher{
+									pos:        position{line: 383, col: 37, offset: 12140},
+									val:        "(",
+									ignoreCase: false,
+									want:       "\"(\"",
+								},
+								&zeroOrMoreExpr{
+									pos: position{line: 383, col: 41, offset: 12144},
+									expr: &seqExpr{
+										pos: position{line: 383, col: 42, offset: 12145},
+										exprs: []any{
+											&notExpr{
+												pos: position{line: 383, col: 42, offset: 12145},
+												expr: &litMatcher{
+													pos:        position{line: 383, col: 43, offset: 12146},
+													val:        ")",
+													ignoreCase: false,
+													want:       "\")\"",
+												},
+											},
+											&anyMatcher{
+												line: 383, col: 47, offset: 12150,
+											},
+										},
+									},
+								},
+								&litMatcher{
+									pos:        position{line: 383, col: 51, offset: 12154},
+									val:        ")",
+									ignoreCase: false,
+									want:       "\")\"",
+								},
+							},
+						},
+					},
+				},
+			},
+		},
+		{
+			name: "TypeFields",
+			pos:  position{line: 384, col: 1, offset: 12160},
+			expr: &actionExpr{
+				pos: position{line: 384, col: 15, offset: 12174},
+				run: (*parser).callonTypeFields1,
+				expr: &seqExpr{
+					pos: position{line: 384, col: 15, offset: 12174},
+					exprs: []any{
+						&labeledExpr{
+							pos:   position{line: 384, col: 15, offset: 12174},
+							label: "first",
+							expr: &ruleRefExpr{
+								pos:  position{line: 384, col: 21, offset: 12180},
+								name: "TypeField",
+							},
+						},
+						&labeledExpr{
+							pos:   position{line: 384, col: 31, offset: 12190},
+							label: "rest",
+							expr: &zeroOrMoreExpr{
+								pos: position{line: 384, col: 36, offset: 12195},
+								expr: &seqExpr{
+									pos: position{line: 384, col: 37, offset: 12196},
+									exprs: []any{
+										&ruleRefExpr{
+											pos:  position{line: 384, col: 37, offset: 12196},
+											name: "_",
+										},
+										&litMatcher{
+											pos:        position{line: 384, col: 39, offset: 12198},
+											val:        ",",
+											ignoreCase: false,
+											want:       "\",\"",
+										},
+										&ruleRefExpr{
+											pos:  position{line: 384, col: 43, offset: 12202},
+											name: "_",
+										},
+										&ruleRefExpr{
+											pos:  position{line: 384, col: 45, offset: 12204},
+											name: "TypeField",
+										},
+									},
+								},
+							},
+						},
+						&ruleRefExpr{
+							pos:  position{line: 384, col: 57, offset: 12216},
+							name: "_",
+						},
+						&zeroOrOneExpr{
+							pos: position{line: 384, col: 59, offset: 12218},
+							expr: &litMatcher{
+								pos:        position{line: 384, col: 59, offset: 12218},
+								val:        ",",
+								ignoreCase: false,
+								want:       "\",\"",
+							},
+						},
+					},
+				},
+			},
+		},
+		{
+			name: "TypeField",
+			pos:  position{line: 391, col: 1, offset: 12404},
+			expr: &actionExpr{
+				pos: position{line: 391, col: 14, offset: 12417},
+				run: (*parser).callonTypeField1,
+				expr: &seqExpr{
+					pos: position{line: 391, col: 14, offset: 12417},
+					exprs: []any{
+						&labeledExpr{
+							pos:   position{line: 391, col: 14, offset: 12417},
+							label: "label",
+							expr: &zeroOrOneExpr{
+								pos: position{line: 391, col: 20, offset: 12423},
+								expr: &seqExpr{
+									pos: position{line: 391, col: 21, offset: 12424},
+									exprs: []any{
+										&ruleRefExpr{
+											pos:  position{line: 391, col: 21, offset: 12424},
+											name: "Label",
+										},
+										&ruleRefExpr{
+											pos:  position{line: 391, col: 27, offset: 12430},
+											name: "_",
+										},
+										&litMatcher{
+											pos:        position{line: 391, col: 29, offset: 12432},
+											val:        ":",
+											ignoreCase: false,
+											want:       "\":\"",
+										},
+										&ruleRefExpr{
+											pos:  position{line: 391, col: 33, offset: 12436},
+											name: "_",
+										},
+									},
+								},
+							},
+						},
+						&labeledExpr{
+							pos:   position{line: 391, col: 37, offset: 12440},
+							label: "typ",
+							expr: &ruleRefExpr{
+								pos:  position{line: 391, col: 41, offset: 12444},
+								name: "TypeAnnotationContent",
+							},
+						},
+					},
+				},
+			},
+		},
+		{
+			name: "Constant",
+			pos:  position{line: 397, col: 1, offset: 12607},
+			expr: &actionExpr{
+				pos: position{line: 397, col: 13, offset: 12619},
+				run: (*parser).callonConstant1,
+				expr: &seqExpr{
+					pos: position{line: 397, col: 13, offset: 12619},
+					exprs: []any{
+						&labeledExpr{
+							pos:   position{line: 397, col: 13, offset: 12619},
+							label: "target",
+							expr: &zeroOrOneExpr{
+								pos: position{line: 397, col: 20, offset: 12626},
+								expr: &ruleRefExpr{
+									pos:  position{line: 397, col: 20, offset: 12626},
+									name: "TargetAttribute",
+								},
+							},
+						},
+						&ruleRefExpr{
+							pos:  position{line: 397, col: 37, offset: 12643},
+							name: "_",
+						},
+						&labeledExpr{
+							pos:   position{line: 397, col: 39, offset: 12645},
+							label: "pub",
+							expr: &zeroOrOneExpr{
+								pos: position{line: 397, col: 43, offset: 12649},
+								expr: &seqExpr{
+									pos: position{line: 397, col: 44, offset: 12650},
+									exprs: []any{
+										&litMatcher{
+											pos:        position{line: 397, col: 44, offset: 12650},
+											val:        "pub",
+											ignoreCase: false,
+											want:       "\"pub\"",
+										},
+										&ruleRefExpr{
+											pos:  position{line: 397, col: 50, offset: 12656},
+											name: "__",
+										},
+									},
+								},
+							},
+						},
+						&litMatcher{
+							pos:        position{line: 397, col: 55, offset: 12661},
+							val:        "const",
+							ignoreCase: false,
+							want:       "\"const\"",
+						},
+						&ruleRefExpr{
+							pos:  position{line: 397, col: 63, offset: 12669},
+							name: "__",
+						},
+						&labeledExpr{
+							pos:   position{line: 397, col: 66, offset: 12672},
+							label: "name",
+							expr: &ruleRefExpr{
+								pos:  position{line: 397, col: 71, offset: 12677},
+								name: "Name",
+							},
+						},
+						&labeledExpr{
+							pos:   position{line: 397, col: 76, offset: 12682},
+							label: "typ",
+							expr: &zeroOrOneExpr{
+								pos: position{line: 397, col: 80, offset: 12686},
+								expr: &seqExpr{
+									pos: position{line: 397, col: 81, offset: 12687},
+									exprs: []any{
+										&ruleRefExpr{
+											pos:  position{line: 397, col: 81, offset: 12687},
+											name: "_",
+										},
+										&litMatcher{
+											pos:        position{line: 397, col: 83, offset: 12689},
+											val:        ":",
+											ignoreCase: false,
+											want:       "\":\"",
+										},
+										&ruleRefExpr{
+											pos:  position{line: 397, col: 87, offset: 12693},
+											name: "_",
+										},
+										&ruleRefExpr{
+											pos:  position{line: 397, col: 89, offset: 12695},
+											name: "TypeAnnotationContent",
+										},
+									},
+								},
+							},
+						},
+						&ruleRefExpr{
+							pos:  position{line: 397, col: 113, offset: 12719},
+							name: "_",
+						},
+						&litMatcher{
+							pos:        position{line: 397, col: 115, offset: 12721},
+							val:        "=",
+							ignoreCase: false,
+							want:       "\"=\"",
+						},
+						&ruleRefExpr{
+							pos:  position{line: 397, col: 119, offset: 12725},
+							name: "_",
+						},
+						&ruleRefExpr{
+							pos:  position{line: 397, col: 121, offset: 12727},
+							name: "ConstValue",
+						},
+					},
+				},
+			},
+		},
+		{
+			name: "ConstValue",
+			pos:  position{line: 408, col: 1, offset: 13136},
+			expr: &oneOrMoreExpr{
+				pos: position{line: 408, col: 15, offset: 13150},
+				expr: &choiceExpr{
+					pos: position{line: 408, col: 16, offset: 13151},
+					alternatives: []any{
+						&ruleRefExpr{
+							pos:  position{line: 408, col: 16, offset: 13151},
+							name: "ConstGroup",
+						},
+						&ruleRefExpr{
+							pos:  position{line: 408, col: 29, offset: 13164},
+							name: "StringLiteral",
+						},
+						&seqExpr{
+							pos: position{line: 408, col: 45, offset: 13180},
+							exprs: []any{
+								&notExpr{
+									pos: position{line: 408, col: 45, offset: 13180},
+									expr: &seqExpr{
+										pos: position{line: 408, col: 47, offset: 13182},
+										exprs: []any{
+											&litMatcher{
+												pos:        position{line: 408, col: 47, offset: 13182},
+												val:        "\n",
+												ignoreCase: false,
+												want:       "\"\\n\"",
+											},
+											&notExpr{
+												pos: position{line: 408, col: 52, offset: 13187},
+												expr: &charClassMatcher{
+													pos:        position{line: 408, col: 53, offset: 13188},
+													val:        "[ \\t\\r\\n]",
+													chars:      []rune{' ', '\t', '\r', '\n'},
+													ignoreCase: false,
+													inverted:   false,
+												},
+											},
+										},
+									},
+								},
+								&notExpr{
+									pos: position{line: 408, col: 64, offset: 13199},
+									expr: &charClassMatcher{
+										pos:        position{line: 408, col: 65, offset: 13200},
+										val:        "[)\\]}]",
+										chars:      []rune{')', ']', '}'},
+										ignoreCase: false,
+										inverted:   false,
+									},
+								},
+								&anyMatcher{
+									line: 408, col: 72, offset: 13207,
+								},
+							},
+						},
+					},
+				},
+			},
+		},
+		{
+			name: "ConstGroup",
+			pos:  position{line: 409, col: 1, offset: 13211},
+			expr: &choiceExpr{
+				pos: position{line: 409, col: 15, offset: 13225},
+				alternatives: []any{
+					&seqExpr{
+						pos: position{line: 409, col: 15, offset: 13225},
+						exprs: []any{
+							&litMatcher{
+								pos:        position{line: 409, col: 15, offset: 13225},
+								val:        "(",
+								ignoreCase: false,
+								want:       "\"(\"",
+							},
+							&zeroOrMoreExpr{
+								pos: position{line: 409, col: 19, offset: 13229},
+								expr: &choiceExpr{
+									pos: position{line: 409, col: 20, offset: 13230},
+									alternatives: []any{
+										&ruleRefExpr{
+											pos:  position{line: 409, col: 20, offset: 13230},
+											name: "ConstGroup",
+										},
+										&ruleRefExpr{
+											pos:  position{line: 409, col: 33, offset: 13243},
+											name: "StringLiteral",
+										},
+										&charClassMatcher{
+											pos:        position{line: 409, col: 49, offset: 13259},
+											val:        "[^()[\\]{}\"]",
+											chars:      []rune{'(', ')', '[', ']', '{', '}', '"'},
+											ignoreCase: false,
+											inverted:   true,
+										},
+									},
+								},
+							},
+							&litMatcher{
+								pos:        position{line: 409, col: 63, offset: 13273},
+								val:        ")",
+								ignoreCase: false,
+								want:       "\")\"",
+							},
+						},
+					},
+					&seqExpr{
+						pos: position{line: 410, col: 5, offset: 13283},
+						exprs: []any{
+							&litMatcher{
+								pos:        position{line: 410, col: 5, offset: 13283},
+								val:        "[",
+								ignoreCase: false,
+								want:       "\"[\"",
+							},
+							&zeroOrMoreExpr{
+								pos: position{line: 410, col: 9, offset: 13287},
+								expr: &choiceExpr{
+									pos: position{line: 410, col: 10, offset: 13288},
+									alternatives: []any{
+										&ruleRefExpr{
+											pos:  position{line: 410, col: 10, offset: 13288},
+											name: "ConstGroup",
+										},
+										&ruleRefExpr{
+											pos:  position{line: 410, col: 23, offset: 13301},
+											name: "StringLiteral",
+										},
+										&charClassMatcher{
+											pos:        position{line: 410, col: 39, offset: 13317},
+											val:        "[^()[\\]{}\"]",
+											chars:      []rune{'(', ')', '[', ']', '{', '}', '"'},
 											ignoreCase: false,
-											want:       "\".\"",
+											inverted:   true,
 										},
 									},
 								},
 							},
-						},
-						&labeledExpr{
-							pos:   position{line: 172, col: 25, offset: 5632},
-							label: "name",
-							expr: &choiceExpr{
-								pos: position{line: 172, col: 31, offset: 5638},
-								alternatives: []any{
-									&ruleRefExpr{
-										pos:  position{line: 172, col: 31, offset: 5638},
-										name: "Name",
-									},
-									&ruleRefExpr{
-										pos:  position{line: 172, col: 38, offset: 5645},
-										name: "UpName",
-									},
-								},
+							&litMatcher{
+								pos:        position{line: 410, col: 53, offset: 13331},
+								val:        "]",
+								ignoreCase: false,
+								want:       "\"]\"",
 							},
 						},
-						&zeroOrOneExpr{
-							pos: position{line: 172, col: 46, offset: 5653},
-							expr: &seqExpr{
-								pos: position{line: 172, col: 47, offset: 5654},
-								exprs: []any{
-									&ruleRefExpr{
-										pos:  position{line: 172, col: 47, offset: 5654},
-										name: "_",
-									},
-									&labeledExpr{
-										pos:   position{line: 172, col: 49, offset: 5656},
-										label: "gp",
-										expr: &ruleRefExpr{
-											pos:  position{line: 172, col: 52, offset: 5659},
-											name: "GenericParams",
+					},
+					&seqExpr{
+						pos: position{line: 411, col: 5, offset: 13341},
+						exprs: []any{
+							&litMatcher{
+								pos:        position{line: 411, col: 5, offset: 13341},
+								val:        "{",
+								ignoreCase: false,
+								want:       "\"{\"",
+							},
+							&zeroOrMoreExpr{
+								pos: position{line: 411, col: 9, offset: 13345},
+								expr: &choiceExpr{
+									pos: position{line: 411, col: 10, offset: 13346},
+									alternatives: []any{
+										&ruleRefExpr{
+											pos:  position{line: 411, col: 10, offset: 13346},
+											name: "ConstGroup",
+										},
+										&ruleRefExpr{
+											pos:  position{line: 411, col: 23, offset: 13359},
+											name: "StringLiteral",
+										},
+										&charClassMatcher{
+											pos:        position{line: 411, col: 39, offset: 13375},
+											val:        "[^()[\\]{}\"]",
+											chars:      []rune{'(', ')', '[', ']', '{', '}', '"'},
+											ignoreCase: false,
+											inverted:   true,
 										},
 									},
 								},
 							},
+							&litMatcher{
+								pos:        position{line: 411, col: 53, offset: 13389},
+								val:        "}",
+								ignoreCase: false,
+								want:       "\"}\"",
+							},
 						},
 					},
 				},
 			},
 		},
 		{
-			name: "IgnoredType",
-			pos:  position{line: 175, col: 1, offset: 5709},
-			expr: &actionExpr{
-				pos: position{line: 175, col: 16, offset: 5724},
-				run: (*parser).callonIgnoredType1,
-				expr: &seqExpr{
-					pos: position{line: 175, col: 16, offset: 5724},
-					exprs: []any{
-						&labeledExpr{
-							pos:   position{line: 175, col: 16, offset: 5724},
-							label: "pub",
-							expr: &zeroOrOneExpr{
-								pos: position{line: 175, col: 20, offset: 5728},
-								expr: &seqExpr{
-									pos: position{line: 175, col: 21, offset: 5729},
-									exprs: []any{
-										&litMatcher{
-											pos:        position{line: 175, col: 21, offset: 5729},
-											val:        "pub",
-											ignoreCase: false,
-											want:       "\"pub\"",
-										},
-										&ruleRefExpr{
-											pos:  position{line: 175, col: 27, offset: 5735},
-											name: "__",
-										},
-									},
-								},
-							},
-						},
-						&labeledExpr{
-							pos:   position{line: 175, col: 32, offset: 5740},
-							label: "opaque",
-							expr: &zeroOrOneExpr{
-								pos: position{line: 175, col: 39, offset: 5747},
-								expr: &seqExpr{
-									pos: position{line: 175, col: 40, offset: 5748},
+			name: "StringLiteral",
+			pos:  position{line: 412, col: 1, offset: 13393},
+			expr: &seqExpr{
+				pos: position{line: 412, col: 18, offset: 13410},
+				exprs: []any{
+					&litMatcher{
+						pos:        position{line: 412, col: 18, offset: 13410},
+						val:        "\"",
+						ignoreCase: false,
+						want:       "\"\\\"\"",
+					},
+					&zeroOrMoreExpr{
+						pos: position{line: 412, col: 23, offset: 13415},
+						expr: &choiceExpr{
+							pos: position{line: 412, col: 24, offset: 13416},
+							alternatives: []any{
+								&seqExpr{
+									pos: position{line: 412, col: 24, offset: 13416},
 									exprs: []any{
 										&litMatcher{
-											pos:        position{line: 175, col: 40, offset: 5748},
-											val:        "opaque",
+											pos:        position{line: 412, col: 24, offset: 13416},
+											val:        "\\",
 											ignoreCase: false,
-											want:       "\"opaque\"",
+											want:       "\"\\\\\"",
 										},
-										&ruleRefExpr{
-											pos:  position{line: 175, col: 49, offset: 5757},
-											name: "__",
+										&anyMatcher{
+											line: 412, col: 29, offset: 13421,
 										},
 									},
 								},
-							},
-						},
-						&litMatcher{
-							pos:        position{line: 175, col: 54, offset: 5762},
-							val:        "type",
-							ignoreCase: false,
-							want:       "\"type\"",
-						},
-						&ruleRefExpr{
-							pos:  position{line: 175, col: 61, offset: 5769},
-							name: "__",
-						},
-						&ruleRefExpr{
-							pos:  position{line: 175, col: 65, offset: 5773},
-							name: "Type",
-						},
-						&zeroOrOneExpr{
-							pos: position{line: 175, col: 71, offset: 5779},
-							expr: &seqExpr{
-								pos: position{line: 175, col: 72, offset: 5780},
-								exprs: []any{
-									&ruleRefExpr{
-										pos:  position{line: 175, col: 72, offset: 5780},
-										name: "_",
-									},
-									&ruleRefExpr{
-										pos:  position{line: 175, col: 74, offset: 5782},
-										name: "IgnoredBlock",
-									},
+								&charClassMatcher{
+									pos:        position{line: 412, col: 33, offset: 13425},
+									val:        "[^\"\\\\]",
+									chars:      []rune{'"', '\\'},
+									ignoreCase: false,
+									inverted:   true,
 								},
 							},
 						},
 					},
+					&litMatcher{
+						pos:        position{line: 412, col: 42, offset: 13434},
+						val:        "\"",
+						ignoreCase: false,
+						want:       "\"\\\"\"",
+					},
 				},
 			},
 		},
 		{
 			name: "GenericParams",
-			pos:  position{line: 178, col: 1, offset: 5821},
+			pos:  position{line: 413, col: 1, offset: 13439},
 			expr: &actionExpr{
-				pos: position{line: 178, col: 18, offset: 5838},
+				pos: position{line: 413, col: 18, offset: 13456},
 				run: (*parser).callonGenericParams1,
 				expr: &seqExpr{
-					pos: position{line: 178, col: 18, offset: 5838},
+					pos: position{line: 413, col: 18, offset: 13456},
 					exprs: []any{
 						&litMatcher{
-							pos:        position{line: 178, col: 18, offset: 5838},
+							pos:        position{line: 413, col: 18, offset: 13456},
 							val:        "(",
 							ignoreCase: false,
 							want:       "\"(\"",
 						},
 						&ruleRefExpr{
-							pos:  position{line: 178, col: 22, offset: 5842},
+							pos:  position{line: 413, col: 22, offset: 13460},
 							name: "_",
 						},
 						&labeledExpr{
-							pos:   position{line: 178, col: 24, offset: 5844},
+							pos:   position{line: 413, col: 24, offset: 13462},
 							label: "first",
 							expr: &ruleRefExpr{
-								pos:  position{line: 178, col: 31, offset: 5851},
+								pos:  position{line: 413, col: 31, offset: 13469},
 								name: "TypeAnnotationContent",
 							},
 						},
 						&labeledExpr{
-							pos:   position{line: 178, col: 54, offset: 5874},
+							pos:   position{line: 413, col: 54, offset: 13492},
 							label: "rest",
 							expr: &zeroOrMoreExpr{
-								pos: position{line: 178, col: 59, offset: 5879},
+								pos: position{line: 413, col: 59, offset: 13497},
 								expr: &seqExpr{
-									pos: position{line: 178, col: 60, offset: 5880},
+									pos: position{line: 413, col: 60, offset: 13498},
 									exprs: []any{
 										&ruleRefExpr{
-											pos:  position{line: 178, col: 60, offset: 5880},
+											pos:  position{line: 413, col: 60, offset: 13498},
 											name: "_",
 										},
 										&litMatcher{
-											pos:        position{line: 178, col: 62, offset: 5882},
+											pos:        position{line: 413, col: 62, offset: 13500},
 											val:        ",",
 											ignoreCase: false,
 											want:       "\",\"",
 										},
 										&ruleRefExpr{
-											pos:  position{line: 178, col: 66, offset: 5886},
+											pos:  position{line: 413, col: 66, offset: 13504},
 											name: "_",
 										},
 										&ruleRefExpr{
-											pos:  position{line: 178, col: 68, offset: 5888},
+											pos:  position{line: 413, col: 68, offset: 13506},
 											name: "TypeAnnotationContent",
 										},
 									},
@@ -1160,20 +2285,24 @@ var g = &grammar{
 							},
 						},
 						&ruleRefExpr{
-							pos:  position{line: 178, col: 92, offset: 5912},
+							pos:  position{line: 413, col: 92, offset: 13530},
 							name: "_",
 						},
 						&zeroOrOneExpr{
-							pos: position{line: 178, col: 94, offset: 5914},
+							pos: position{line: 413, col: 94, offset: 13532},
 							expr: &litMatcher{
-								pos:        position{line: 178, col: 94, offset: 5914},
+								pos:        position{line: 413, col: 94, offset: 13532},
 								val:        ",",
 								ignoreCase: false,
 								want:       "\",\"",
 							},
 						},
+						&ruleRefExpr{
+							pos:  position{line: 413, col: 99, offset: 13537},
+							name: "_",
+						},
 						&litMatcher{
-							pos:        position{line: 178, col: 99, offset: 5919},
+							pos:        position{line: 413, col: 101, offset: 13539},
 							val:        ")",
 							ignoreCase: false,
 							want:       "\")\"",
@@ -1184,27 +2313,27 @@ var g = &grammar{
 		},
 		{
 			name: "GenericParam",
-			pos:  position{line: 179, col: 1, offset: 5943},
+			pos:  position{line: 420, col: 1, offset: 13695},
 			expr: &actionExpr{
-				pos: position{line: 179, col: 17, offset: 5959},
+				pos: position{line: 420, col: 17, offset: 13711},
 				run: (*parser).callonGenericParam1,
 				expr: &seqExpr{
-					pos: position{line: 179, col: 17, offset: 5959},
+					pos: position{line: 420, col: 17, offset: 13711},
 					exprs: []any{
 						&labeledExpr{
-							pos:   position{line: 179, col: 17, offset: 5959},
+							pos:   position{line: 420, col: 17, offset: 13711},
 							label: "mod",
 							expr: &zeroOrOneExpr{
-								pos: position{line: 179, col: 21, offset: 5963},
+								pos: position{line: 420, col: 21, offset: 13715},
 								expr: &seqExpr{
-									pos: position{line: 179, col: 22, offset: 5964},
+									pos: position{line: 420, col: 22, offset: 13716},
 									exprs: []any{
 										&ruleRefExpr{
-											pos:  position{line: 179, col: 22, offset: 5964},
+											pos:  position{line: 420, col: 22, offset: 13716},
 											name: "Name",
 										},
 										&litMatcher{
-											pos:        position{line: 179, col: 27, offset: 5969},
+											pos:        position{line: 420, col: 27, offset: 13721},
 											val:        ".",
 											ignoreCase: false,
 											want:       "\".\"",
@@ -1214,37 +2343,37 @@ var g = &grammar{
 							},
 						},
 						&labeledExpr{
-							pos:   position{line: 179, col: 33, offset: 5975},
+							pos:   position{line: 420, col: 33, offset: 13727},
 							label: "param",
 							expr: &choiceExpr{
-								pos: position{line: 179, col: 40, offset: 5982},
+								pos: position{line: 420, col: 40, offset: 13734},
 								alternatives: []any{
 									&ruleRefExpr{
-										pos:  position{line: 179, col: 40, offset: 5982},
+										pos:  position{line: 420, col: 40, offset: 13734},
 										name: "Name",
 									},
 									&ruleRefExpr{
-										pos:  position{line: 179, col: 47, offset: 5989},
+										pos:  position{line: 420, col: 47, offset: 13741},
 										name: "UpName",
 									},
 								},
 							},
 						},
 						&zeroOrOneExpr{
-							pos: position{line: 179, col: 55, offset: 5997},
+							pos: position{line: 420, col: 55, offset: 13749},
 							expr: &seqExpr{
-								pos: position{line: 179, col: 56, offset: 5998},
+								pos: position{line: 420, col: 56, offset: 13750},
 								exprs: []any{
 									&ruleRefExpr{
-										pos:  position{line: 179, col: 56, offset: 5998},
+										pos:  position{line: 420, col: 56, offset: 13750},
 										name: "_",
 									},
 									&ruleRefExpr{
-										pos:  position{line: 179, col: 58, offset: 6000},
+										pos:  position{line: 420, col: 58, offset: 13752},
 										name: "GenericParams",
 									},
 									&ruleRefExpr{
-										pos:  position{line: 179, col: 72, offset: 6014},
+										pos:  position{line: 420, col: 72, offset: 13766},
 										name: "_",
 									},
 								},
@@ -1256,30 +2385,30 @@ var g = &grammar{
 		},
 		{
 			name: "LabeledNameParam",
-			pos:  position{line: 180, col: 1, offset: 6038},
+			pos:  position{line: 421, col: 1, offset: 13790},
 			expr: &actionExpr{
-				pos: position{line: 180, col: 21, offset: 6058},
+				pos: position{line: 421, col: 21, offset: 13810},
 				run: (*parser).callonLabeledNameParam1,
 				expr: &seqExpr{
-					pos: position{line: 180, col: 21, offset: 6058},
+					pos: position{line: 421, col: 21, offset: 13810},
 					exprs: []any{
 						&labeledExpr{
-							pos:   position{line: 180, col: 21, offset: 6058},
+							pos:   position{line: 421, col: 21, offset: 13810},
 							label: "label",
 							expr: &ruleRefExpr{
-								pos:  position{line: 180, col: 27, offset: 6064},
+								pos:  position{line: 421, col: 27, offset: 13816},
 								name: "Label",
 							},
 						},
 						&ruleRefExpr{
-							pos:  position{line: 180, col: 33, offset: 6070},
+							pos:  position{line: 421, col: 33, offset: 13822},
 							name: "_",
 						},
 						&labeledExpr{
-							pos:   position{line: 180, col: 35, offset: 6072},
+							pos:   position{line: 421, col: 35, offset: 13824},
 							label: "name",
 							expr: &ruleRefExpr{
-								pos:  position{line: 180, col: 40, offset: 6077},
+								pos:  position{line: 421, col: 40, offset: 13829},
 								name: "Identifier",
 							},
 						},
@@ -1289,15 +2418,15 @@ var g = &grammar{
 		},
 		{
 			name: "NameParam",
-			pos:  position{line: 183, col: 1, offset: 6168},
+			pos:  position{line: 424, col: 1, offset: 13936},
 			expr: &actionExpr{
-				pos: position{line: 183, col: 14, offset: 6181},
+				pos: position{line: 424, col: 14, offset: 13949},
 				run: (*parser).callonNameParam1,
 				expr: &labeledExpr{
-					pos:   position{line: 183, col: 14, offset: 6181},
+					pos:   position{line: 424, col: 14, offset: 13949},
 					label: "name",
 					expr: &ruleRefExpr{
-						pos:  position{line: 183, col: 19, offset: 6186},
+						pos:  position{line: 424, col: 19, offset: 13954},
 						name: "Identifier",
 					},
 				},
@@ -1305,15 +2434,15 @@ var g = &grammar{
 		},
 		{
 			name: "DiscardParam",
-			pos:  position{line: 184, col: 1, offset: 6253},
+			pos:  position{line: 425, col: 1, offset: 14037},
 			expr: &actionExpr{
-				pos: position{line: 184, col: 17, offset: 6269},
+				pos: position{line: 425, col: 17, offset: 14053},
 				run: (*parser).callonDiscardParam1,
 				expr: &labeledExpr{
-					pos:   position{line: 184, col: 17, offset: 6269},
+					pos:   position{line: 425, col: 17, offset: 14053},
 					label: "name",
 					expr: &ruleRefExpr{
-						pos:  position{line: 184, col: 22, offset: 6274},
+						pos:  position{line: 425, col: 22, offset: 14058},
 						name: "Discard",
 					},
 				},
@@ -1321,95 +2450,95 @@ var g = &grammar{
 		},
 		{
 			name: "Identifier",
-			pos:  position{line: 185, col: 1, offset: 6329},
+			pos:  position{line: 426, col: 1, offset: 14135},
 			expr: &actionExpr{
-				pos: position{line: 185, col: 15, offset: 6343},
+				pos: position{line: 426, col: 15, offset: 14149},
 				run: (*parser).callonIdentifier1,
 				expr: &ruleRefExpr{
-					pos:  position{line: 185, col: 15, offset: 6343},
+					pos:  position{line: 426, col: 15, offset: 14149},
 					name: "Name",
 				},
 			},
 		},
 		{
 			name: "Discard",
-			pos:  position{line: 186, col: 1, offset: 6397},
+			pos:  position{line: 427, col: 1, offset: 14219},
 			expr: &actionExpr{
-				pos: position{line: 186, col: 12, offset: 6408},
+				pos: position{line: 427, col: 12, offset: 14230},
 				run: (*parser).callonDiscard1,
 				expr: &ruleRefExpr{
-					pos:  position{line: 186, col: 12, offset: 6408},
+					pos:  position{line: 427, col: 12, offset: 14230},
 					name: "DiscardName",
 				},
 			},
 		},
 		{
 			name: "Label",
-			pos:  position{line: 187, col: 1, offset: 6466},
+			pos:  position{line: 428, col: 1, offset: 14304},
 			expr: &actionExpr{
-				pos: position{line: 187, col: 10, offset: 6475},
+				pos: position{line: 428, col: 10, offset: 14313},
 				run: (*parser).callonLabel1,
 				expr: &ruleRefExpr{
-					pos:  position{line: 187, col: 10, offset: 6475},
+					pos:  position{line: 428, col: 10, offset: 14313},
 					name: "Name",
 				},
 			},
 		},
 		{
 			name: "IgnoredContent",
-			pos:  position{line: 189, col: 1, offset: 6512},
+			pos:  position{line: 430, col: 1, offset: 14350},
 			expr: &actionExpr{
-				pos: position{line: 189, col: 19, offset: 6530},
+				pos: position{line: 430, col: 19, offset: 14368},
 				run: (*parser).callonIgnoredContent1,
 				expr: &labeledExpr{
-					pos:   position{line: 189, col: 19, offset: 6530},
+					pos:   position{line: 430, col: 19, offset: 14368},
 					label: "expr",
 					expr: &oneOrMoreExpr{
-						pos: position{line: 189, col: 24, offset: 6535},
+						pos: position{line: 430, col: 24, offset: 14373},
 						expr: &seqExpr{
-							pos: position{line: 189, col: 25, offset: 6536},
+							pos: position{line: 430, col: 25, offset: 14374},
 							exprs: []any{
 								&notExpr{
-									pos: position{line: 189, col: 25, offset: 6536},
+									pos: position{line: 430, col: 25, offset: 14374},
 									expr: &choiceExpr{
-										pos: position{line: 190, col: 5, offset: 6543},
+										pos: position{line: 431, col: 5, offset: 14381},
 										alternatives: []any{
 											&seqExpr{
-												pos: position{line: 190, col: 5, offset: 6543},
+												pos: position{line: 431, col: 5, offset: 14381},
 												exprs: []any{
 													&ruleRefExpr{
-														pos:  position{line: 190, col: 5, offset: 6543},
+														pos:  position{line: 431, col: 5, offset: 14381},
 														name: "TargetAttribute",
 													},
 													&ruleRefExpr{
-														pos:  position{line: 190, col: 21, offset: 6559},
+														pos:  position{line: 431, col: 21, offset: 14397},
 														name: "_",
 													},
 													&litMatcher{
-														pos:        position{line: 190, col: 23, offset: 6561},
+														pos:        position{line: 431, col: 23, offset: 14399},
 														val:        "fn",
 														ignoreCase: false,
 														want:       "\"fn\"",
 													},
 													&ruleRefExpr{
-														pos:  position{line: 190, col: 28, offset: 6566},
+														pos:  position{line: 431, col: 28, offset: 14404},
 														name: "_",
 													},
 												},
 											},
 											&seqExpr{
-												pos: position{line: 191, col: 5, offset: 6574},
+												pos: position{line: 432, col: 5, offset: 14412},
 												exprs: []any{
 													&ruleRefExpr{
-														pos:  position{line: 191, col: 5, offset: 6574},
+														pos:  position{line: 432, col: 5, offset: 14412},
 														name: "TargetAttribute",
 													},
 													&ruleRefExpr{
-														pos:  position{line: 191, col: 21, offset: 6590},
+														pos:  position{line: 432, col: 21, offset: 14428},
 														name: "_",
 													},
 													&litMatcher{
-														pos:        position{line: 191, col: 23, offset: 6592},
+														pos:        position{line: 432, col: 23, offset: 14430},
 														val:        "import",
 														ignoreCase: false,
 														want:       "\"import\"",
@@ -1417,196 +2546,336 @@ var g = &grammar{
 												},
 											},
 											&seqExpr{
-												pos: position{line: 192, col: 5, offset: 6607},
+												pos: position{line: 433, col: 5, offset: 14445},
 												exprs: []any{
 													&ruleRefExpr{
-														pos:  position{line: 192, col: 5, offset: 6607},
+														pos:  position{line: 433, col: 5, offset: 14445},
 														name: "TargetAttribute",
 													},
 													&ruleRefExpr{
-														pos:  position{line: 192, col: 21, offset: 6623},
+														pos:  position{line: 433, col: 21, offset: 14461},
 														name: "_",
 													},
 													&litMatcher{
-														pos:        position{line: 192, col: 23, offset: 6625},
+														pos:        position{line: 433, col: 23, offset: 14463},
 														val:        "pub",
 														ignoreCase: false,
 														want:       "\"pub\"",
 													},
 													&ruleRefExpr{
-														pos:  position{line: 192, col: 29, offset: 6631},
+														pos:  position{line: 433, col: 29, offset: 14469},
 														name: "_",
 													},
 													&litMatcher{
-														pos:        position{line: 192, col: 31, offset: 6633},
+														pos:        position{line: 433, col: 31, offset: 14471},
 														val:        "fn",
 														ignoreCase: false,
 														want:       "\"fn\"",
 													},
 													&ruleRefExpr{
-														pos:  position{line: 192, col: 36, offset: 6638},
+														pos:  position{line: 433, col: 36, offset: 14476},
+														name: "_",
+													},
+												},
+											},
+											&seqExpr{
+												pos: position{line: 434, col: 5, offset: 14484},
+												exprs: []any{
+													&ruleRefExpr{
+														pos:  position{line: 434, col: 5, offset: 14484},
+														name: "TargetAttribute",
+													},
+													&ruleRefExpr{
+														pos:  position{line: 434, col: 21, offset: 14500},
+														name: "_",
+													},
+													&zeroOrOneExpr{
+														pos: position{line: 434, col: 23, offset: 14502},
+														expr: &seqExpr{
+															pos: position{line: 434, col: 24, offset: 14503},
+															exprs: []any{
+																&litMatcher{
+																	pos:        position{line: 434, col: 24, offset: 14503},
+																	val:        "pub",
+																	ignoreCase: false,
+																	want:       "\"pub\"",
+																},
+																&ruleRefExpr{
+																	pos:  position{line: 434, col: 30, offset: 14509},
+																	name: "_",
+																},
+															},
+														},
+													},
+													&zeroOrOneExpr{
+														pos: position{line: 434, col: 34, offset: 14513},
+														expr: &seqExpr{
+															pos: position{line: 434, col: 35, offset: 14514},
+															exprs: []any{
+																&litMatcher{
+																	pos:        position{line: 434, col: 35, offset: 14514},
+																	val:        "opaque",
+																	ignoreCase: false,
+																	want:       "\"opaque\"",
+																},
+																&ruleRefExpr{
+																	pos:  position{line: 434, col: 44, offset: 14523},
+																	name: "_",
+																},
+															},
+														},
+													},
+													&litMatcher{
+														pos:        position{line: 434, col: 48, offset: 14527},
+														val:        "type",
+														ignoreCase: false,
+														want:       "\"type\"",
+													},
+													&ruleRefExpr{
+														pos:  position{line: 434, col: 55, offset: 14534},
+														name: "_",
+													},
+												},
+											},
+											&seqExpr{
+												pos: position{line: 435, col: 5, offset: 14542},
+												exprs: []any{
+													&ruleRefExpr{
+														pos:  position{line: 435, col: 5, offset: 14542},
+														name: "TargetAttribute",
+													},
+													&ruleRefExpr{
+														pos:  position{line: 435, col: 21, offset: 14558},
+														name: "_",
+													},
+													&zeroOrOneExpr{
+														pos: position{line: 435, col: 23, offset: 14560},
+														expr: &seqExpr{
+															pos: position{line: 435, col: 24, offset: 14561},
+															exprs: []any{
+																&litMatcher{
+																	pos:        position{line: 435, col: 24, offset: 14561},
+																	val:        "pub",
+																	ignoreCase: false,
+																	want:       "\"pub\"",
+																},
+																&ruleRefExpr{
+																	pos:  position{line: 435, col: 30, offset: 14567},
+																	name: "_",
+																},
+															},
+														},
+													},
+													&litMatcher{
+														pos:        position{line: 435, col: 34, offset: 14571},
+														val:        "const",
+														ignoreCase: false,
+														want:       "\"const\"",
+													},
+													&ruleRefExpr{
+														pos:  position{line: 435, col: 42, offset: 14579},
 														name: "_",
 													},
 												},
 											},
 											&seqExpr{
-												pos: position{line: 193, col: 5, offset: 6646},
+												pos: position{line: 436, col: 5, offset: 14587},
 												exprs: []any{
 													&litMatcher{
-														pos:        position{line: 193, col: 5, offset: 6646},
+														pos:        position{line: 436, col: 5, offset: 14587},
 														val:        "import",
 														ignoreCase: false,
 														want:       "\"import\"",
 													},
 													&ruleRefExpr{
-														pos:  position{line: 193, col: 14, offset: 6655},
+														pos:  position{line: 436, col: 14, offset: 14596},
 														name: "_",
 													},
 												},
 											},
 											&seqExpr{
-												pos: position{line: 194, col: 5, offset: 6663},
+												pos: position{line: 437, col: 5, offset: 14604},
 												exprs: []any{
 													&ruleRefExpr{
-														pos:  position{line: 194, col: 5, offset: 6663},
+														pos:  position{line: 437, col: 5, offset: 14604},
 														name: "ExternalAttribute",
 													},
 													&ruleRefExpr{
-														pos:  position{line: 194, col: 23, offset: 6681},
+														pos:  position{line: 437, col: 23, offset: 14622},
 														name: "_",
 													},
 												},
 											},
 											&seqExpr{
-												pos: position{line: 195, col: 5, offset: 6689},
+												pos: position{line: 439, col: 5, offset: 14676},
 												exprs: []any{
 													&litMatcher{
-														pos:        position{line: 195, col: 5, offset: 6689},
+														pos:        position{line: 439, col: 5, offset: 14676},
 														val:        "fn",
 														ignoreCase: false,
 														want:       "\"fn\"",
 													},
 													&ruleRefExpr{
-														pos:  position{line: 195, col: 10, offset: 6694},
+														pos:  position{line: 439, col: 10, offset: 14681},
 														name: "_",
 													},
 													&ruleRefExpr{
-														pos:  position{line: 195, col: 12, offset: 6696},
+														pos:  position{line: 439, col: 12, offset: 14683},
 														name: "Name",
 													},
 												},
 											},
 											&seqExpr{
-												pos: position{line: 196, col: 5, offset: 6707},
+												pos: position{line: 440, col: 5, offset: 14694},
 												exprs: []any{
 													&litMatcher{
-														pos:        position{line: 196, col: 5, offset: 6707},
+														pos:        position{line: 440, col: 5, offset: 14694},
 														val:        "pub",
 														ignoreCase: false,
 														want:       "\"pub\"",
 													},
 													&ruleRefExpr{
-														pos:  position{line: 196, col: 11, offset: 6713},
+														pos:  position{line: 440, col: 11, offset: 14700},
 														name: "_",
 													},
 													&litMatcher{
-														pos:        position{line: 196, col: 13, offset: 6715},
+														pos:        position{line: 440, col: 13, offset: 14702},
 														val:        "fn",
 														ignoreCase: false,
 														want:       "\"fn\"",
 													},
 													&ruleRefExpr{
-														pos:  position{line: 196, col: 18, offset: 6720},
+														pos:  position{line: 440, col: 18, offset: 14707},
 														name: "_",
 													},
 												},
 											},
 											&seqExpr{
-												pos: position{line: 197, col: 5, offset: 6728},
+												pos: position{line: 441, col: 5, offset: 14715},
 												exprs: []any{
 													&litMatcher{
-														pos:        position{line: 197, col: 5, offset: 6728},
+														pos:        position{line: 441, col: 5, offset: 14715},
 														val:        "type",
 														ignoreCase: false,
 														want:       "\"type\"",
 													},
 													&ruleRefExpr{
-														pos:  position{line: 197, col: 12, offset: 6735},
+														pos:  position{line: 441, col: 12, offset: 14722},
 														name: "_",
 													},
 												},
 											},
 											&seqExpr{
-												pos: position{line: 198, col: 5, offset: 6743},
+												pos: position{line: 442, col: 5, offset: 14730},
 												exprs: []any{
 													&litMatcher{
-														pos:        position{line: 198, col: 5, offset: 6743},
+														pos:        position{line: 442, col: 5, offset: 14730},
 														val:        "pub",
 														ignoreCase: false,
 														want:       "\"pub\"",
 													},
 													&ruleRefExpr{
-														pos:  position{line: 198, col: 11, offset: 6749},
+														pos:  position{line: 442, col: 11, offset: 14736},
 														name: "_",
 													},
 													&litMatcher{
-														pos:        position{line: 198, col: 13, offset: 6751},
+														pos:        position{line: 442, col: 13, offset: 14738},
 														val:        "type",
 														ignoreCase: false,
 														want:       "\"type\"",
 													},
 													&ruleRefExpr{
-														pos:  position{line: 198, col: 20, offset: 6758},
+														pos:  position{line: 442, col: 20, offset: 14745},
 														name: "_",
 													},
 												},
 											},
 											&seqExpr{
-												pos: position{line: 199, col: 5, offset: 6766},
+												pos: position{line: 443, col: 5, offset: 14753},
 												exprs: []any{
 													&litMatcher{
-														pos:        position{line: 199, col: 5, offset: 6766},
+														pos:        position{line: 443, col: 5, offset: 14753},
 														val:        "pub",
 														ignoreCase: false,
 														want:       "\"pub\"",
 													},
 													&ruleRefExpr{
-														pos:  position{line: 199, col: 11, offset: 6772},
+														pos:  position{line: 443, col: 11, offset: 14759},
 														name: "_",
 													},
 													&litMatcher{
-														pos:        position{line: 199, col: 13, offset: 6774},
+														pos:        position{line: 443, col: 13, offset: 14761},
 														val:        "opaque",
 														ignoreCase: false,
 														want:       "\"opaque\"",
 													},
 													&ruleRefExpr{
-														pos:  position{line: 199, col: 22, offset: 6783},
+														pos:  position{line: 443, col: 22, offset: 14770},
 														name: "_",
 													},
 													&litMatcher{
-														pos:        position{line: 199, col: 24, offset: 6785},
+														pos:        position{line: 443, col: 24, offset: 14772},
 														val:        "type",
 														ignoreCase: false,
 														want:       "\"type\"",
 													},
 													&ruleRefExpr{
-														pos:  position{line: 199, col: 31, offset: 6792},
+														pos:  position{line: 443, col: 31, offset: 14779},
+														name: "_",
+													},
+												},
+											},
+											&seqExpr{
+												pos: position{line: 444, col: 5, offset: 14787},
+												exprs: []any{
+													&litMatcher{
+														pos:        position{line: 444, col: 5, offset: 14787},
+														val:        "const",
+														ignoreCase: false,
+														want:       "\"const\"",
+													},
+													&ruleRefExpr{
+														pos:  position{line: 444, col: 13, offset: 14795},
+														name: "_",
+													},
+												},
+											},
+											&seqExpr{
+												pos: position{line: 445, col: 5, offset: 14803},
+												exprs: []any{
+													&litMatcher{
+														pos:        position{line: 445, col: 5, offset: 14803},
+														val:        "pub",
+														ignoreCase: false,
+														want:       "\"pub\"",
+													},
+													&ruleRefExpr{
+														pos:  position{line: 445, col: 11, offset: 14809},
+														name: "_",
+													},
+													&litMatcher{
+														pos:        position{line: 445, col: 13, offset: 14811},
+														val:        "const",
+														ignoreCase: false,
+														want:       "\"const\"",
+													},
+													&ruleRefExpr{
+														pos:  position{line: 445, col: 21, offset: 14819},
 														name: "_",
 													},
 												},
 											},
 											&seqExpr{
-												pos: position{line: 200, col: 5, offset: 6800},
+												pos: position{line: 446, col: 5, offset: 14827},
 												exprs: []any{
 													&litMatcher{
-														pos:        position{line: 200, col: 5, offset: 6800},
+														pos:        position{line: 446, col: 5, offset: 14827},
 														val:        "//",
 														ignoreCase: false,
 														want:       "\"//\"",
 													},
 													&ruleRefExpr{
-														pos:  position{line: 200, col: 10, offset: 6805},
+														pos:  position{line: 446, col: 10, offset: 14832},
 														name: "_",
 													},
 												},
@@ -1615,7 +2884,7 @@ var g = &grammar{
 									},
 								},
 								&anyMatcher{
-									line: 201, col: 3, offset: 6809,
+									line: 447, col: 3, offset: 14836,
 								},
 							},
 						},
@@ -1625,45 +2894,45 @@ var g = &grammar{
 		},
 		{
 			name: "IgnoredBlock",
-			pos:  position{line: 204, col: 1, offset: 6837},
+			pos:  position{line: 450, col: 1, offset: 14864},
 			expr: &actionExpr{
-				pos: position{line: 204, col: 17, offset: 6853},
+				pos: position{line: 450, col: 17, offset: 14880},
 				run: (*parser).callonIgnoredBlock1,
 				expr: &seqExpr{
-					pos: position{line: 204, col: 17, offset: 6853},
+					pos: position{line: 450, col: 17, offset: 14880},
 					exprs: []any{
 						&litMatcher{
-							pos:        position{line: 204, col: 17, offset: 6853},
+							pos:        position{line: 450, col: 17, offset: 14880},
 							val:        "{",
 							ignoreCase: false,
 							want:       "\"{\"",
 						},
 						&labeledExpr{
-							pos:   position{line: 204, col: 21, offset: 6857},
+							pos:   position{line: 450, col: 21, offset: 14884},
 							label: "expr",
 							expr: &zeroOrMoreExpr{
-								pos: position{line: 204, col: 26, offset: 6862},
+								pos: position{line: 450, col: 26, offset: 14889},
 								expr: &choiceExpr{
-									pos: position{line: 204, col: 27, offset: 6863},
+									pos: position{line: 450, col: 27, offset: 14890},
 									alternatives: []any{
 										&ruleRefExpr{
-											pos:  position{line: 204, col: 27, offset: 6863},
+											pos:  position{line: 450, col: 27, offset: 14890},
 											name: "IgnoredBlock",
 										},
 										&seqExpr{
-											pos: position{line: 204, col: 42, offset: 6878},
+											pos: position{line: 450, col: 42, offset: 14905},
 											exprs: []any{
 												&notExpr{
-													pos: position{line: 204, col: 42, offset: 6878},
+													pos: position{line: 450, col: 42, offset: 14905},
 													expr: &litMatcher{
-														pos:        position{line: 204, col: 43, offset: 6879},
+														pos:        position{line: 450, col: 43, offset: 14906},
 														val:        "}",
 														ignoreCase: false,
 														want:       "\"}\"",
 													},
 												},
 												&anyMatcher{
-													line: 204, col: 47, offset: 6883,
+													line: 450, col: 47, offset: 14910,
 												},
 											},
 										},
@@ -1672,7 +2941,7 @@ var g = &grammar{
 							},
 						},
 						&litMatcher{
-							pos:        position{line: 204, col: 51, offset: 6887},
+							pos:        position{line: 450, col: 51, offset: 14914},
 							val:        "}",
 							ignoreCase: false,
 							want:       "\"}\"",
@@ -1684,18 +2953,18 @@ var g = &grammar{
 		{
 			name:        "_",
 			displayName: "\"whitespace\"",
-			pos:         position{line: 212, col: 1, offset: 7107},
+			pos:         position{line: 458, col: 1, offset: 15134},
 			expr: &zeroOrMoreExpr{
-				pos: position{line: 212, col: 19, offset: 7125},
+				pos: position{line: 458, col: 19, offset: 15152},
 				expr: &choiceExpr{
-					pos: position{line: 212, col: 20, offset: 7126},
+					pos: position{line: 458, col: 20, offset: 15153},
 					alternatives: []any{
 						&ruleRefExpr{
-							pos:  position{line: 212, col: 20, offset: 7126},
+							pos:  position{line: 458, col: 20, offset: 15153},
 							name: "Whitespace",
 						},
 						&ruleRefExpr{
-							pos:  position{line: 212, col: 33, offset: 7139},
+							pos:  position{line: 458, col: 33, offset: 15166},
 							name: "Comment",
 						},
 					},
@@ -1705,18 +2974,18 @@ var g = &grammar{
 		{
 			name:        "__",
 			displayName: "\"whitespace\"",
-			pos:         position{line: 213, col: 1, offset: 7149},
+			pos:         position{line: 459, col: 1, offset: 15176},
 			expr: &oneOrMoreExpr{
-				pos: position{line: 213, col: 20, offset: 7168},
+				pos: position{line: 459, col: 20, offset: 15195},
 				expr: &choiceExpr{
-					pos: position{line: 213, col: 21, offset: 7169},
+					pos: position{line: 459, col: 21, offset: 15196},
 					alternatives: []any{
 						&ruleRefExpr{
-							pos:  position{line: 213, col: 21, offset: 7169},
+							pos:  position{line: 459, col: 21, offset: 15196},
 							name: "Whitespace",
 						},
 						&ruleRefExpr{
-							pos:  position{line: 213, col: 34, offset: 7182},
+							pos:  position{line: 459, col: 34, offset: 15209},
 							name: "Comment",
 						},
 					},
@@ -1725,9 +2994,9 @@ var g = &grammar{
 		},
 		{
 			name: "Whitespace",
-			pos:  position{line: 214, col: 1, offset: 7192},
+			pos:  position{line: 460, col: 1, offset: 15219},
 			expr: &charClassMatcher{
-				pos:        position{line: 214, col: 15, offset: 7206},
+				pos:        position{line: 460, col: 15, offset: 15233},
 				val:        "[ \\t\\r\\n]",
 				chars:      []rune{' ', '\t', '\r', '\n'},
 				ignoreCase: false,
@@ -1736,35 +3005,35 @@ var g = &grammar{
 		},
 		{
 			name: "Comment",
-			pos:  position{line: 215, col: 1, offset: 7216},
+			pos:  position{line: 461, col: 1, offset: 15243},
 			expr: &actionExpr{
-				pos: position{line: 215, col: 12, offset: 7227},
+				pos: position{line: 461, col: 12, offset: 15254},
 				run: (*parser).callonComment1,
 				expr: &seqExpr{
-					pos: position{line: 215, col: 12, offset: 7227},
+					pos: position{line: 461, col: 12, offset: 15254},
 					exprs: []any{
 						&litMatcher{
-							pos:        position{line: 215, col: 12, offset: 7227},
+							pos:        position{line: 461, col: 12, offset: 15254},
 							val:        "//",
 							ignoreCase: false,
 							want:       "\"//\"",
 						},
 						&zeroOrMoreExpr{
-							pos: position{line: 215, col: 17, offset: 7232},
+							pos: position{line: 461, col: 17, offset: 15259},
 							expr: &seqExpr{
-								pos: position{line: 215, col: 18, offset: 7233},
+								pos: position{line: 461, col: 18, offset: 15260},
 								exprs: []any{
 									&notExpr{
-										pos: position{line: 215, col: 18, offset: 7233},
+										pos: position{line: 461, col: 18, offset: 15260},
 										expr: &litMatcher{
-											pos:        position{line: 215, col: 19, offset: 7234},
+											pos:        position{line: 461, col: 19, offset: 15261},
 											val:        "\n",
 											ignoreCase: false,
 											want:       "\"\\n\"",
 										},
 									},
 									&anyMatcher{
-										line: 215, col: 24, offset: 7239,
+										line: 461, col: 24, offset: 15266,
 									},
 								},
 							},
@@ -1775,63 +3044,63 @@ var g = &grammar{
 		},
 		{
 			name: "TargetAttribute",
-			pos:  position{line: 221, col: 1, offset: 7469},
+			pos:  position{line: 467, col: 1, offset: 15496},
 			expr: &actionExpr{
-				pos: position{line: 221, col: 20, offset: 7488},
+				pos: position{line: 467, col: 20, offset: 15515},
 				run: (*parser).callonTargetAttribute1,
 				expr: &seqExpr{
-					pos: position{line: 221, col: 20, offset: 7488},
+					pos: position{line: 467, col: 20, offset: 15515},
 					exprs: []any{
 						&litMatcher{
-							pos:        position{line: 221, col: 20, offset: 7488},
+							pos:        position{line: 467, col: 20, offset: 15515},
 							val:        "@",
 							ignoreCase: false,
 							want:       "\"@\"",
 						},
 						&ruleRefExpr{
-							pos:  position{line: 221, col: 24, offset: 7492},
+							pos:  position{line: 467, col: 24, offset: 15519},
 							name: "_",
 						},
 						&litMatcher{
-							pos:        position{line: 221, col: 26, offset: 7494},
+							pos:        position{line: 467, col: 26, offset: 15521},
 							val:        "target",
 							ignoreCase: false,
 							want:       "\"target\"",
 						},
 						&ruleRefExpr{
-							pos:  position{line: 221, col: 35, offset: 7503},
+							pos:  position{line: 467, col: 35, offset: 15530},
 							name: "_",
 						},
 						&litMatcher{
-							pos:        position{line: 221, col: 37, offset: 7505},
+							pos:        position{line: 467, col: 37, offset: 15532},
 							val:        "(",
 							ignoreCase: false,
 							want:       "\"(\"",
 						},
 						&ruleRefExpr{
-							pos:  position{line: 221, col: 41, offset: 7509},
+							pos:  position{line: 467, col: 41, offset: 15536},
 							name: "_",
 						},
 						&labeledExpr{
-							pos:   position{line: 221, col: 43, offset: 7511},
+							pos:   position{line: 467, col: 43, offset: 15538},
 							label: "args",
 							expr: &ruleRefExpr{
-								pos:  position{line: 221, col: 48, offset: 7516},
+								pos:  position{line: 467, col: 48, offset: 15543},
 								name: "TargetArgs",
 							},
 						},
 						&ruleRefExpr{
-							pos:  position{line: 221, col: 59, offset: 7527},
+							pos:  position{line: 467, col: 59, offset: 15554},
 							name: "_",
 						},
 						&litMatcher{
-							pos:        position{line: 221, col: 61, offset: 7529},
+							pos:        position{line: 467, col: 61, offset: 15556},
 							val:        ")",
 							ignoreCase: false,
 							want:       "\")\"",
 						},
 						&ruleRefExpr{
-							pos:  position{line: 221, col: 65, offset: 7533},
+							pos:  position{line: 467, col: 65, offset: 15560},
 							name: "_",
 						},
 					},
@@ -1840,24 +3109,24 @@ var g = &grammar{
 		},
 		{
 			name: "TargetArgs",
-			pos:  position{line: 225, col: 1, offset: 7558},
+			pos:  position{line: 473, col: 1, offset: 15640},
 			expr: &actionExpr{
-				pos: position{line: 225, col: 15, offset: 7572},
+				pos: position{line: 473, col: 15, offset: 15654},
 				run: (*parser).callonTargetArgs1,
 				expr: &labeledExpr{
-					pos:   position{line: 225, col: 15, offset: 7572},
+					pos:   position{line: 473, col: 15, offset: 15654},
 					label: "target",
 					expr: &choiceExpr{
-						pos: position{line: 225, col: 23, offset: 7580},
+						pos: position{line: 473, col: 23, offset: 15662},
 						alternatives: []any{
 							&litMatcher{
-								pos:        position{line: 225, col: 23, offset: 7580},
+								pos:        position{line: 473, col: 23, offset: 15662},
 								val:        "erlang",
 								ignoreCase: false,
 								want:       "\"erlang\"",
 							},
 							&litMatcher{
-								pos:        position{line: 225, col: 34, offset: 7591},
+								pos:        position{line: 473, col: 34, offset: 15673},
 								val:        "javascript",
 								ignoreCase: false,
 								want:       "\"javascript\"",
@@ -1869,70 +3138,70 @@ var g = &grammar{
 		},
 		{
 			name: "Import",
-			pos:  position{line: 231, col: 1, offset: 7686},
+			pos:  position{line: 479, col: 1, offset: 15768},
 			expr: &actionExpr{
-				pos: position{line: 231, col: 11, offset: 7696},
+				pos: position{line: 479, col: 11, offset: 15778},
 				run: (*parser).callonImport1,
 				expr: &seqExpr{
-					pos: position{line: 231, col: 11, offset: 7696},
+					pos: position{line: 479, col: 11, offset: 15778},
 					exprs: []any{
 						&labeledExpr{
-							pos:   position{line: 231, col: 11, offset: 7696},
+							pos:   position{line: 479, col: 11, offset: 15778},
 							label: "targetAttribute",
 							expr: &zeroOrOneExpr{
-								pos: position{line: 231, col: 27, offset: 7712},
+								pos: position{line: 479, col: 27, offset: 15794},
 								expr: &ruleRefExpr{
-									pos:  position{line: 231, col: 27, offset: 7712},
+									pos:  position{line: 479, col: 27, offset: 15794},
 									name: "TargetAttribute",
 								},
 							},
 						},
 						&ruleRefExpr{
-							pos:  position{line: 231, col: 44, offset: 7729},
+							pos:  position{line: 479, col: 44, offset: 15811},
 							name: "_",
 						},
 						&litMatcher{
-							pos:        position{line: 231, col: 46, offset: 7731},
+							pos:        position{line: 479, col: 46, offset: 15813},
 							val:        "import",
 							ignoreCase: false,
 							want:       "\"import\"",
 						},
 						&ruleRefExpr{
-							pos:  position{line: 231, col: 55, offset: 7740},
+							pos:  position{line: 479, col: 55, offset: 15822},
 							name: "__",
 						},
 						&labeledExpr{
-							pos:   position{line: 231, col: 58, offset: 7743},
+							pos:   position{line: 479, col: 58, offset: 15825},
 							label: "mod",
 							expr: &ruleRefExpr{
-								pos:  position{line: 231, col: 62, offset: 7747},
+								pos:  position{line: 479, col: 62, offset: 15829},
 								name: "Module",
 							},
 						},
 						&labeledExpr{
-							pos:   position{line: 231, col: 69, offset: 7754},
+							pos:   position{line: 479, col: 69, offset: 15836},
 							label: "unqual",
 							expr: &zeroOrOneExpr{
-								pos: position{line: 231, col: 76, offset: 7761},
+								pos: position{line: 479, col: 76, offset: 15843},
 								expr: &seqExpr{
-									pos: position{line: 231, col: 77, offset: 7762},
+									pos: position{line: 479, col: 77, offset: 15844},
 									exprs: []any{
 										&ruleRefExpr{
-											pos:  position{line: 231, col: 77, offset: 7762},
+											pos:  position{line: 479, col: 77, offset: 15844},
 											name: "_",
 										},
 										&litMatcher{
-											pos:        position{line: 231, col: 79, offset: 7764},
+											pos:        position{line: 479, col: 79, offset: 15846},
 											val:        ".",
 											ignoreCase: false,
 											want:       "\".\"",
 										},
 										&ruleRefExpr{
-											pos:  position{line: 231, col: 83, offset: 7768},
+											pos:  position{line: 479, col: 83, offset: 15850},
 											name: "_",
 										},
 										&ruleRefExpr{
-											pos:  position{line: 231, col: 85, offset: 7770},
+											pos:  position{line: 479, col: 85, offset: 15852},
 											name: "UnqualifiedImports",
 										},
 									},
@@ -1940,29 +3209,29 @@ var g = &grammar{
 							},
 						},
 						&labeledExpr{
-							pos:   position{line: 231, col: 106, offset: 7791},
+							pos:   position{line: 479, col: 106, offset: 15873},
 							label: "alias",
 							expr: &zeroOrOneExpr{
-								pos: position{line: 231, col: 112, offset: 7797},
+								pos: position{line: 479, col: 112, offset: 15879},
 								expr: &seqExpr{
-									pos: position{line: 231, col: 113, offset: 7798},
+									pos: position{line: 479, col: 113, offset: 15880},
 									exprs: []any{
 										&ruleRefExpr{
-											pos:  position{line: 231, col: 113, offset: 7798},
+											pos:  position{line: 479, col: 113, offset: 15880},
 											name: "_",
 										},
 										&litMatcher{
-											pos:        position{line: 231, col: 115, offset: 7800},
+											pos:        position{line: 479, col: 115, offset: 15882},
 											val:        "as",
 											ignoreCase: false,
 											want:       "\"as\"",
 										},
 										&ruleRefExpr{
-											pos:  position{line: 231, col: 120, offset: 7805},
+											pos:  position{line: 479, col: 120, offset: 15887},
 											name: "__",
 										},
 										&ruleRefExpr{
-											pos:  position{line: 231, col: 123, offset: 7808},
+											pos:  position{line: 479, col: 123, offset: 15890},
 											name: "Identifier",
 										},
 									},
@@ -1975,38 +3244,38 @@ var g = &grammar{
 		},
 		{
 			name: "Module",
-			pos:  position{line: 245, col: 1, offset: 8160},
+			pos:  position{line: 493, col: 1, offset: 16258},
 			expr: &actionExpr{
-				pos: position{line: 245, col: 11, offset: 8170},
+				pos: position{line: 493, col: 11, offset: 16268},
 				run: (*parser).callonModule1,
 				expr: &seqExpr{
-					pos: position{line: 245, col: 11, offset: 8170},
+					pos: position{line: 493, col: 11, offset: 16268},
 					exprs: []any{
 						&ruleRefExpr{
-							pos:  position{line: 245, col: 11, offset: 8170},
+							pos:  position{line: 493, col: 11, offset: 16268},
 							name: "Name",
 						},
 						&zeroOrMoreExpr{
-							pos: position{line: 245, col: 16, offset: 8175},
+							pos: position{line: 493, col: 16, offset: 16273},
 							expr: &seqExpr{
-								pos: position{line: 245, col: 17, offset: 8176},
+								pos: position{line: 493, col: 17, offset: 16274},
 								exprs: []any{
 									&ruleRefExpr{
-										pos:  position{line: 245, col: 17, offset: 8176},
+										pos:  position{line: 493, col: 17, offset: 16274},
 										name: "_",
 									},
 									&litMatcher{
-										pos:        position{line: 245, col: 19, offset: 8178},
+										pos:        position{line: 493, col: 19, offset: 16276},
 										val:        "/",
 										ignoreCase: false,
 										want:       "\"/\"",
 									},
 									&ruleRefExpr{
-										pos:  position{line: 245, col: 23, offset: 8182},
+										pos:  position{line: 493, col: 23, offset: 16280},
 										name: "_",
 									},
 									&ruleRefExpr{
-										pos:  position{line: 245, col: 25, offset: 8184},
+										pos:  position{line: 493, col: 25, offset: 16282},
 										name: "Name",
 									},
 								},
@@ -2018,40 +3287,40 @@ var g = &grammar{
 		},
 		{
 			name: "UnqualifiedImports",
-			pos:  position{line: 250, col: 1, offset: 8302},
+			pos:  position{line: 498, col: 1, offset: 16400},
 			expr: &actionExpr{
-				pos: position{line: 250, col: 23, offset: 8324},
+				pos: position{line: 498, col: 23, offset: 16422},
 				run: (*parser).callonUnqualifiedImports1,
 				expr: &seqExpr{
-					pos: position{line: 250, col: 23, offset: 8324},
+					pos: position{line: 498, col: 23, offset: 16422},
 					exprs: []any{
 						&litMatcher{
-							pos:        position{line: 250, col: 23, offset: 8324},
+							pos:        position{line: 498, col: 23, offset: 16422},
 							val:        "{",
 							ignoreCase: false,
 							want:       "\"{\"",
 						},
 						&ruleRefExpr{
-							pos:  position{line: 250, col: 27, offset: 8328},
+							pos:  position{line: 498, col: 27, offset: 16426},
 							name: "_",
 						},
 						&labeledExpr{
-							pos:   position{line: 250, col: 29, offset: 8330},
+							pos:   position{line: 498, col: 29, offset: 16428},
 							label: "items",
 							expr: &zeroOrOneExpr{
-								pos: position{line: 250, col: 35, offset: 8336},
+								pos: position{line: 498, col: 35, offset: 16434},
 								expr: &ruleRefExpr{
-									pos:  position{line: 250, col: 35, offset: 8336},
+									pos:  position{line: 498, col: 35, offset: 16434},
 									name: "UnqualifiedImportList",
 								},
 							},
 						},
 						&ruleRefExpr{
-							pos:  position{line: 250, col: 58, offset: 8359},
+							pos:  position{line: 498, col: 58, offset: 16457},
 							name: "_",
 						},
 						&litMatcher{
-							pos:        position{line: 250, col: 60, offset: 8361},
+							pos:        position{line: 498, col: 60, offset: 16459},
 							val:        "}",
 							ignoreCase: false,
 							want:       "\"}\"",
@@ -2062,45 +3331,45 @@ var g = &grammar{
 		},
 		{
 			name: "UnqualifiedImportList",
-			pos:  position{line: 258, col: 1, offset: 8535},
+			pos:  position{line: 506, col: 1, offset: 16633},
 			expr: &actionExpr{
-				pos: position{line: 258, col: 26, offset: 8560},
+				pos: position{line: 506, col: 26, offset: 16658},
 				run: (*parser).callonUnqualifiedImportList1,
 				expr: &seqExpr{
-					pos: position{line: 258, col: 26, offset: 8560},
+					pos: position{line: 506, col: 26, offset: 16658},
 					exprs: []any{
 						&labeledExpr{
-							pos:   position{line: 258, col: 26, offset: 8560},
+							pos:   position{line: 506, col: 26, offset: 16658},
 							label: "first",
 							expr: &ruleRefExpr{
-								pos:  position{line: 258, col: 32, offset: 8566},
+								pos:  position{line: 506, col: 32, offset: 16664},
 								name: "UnqualifiedImport",
 							},
 						},
 						&labeledExpr{
-							pos:   position{line: 258, col: 50, offset: 8584},
+							pos:   position{line: 506, col: 50, offset: 16682},
 							label: "rest",
 							expr: &zeroOrMoreExpr{
-								pos: position{line: 258, col: 55, offset: 8589},
+								pos: position{line: 506, col: 55, offset: 16687},
 								expr: &seqExpr{
-									pos: position{line: 258, col: 56, offset: 8590},
+									pos: position{line: 506, col: 56, offset: 16688},
 									exprs: []any{
 										&ruleRefExpr{
-											pos:  position{line: 258, col: 56, offset: 8590},
+											pos:  position{line: 506, col: 56, offset: 16688},
 											name: "_",
 										},
 										&litMatcher{
-											pos:        position{line: 258, col: 58, offset: 8592},
+											pos:        position{line: 506, col: 58, offset: 16690},
 											val:        ",",
 											ignoreCase: false,
 											want:       "\",\"",
 										},
 										&ruleRefExpr{
-											pos:  position{line: 258, col: 62, offset: 8596},
+											pos:  position{line: 506, col: 62, offset: 16694},
 											name: "_",
 										},
 										&ruleRefExpr{
-											pos:  position{line: 258, col: 64, offset: 8598},
+											pos:  position{line: 506, col: 64, offset: 16696},
 											name: "UnqualifiedImport",
 										},
 									},
@@ -2108,13 +3377,13 @@ var g = &grammar{
 							},
 						},
 						&ruleRefExpr{
-							pos:  position{line: 258, col: 84, offset: 8618},
+							pos:  position{line: 506, col: 84, offset: 16716},
 							name: "_",
 						},
 						&zeroOrOneExpr{
-							pos: position{line: 258, col: 86, offset: 8620},
+							pos: position{line: 506, col: 86, offset: 16718},
 							expr: &litMatcher{
-								pos:        position{line: 258, col: 86, offset: 8620},
+								pos:        position{line: 506, col: 86, offset: 16718},
 								val:        ",",
 								ignoreCase: false,
 								want:       "\",\"",
@@ -2126,29 +3395,29 @@ var g = &grammar{
 		},
 		{
 			name: "UnqualifiedImport",
-			pos:  position{line: 272, col: 1, offset: 9072},
+			pos:  position{line: 520, col: 1, offset: 17170},
 			expr: &actionExpr{
-				pos: position{line: 272, col: 22, offset: 9093},
+				pos: position{line: 520, col: 22, offset: 17191},
 				run: (*parser).callonUnqualifiedImport1,
 				expr: &seqExpr{
-					pos: position{line: 272, col: 22, offset: 9093},
+					pos: position{line: 520, col: 22, offset: 17191},
 					exprs: []any{
 						&labeledExpr{
-							pos:   position{line: 272, col: 22, offset: 9093},
+							pos:   position{line: 520, col: 22, offset: 17191},
 							label: "itemType",
 							expr: &zeroOrOneExpr{
-								pos: position{line: 272, col: 31, offset: 9102},
+								pos: position{line: 520, col: 31, offset: 17200},
 								expr: &seqExpr{
-									pos: position{line: 272, col: 32, offset: 9103},
+									pos: position{line: 520, col: 32, offset: 17201},
 									exprs: []any{
 										&litMatcher{
-											pos:        position{line: 272, col: 32, offset: 9103},
+											pos:        position{line: 520, col: 32, offset: 17201},
 											val:        "type",
 											ignoreCase: false,
 											want:       "\"type\"",
 										},
 										&ruleRefExpr{
-											pos:  position{line: 272, col: 39, offset: 9110},
+											pos:  position{line: 520, col: 39, offset: 17208},
 											name: "__",
 										},
 									},
@@ -2156,53 +3425,53 @@ var g = &grammar{
 							},
 						},
 						&labeledExpr{
-							pos:   position{line: 272, col: 44, offset: 9115},
+							pos:   position{line: 520, col: 44, offset: 17213},
 							label: "name",
 							expr: &choiceExpr{
-								pos: position{line: 272, col: 50, offset: 9121},
+								pos: position{line: 520, col: 50, offset: 17219},
 								alternatives: []any{
 									&ruleRefExpr{
-										pos:  position{line: 272, col: 50, offset: 9121},
+										pos:  position{line: 520, col: 50, offset: 17219},
 										name: "UpName",
 									},
 									&ruleRefExpr{
-										pos:  position{line: 272, col: 59, offset: 9130},
+										pos:  position{line: 520, col: 59, offset: 17228},
 										name: "Name",
 									},
 								},
 							},
 						},
 						&labeledExpr{
-							pos:   position{line: 272, col: 65, offset: 9136},
+							pos:   position{line: 520, col: 65, offset: 17234},
 							label: "alias",
 							expr: &zeroOrOneExpr{
-								pos: position{line: 272, col: 71, offset: 9142},
+								pos: position{line: 520, col: 71, offset: 17240},
 								expr: &seqExpr{
-									pos: position{line: 272, col: 72, offset: 9143},
+									pos: position{line: 520, col: 72, offset: 17241},
 									exprs: []any{
 										&ruleRefExpr{
-											pos:  position{line: 272, col: 72, offset: 9143},
+											pos:  position{line: 520, col: 72, offset: 17241},
 											name: "_",
 										},
 										&litMatcher{
-											pos:        position{line: 272, col: 74, offset: 9145},
+											pos:        position{line: 520, col: 74, offset: 17243},
 											val:        "as",
 											ignoreCase: false,
 											want:       "\"as\"",
 										},
 										&ruleRefExpr{
-											pos:  position{line: 272, col: 79, offset: 9150},
+											pos:  position{line: 520, col: 79, offset: 17248},
 											name: "__",
 										},
 										&choiceExpr{
-											pos: position{line: 272, col: 83, offset: 9154},
+											pos: position{line: 520, col: 83, offset: 17252},
 											alternatives: []any{
 												&ruleRefExpr{
-													pos:  position{line: 272, col: 83, offset: 9154},
+													pos:  position{line: 520, col: 83, offset: 17252},
 													name: "UpName",
 												},
 												&ruleRefExpr{
-													pos:  position{line: 272, col: 92, offset: 9163},
+													pos:  position{line: 520, col: 92, offset: 17261},
 													name: "Name",
 												},
 											},
@@ -2217,22 +3486,22 @@ var g = &grammar{
 		},
 		{
 			name: "Name",
-			pos:  position{line: 288, col: 1, offset: 9547},
+			pos:  position{line: 536, col: 1, offset: 17661},
 			expr: &actionExpr{
-				pos: position{line: 288, col: 16, offset: 9562},
+				pos: position{line: 536, col: 16, offset: 17676},
 				run: (*parser).callonName1,
 				expr: &seqExpr{
-					pos: position{line: 288, col: 16, offset: 9562},
+					pos: position{line: 536, col: 16, offset: 17676},
 					exprs: []any{
 						&notExpr{
-							pos: position{line: 288, col: 16, offset: 9562},
+							pos: position{line: 536, col: 16, offset: 17676},
 							expr: &ruleRefExpr{
-								pos:  position{line: 288, col: 17, offset: 9563},
+								pos:  position{line: 536, col: 17, offset: 17677},
 								name: "KEYWORD",
 							},
 						},
 						&charClassMatcher{
-							pos:        position{line: 288, col: 25, offset: 9571},
+							pos:        position{line: 536, col: 25, offset: 17685},
 							val:        "[a-z_]",
 							chars:      []rune{'_'},
 							ranges:     []rune{'a', 'z'},
@@ -2240,9 +3509,9 @@ var g = &grammar{
 							inverted:   false,
 						},
 						&zeroOrMoreExpr{
-							pos: position{line: 288, col: 32, offset: 9578},
+							pos: position{line: 536, col: 32, offset: 17692},
 							expr: &charClassMatcher{
-								pos:        position{line: 288, col: 32, offset: 9578},
+								pos:        position{line: 536, col: 32, offset: 17692},
 								val:        "[a-z0-9_]",
 								chars:      []rune{'_'},
 								ranges:     []rune{'a', 'z', '0', '9'},
@@ -2256,24 +3525,24 @@ var g = &grammar{
 		},
 		{
 			name: "UpName",
-			pos:  position{line: 289, col: 1, offset: 9620},
+			pos:  position{line: 537, col: 1, offset: 17734},
 			expr: &actionExpr{
-				pos: position{line: 289, col: 16, offset: 9635},
+				pos: position{line: 537, col: 16, offset: 17749},
 				run: (*parser).callonUpName1,
 				expr: &seqExpr{
-					pos: position{line: 289, col: 16, offset: 9635},
+					pos: position{line: 537, col: 16, offset: 17749},
 					exprs: []any{
 						&charClassMatcher{
-							pos:        position{line: 289, col: 16, offset: 9635},
+							pos:        position{line: 537, col: 16, offset: 17749},
 							val:        "[A-Z]",
 							ranges:     []rune{'A', 'Z'},
 							ignoreCase: false,
 							inverted:   false,
 						},
 						&zeroOrMoreExpr{
-							pos: position{line: 289, col: 22, offset: 9641},
+							pos: position{line: 537, col: 22, offset: 17755},
 							expr: &charClassMatcher{
-								pos:        position{line: 289, col: 22, offset: 9641},
+								pos:        position{line: 537, col: 22, offset: 17755},
 								val:        "[a-zA-Z0-9]",
 								ranges:     []rune{'a', 'z', 'A', 'Z', '0', '9'},
 								ignoreCase: false,
@@ -2286,20 +3555,20 @@ var g = &grammar{
 		},
 		{
 			name: "DiscardName",
-			pos:  position{line: 290, col: 1, offset: 9685},
+			pos:  position{line: 538, col: 1, offset: 17799},
 			expr: &seqExpr{
-				pos: position{line: 290, col: 16, offset: 9700},
+				pos: position{line: 538, col: 16, offset: 17814},
 				exprs: []any{
 					&litMatcher{
-						pos:        position{line: 290, col: 16, offset: 9700},
+						pos:        position{line: 538, col: 16, offset: 17814},
 						val:        "_",
 						ignoreCase: false,
 						want:       "\"_\"",
 					},
 					&zeroOrMoreExpr{
-						pos: position{line: 290, col: 20, offset: 9704},
+						pos: position{line: 538, col: 20, offset: 17818},
 						expr: &charClassMatcher{
-							pos:        position{line: 290, col: 20, offset: 9704},
+							pos:        position{line: 538, col: 20, offset: 17818},
 							val:        "[a-z0-9_]",
 							chars:      []rune{'_'},
 							ranges:     []rune{'a', 'z', '0', '9'},
@@ -2312,63 +3581,63 @@ var g = &grammar{
 		},
 		{
 			name: "KEYWORD",
-			pos:  position{line: 292, col: 1, offset: 9716},
+			pos:  position{line: 540, col: 1, offset: 17830},
 			expr: &seqExpr{
-				pos: position{line: 292, col: 12, offset: 9727},
+				pos: position{line: 540, col: 12, offset: 17841},
 				exprs: []any{
 					&choiceExpr{
-						pos: position{line: 292, col: 13, offset: 9728},
+						pos: position{line: 540, col: 13, offset: 17842},
 						alternatives: []any{
 							&litMatcher{
-								pos:        position{line: 292, col: 13, offset: 9728},
+								pos:        position{line: 540, col: 13, offset: 17842},
 								val:        "as",
 								ignoreCase: false,
 								want:       "\"as\"",
 							},
 							&litMatcher{
-								pos:        position{line: 292, col: 20, offset: 9735},
+								pos:        position{line: 540, col: 20, offset: 17849},
 								val:        "case",
 								ignoreCase: false,
 								want:       "\"case\"",
 							},
 							&litMatcher{
-								pos:        position{line: 292, col: 29, offset: 9744},
+								pos:        position{line: 540, col: 29, offset: 17858},
 								val:        "const",
 								ignoreCase: false,
 								want:       "\"const\"",
 							},
 							&litMatcher{
-								pos:        position{line: 292, col: 39, offset: 9754},
+								pos:        position{line: 540, col: 39, offset: 17868},
 								val:        "if",
 								ignoreCase: false,
 								want:       "\"if\"",
 							},
 							&litMatcher{
-								pos:        position{line: 292, col: 46, offset: 9761},
+								pos:        position{line: 540, col: 46, offset: 17875},
 								val:        "import",
 								ignoreCase: false,
 								want:       "\"import\"",
 							},
 							&litMatcher{
-								pos:        position{line: 292, col: 57, offset: 9772},
+								pos:        position{line: 540, col: 57, offset: 17886},
 								val:        "let",
 								ignoreCase: false,
 								want:       "\"let\"",
 							},
 							&litMatcher{
-								pos:        position{line: 292, col: 65, offset: 9780},
+								pos:        position{line: 540, col: 65, offset: 17894},
 								val:        "pub",
 								ignoreCase: false,
 								want:       "\"pub\"",
 							},
 							&litMatcher{
-								pos:        position{line: 292, col: 73, offset: 9788},
+								pos:        position{line: 540, col: 73, offset: 17902},
 								val:        "type",
 								ignoreCase: false,
 								want:       "\"type\"",
 							},
 							&litMatcher{
-								pos:        position{line: 292, col: 82, offset: 9797},
+								pos:        position{line: 540, col: 82, offset: 17911},
 								val:        "use",
 								ignoreCase: false,
 								want:       "\"use\"",
@@ -2376,9 +3645,9 @@ var g = &grammar{
 						},
 					},
 					&notExpr{
-						pos: position{line: 292, col: 89, offset: 9804},
+						pos: position{line: 540, col: 89, offset: 17918},
 						expr: &charClassMatcher{
-							pos:        position{line: 292, col: 91, offset: 9806},
+							pos:        position{line: 540, col: 91, offset: 17920},
 							val:        "[a-zA-Z0-9_]",
 							chars:      []rune{'_'},
 							ranges:     []rune{'a', 'z', 'A', 'Z', '0', '9'},
@@ -2391,11 +3660,11 @@ var g = &grammar{
 		},
 		{
 			name: "EOF",
-			pos:  position{line: 293, col: 1, offset: 9820},
+			pos:  position{line: 541, col: 1, offset: 17934},
 			expr: &notExpr{
-				pos: position{line: 293, col: 12, offset: 9831},
+				pos: position{line: 541, col: 12, offset: 17945},
 				expr: &anyMatcher{
-					line: 293, col: 13, offset: 9832,
+					line: 541, col: 13, offset: 17946,
 				},
 			},
 		},
@@ -2403,20 +3672,27 @@ var g = &grammar{
 }
 
 func (c *current) onSourceFile1(first, rest any) (any, error) {
-	validStatements := []Node{}
+	file := SourceFile{Span: c.span(), Statements: []Node{}}
+	add := func(s any) {
+		if err, ok := s.(SyntaxError); ok {
+			file.Errors = append(file.Errors, err)
+		} else {
+			file.Statements = append(file.Statements, s.(Node))
+		}
+	}
 	if first != nil {
-		validStatements = append(validStatements, first)
+		add(first)
 	}
 	if rest != nil {
 		for _, s := range rest.([]any) {
 			if s != nil {
 				if val, ok := s.([]any); ok && val[1] != nil {
-					validStatements = append(validStatements, val[1].(Node))
+					add(val[1])
 				}
 			}
 		}
 	}
-	return SourceFile{Statements: validStatements}, nil
+	return file, nil
 }
 
 func (p *parser) callonSourceFile1() (any, error) {
@@ -2425,8 +3701,19 @@ func (p *parser) callonSourceFile1() (any, error) {
 	return p.cur.onSourceFile1(stack["first"], stack["rest"])
 }
 
+func (c *current) onSkippedStatement1() (any, error) {
+	line, _, _ := strings.Cut(strings.TrimSpace(string(c.text)), "\n")
+	return SyntaxError{Span: c.span(), Message: fmt.Sprintf("invalid statement %q", line)}, nil
+}
+
+func (p *parser) callonSkippedStatement1() (any, error) {
+	stack := p.vstack[len(p.vstack)-1]
+	_ = stack
+	return p.cur.onSkippedStatement1()
+}
+
 func (c *current) onFunction1(targetAttrs1, exattrs, targetAttrs2, pub, name, params, returnGroup, body any) (any, error) {
-	f := Function{Name: name.(string)}
+	f := Function{Span: c.span(), Name: name.(string)}
 	if pub != nil {
 		f.Public = true
 	}
@@ -2449,7 +3736,9 @@ func (p *parser) callonFunction1() (any, error) {
 }
 
 func (c *current) onExternalAttribute1(args any) (any, error) {
-	return args, nil
+	attr := args.(ExternalAttribute)
+	attr.Span = c.span()
+	return attr, nil
 }
 
 func (p *parser) callonExternalAttribute1() (any, error) {
@@ -2505,7 +3794,17 @@ func (p *parser) callonFunctionParameters1() (any, error) {
 }
 
 func (c *current) onLambdaFunction1(first, rest, returnGroup any) (any, error) {
-	return nil, nil
+	fn := FunctionType{Span: c.span()}
+	if first != nil {
+		fn.Parameters = append(fn.Parameters, first)
+	}
+	for _, param := range toSlice[[]any](rest) {
+		fn.Parameters = append(fn.Parameters, param[3])
+	}
+	if returnGroup != nil {
+		fn.Return = returnGroup.([]any)[3]
+	}
+	return fn, nil
 }
 
 func (p *parser) callonLambdaFunction1() (any, error) {
@@ -2529,6 +3828,7 @@ func (c *current) onFunctionParameter1(p, typ any) (any, error) {
 	if !ok {
 		return nil, nil
 	}
+	param.Span = c.span()
 	if typ != nil {
 		param.Type = typ.(Type)
 	}
@@ -2562,7 +3862,11 @@ func (p *parser) callonTypeAnnotationContent1() (any, error) {
 }
 
 func (c *current) onTupleType1(first, rest any) (any, error) {
-	return nil, nil
+	tuple := TupleType{Span: c.span(), Elements: []Type{first}}
+	for _, elem := range toSlice[[]any](rest) {
+		tuple.Elements = append(tuple.Elements, elem[3])
+	}
+	return tuple, nil
 }
 
 func (p *parser) callonTupleType1() (any, error) {
@@ -2571,28 +3875,173 @@ func (p *parser) callonTupleType1() (any, error) {
 	return p.cur.onTupleType1(stack["first"], stack["rest"])
 }
 
-func (c *current) onType1(mod, name any) (any, error) {
-	return name.(string), nil
+func (c *current) onType1(mod, name, args any) (any, error) {
+	t := NamedType{Span: c.span(), Name: name.(string)}
+	if mod != nil {
+		t.Module = mod.([]any)[0].(string)
+	}
+	if args != nil {
+		t.Args = args.([]any)[1].([]Type)
+	}
+	return t, nil
 }
 
 func (p *parser) callonType1() (any, error) {
 	stack := p.vstack[len(p.vstack)-1]
 	_ = stack
-	return p.cur.onType1(stack["mod"], stack["name"])
+	return p.cur.onType1(stack["mod"], stack["name"], stack["args"])
 }
 
-func (c *current) onIgnoredType1(pub, opaque any) (any, error) {
-	return nil, nil
+func (c *current) onTypeDefinition1(target, pub, opaque, name, params, body any) (any, error) {
+	t := TypeDefinition{Span: c.span(), Name: name.(string)}
+	if pub != nil {
+		t.Public = true
+	}
+	if opaque != nil {
+		t.Opaque = true
+	}
+	if params != nil {
+		t.Parameters = params.([]any)[1].([]string)
+	}
+	if body != nil {
+		switch b := body.([]any)[1].(type) {
+		case []TypeConstructor:
+			t.Constructors = b
+		case typeAlias:
+			t.Alias = true
+			t.AliasedType = b.aliased
+		}
+	}
+	if target, ok := target.(*TargetAttribute); ok {
+		t.Target = target
+	}
+	return t, nil
+}
+
+func (p *parser) callonTypeDefinition1() (any, error) {
+	stack := p.vstack[len(p.vstack)-1]
+	_ = stack
+	return p.cur.onTypeDefinition1(stack["target"], stack["pub"], stack["opaque"], stack["name"], stack["params"], stack["body"])
+}
+
+func (c *current) onTypeParameters1(first, rest any) (any, error) {
+	params := []string{first.(string)}
+	for _, param := range toSlice[[]any](rest) {
+		params = append(params, param[3].(string))
+	}
+	return params, nil
+}
+
+func (p *parser) callonTypeParameters1() (any, error) {
+	stack := p.vstack[len(p.vstack)-1]
+	_ = stack
+	return p.cur.onTypeParameters1(stack["first"], stack["rest"])
+}
+
+func (c *current) onTypeBody2(ctors any) (any, error) {
+	constructors := []TypeConstructor{}
+	for _, ctor := range toSlice[[]any](ctors) {
+		constructors = append(constructors, ctor[0].(TypeConstructor))
+	}
+	return constructors, nil
+}
+
+func (p *parser) callonTypeBody2() (any, error) {
+	stack := p.vstack[len(p.vstack)-1]
+	_ = stack
+	return p.cur.onTypeBody2(stack["ctors"])
+}
+
+func (c *current) onTypeBody12() (any, error) {
+	return []TypeConstructor{}, nil
+}
+
+func (p *parser) callonTypeBody12() (any, error) {
+	stack := p.vstack[len(p.vstack)-1]
+	_ = stack
+	return p.cur.onTypeBody12()
+}
+
+func (c *current) onTypeAlias1(aliased any) (any, error) {
+	return typeAlias{aliased: aliased}, nil
+}
+
+func (p *parser) callonTypeAlias1() (any, error) {
+	stack := p.vstack[len(p.vstack)-1]
+	_ = stack
+	return p.cur.onTypeAlias1(stack["aliased"])
 }
 
-func (p *parser) callonIgnoredType1() (any, error) {
+func (c *current) onTypeConstructor1(name, fields any) (any, error) {
+	ctor := TypeConstructor{Span: c.span(), Name: name.(string)}
+	if fields != nil {
+		if f := fields.([]any)[3]; f != nil {
+			ctor.Fields = f.([]TypeField)
+		}
+	}
+	return ctor, nil
+}
+
+func (p *parser) callonTypeConstructor1() (any, error) {
+	stack := p.vstack[len(p.vstack)-1]
+	_ = stack
+	return p.cur.onTypeConstructor1(stack["name"], stack["fields"])
+}
+
+func (c *current) onTypeFields1(first, rest any) (any, error) {
+	fields := []TypeField{first.(TypeField)}
+	for _, field := range toSlice[[]any](rest) {
+		fields = append(fields, field[3].(TypeField))
+	}
+	return fields, nil
+}
+
+func (p *parser) callonTypeFields1() (any, error) {
+	stack := p.vstack[len(p.vstack)-1]
+	_ = stack
+	return p.cur.onTypeFields1(stack["first"], stack["rest"])
+}
+
+func (c *current) onTypeField1(label, typ any) (any, error) {
+	field := TypeField{Span: c.span(), Type: typ}
+	if label != nil {
+		field.Label = label.([]any)[0].(string)
+	}
+	return field, nil
+}
+
+func (p *parser) callonTypeField1() (any, error) {
+	stack := p.vstack[len(p.vstack)-1]
+	_ = stack
+	return p.cur.onTypeField1(stack["label"], stack["typ"])
+}
+
+func (c *current) onConstant1(target, pub, name, typ any) (any, error) {
+	constant := Constant{Span: c.span(), Name: name.(string)}
+	if pub != nil {
+		constant.Public = true
+	}
+	if typ != nil {
+		constant.Type = typ.([]any)[3]
+	}
+	if target, ok := target.(*TargetAttribute); ok {
+		constant.Target = target
+	}
+	return constant, nil
+}
+
+func (p *parser) callonConstant1() (any, error) {
 	stack := p.vstack[len(p.vstack)-1]
 	_ = stack
-	return p.cur.onIgnoredType1(stack["pub"], stack["opaque"])
+	return p.cur.onConstant1(stack["target"], stack["pub"], stack["name"], stack["typ"])
 }
 
 func (c *current) onGenericParams1(first, rest any) (any, error) {
-	return nil, nil
+	params := []Type{first}
+	for _, param := range toSlice[[]any](rest) {
+		params = append(params, param[3])
+	}
+	return params, nil
 }
 
 func (p *parser) callonGenericParams1() (any, error) {
@@ -2612,7 +4061,7 @@ func (p *parser) callonGenericParam1() (any, error) {
 }
 
 func (c *current) onLabeledNameParam1(label, name any) (any, error) {
-	return Parameter{Label: label.(string), Name: name.(Identifier).Name}, nil
+	return Parameter{Span: c.span(), Label: label.(string), Name: name.(Identifier).Name}, nil
 }
 
 func (p *parser) callonLabeledNameParam1() (any, error) {
@@ -2622,7 +4071,7 @@ func (p *parser) callonLabeledNameParam1() (any, error) {
 }
 
 func (c *current) onNameParam1(name any) (any, error) {
-	return Parameter{Name: name.(Identifier).Name}, nil
+	return Parameter{Span: c.span(), Name: name.(Identifier).Name}, nil
 }
 
 func (p *parser) callonNameParam1() (any, error) {
@@ -2632,7 +4081,7 @@ func (p *parser) callonNameParam1() (any, error) {
 }
 
 func (c *current) onDiscardParam1(name any) (any, error) {
-	return Parameter{Name: name.(string)}, nil
+	return Parameter{Span: c.span(), Name: name.(Discard).Name}, nil
 }
 
 func (p *parser) callonDiscardParam1() (any, error) {
@@ -2642,7 +4091,7 @@ func (p *parser) callonDiscardParam1() (any, error) {
 }
 
 func (c *current) onIdentifier1() (any, error) {
-	return Identifier{Name: string(c.text)}, nil
+	return Identifier{Span: c.span(), Name: string(c.text)}, nil
 }
 
 func (p *parser) callonIdentifier1() (any, error) {
@@ -2652,7 +4101,7 @@ func (p *parser) callonIdentifier1() (any, error) {
 }
 
 func (c *current) onDiscard1() (any, error) {
-	return Discard{Name: string(c.text)}, nil
+	return Discard{Span: c.span(), Name: string(c.text)}, nil
 }
 
 func (p *parser) callonDiscard1() (any, error) {
@@ -2702,7 +4151,9 @@ func (p *parser) callonComment1() (any, error) {
 }
 
 func (c *current) onTargetAttribute1(args any) (any, error) {
-	return args, nil
+	attr := args.(*TargetAttribute)
+	attr.Span = c.span()
+	return attr, nil
 }
 
 func (p *parser) callonTargetAttribute1() (any, error) {
@@ -2724,7 +4175,7 @@ func (p *parser) callonTargetArgs1() (any, error) {
 }
 
 func (c *current) onImport1(targetAttribute, mod, unqual, alias any) (any, error) {
-	imp := Import{Module: mod.(string)}
+	imp := Import{Span: c.span(), Module: mod.(string)}
 	if alias != nil {
 		imp.Alias = alias.([]any)[3].(Identifier).Name
 	}
@@ -2787,7 +4238,7 @@ func (p *parser) callonUnqualifiedImportList1() (any, error) {
 }
 
 func (c *current) onUnqualifiedImport1(itemType, name, alias any) (any, error) {
-	imp := UnqualifiedImport{Name: name.(string)}
+	imp := UnqualifiedImport{Span: c.span(), Name: name.(string)}
 	if itemType != nil {
 		imp.IsType = true
 	}
@@ -7,6 +7,7 @@ import (
 	"testing"
 
 	"github.com/google/go-cmp/cmp"
+	"github.com/google/go-cmp/cmp/cmpopts"
 )
 
 // Mocking a testParse function, replace this with actual function from parser.go
@@ -19,6 +20,9 @@ func testParse(input string) (SourceFile, error) {
 }
 
 func makeFunctionStmt(public bool, name string, params []Parameter, attrs []ExternalAttribute, ret Type) Node {
+	if name, ok := ret.(string); ok {
+		ret = named(name)
+	}
 	if attrs == nil {
 		attrs = []ExternalAttribute{}
 	}
@@ -85,7 +89,32 @@ func makeParameter(name string, typ interface{}) Parameter {
 	if typ == nil {
 		return Parameter{Label: label, Name: name}
 	}
-	return Parameter{Label: label, Name: name, Type: string(typ.(string))}
+	if name, ok := typ.(string); ok {
+		typ = named(name)
+	}
+	return Parameter{Label: label, Name: name, Type: typ}
+}
+
+// named returns the type of the given name and arguments, e.g. `List(Int)`.
+func named(name string, args ...Type) NamedType {
+	return NamedType{Name: name, Args: args}
+}
+
+// tuple returns the tuple type of the given elements, e.g. `#(Int, String)`.
+func tuple(elems ...Type) TupleType {
+	return TupleType{Elements: elems}
+}
+
+// function returns the function type of the given return and parameter
+// types, e.g. `fn(Int) -> String`.
+func function(ret Type, params ...Type) FunctionType {
+	return FunctionType{Parameters: params, Return: ret}
+}
+
+// qualified returns the type of the given name and arguments from an imported
+// module, e.g. `option.Option(Int)`.
+func qualified(module, name string, args ...Type) NamedType {
+	return NamedType{Module: module, Name: name, Args: args}
 }
 
 func TestParser(t *testing.T) {
@@ -219,11 +248,25 @@ pub fn attach_many(
 `,
 			ast: SourceFile{
 				Statements: []Node{
-					makeFunctionStmt(true, "main", makeParameters("test", nil), nil, nil),
-					makeFunctionStmt(true, "subfield", makeParameters("field_path", "List", "field_decoder", "Decoder", "next", nil), nil, string("Decoder")),
-					makeFunctionStmt(false, "fold_dict", makeParameters("acc", nil, "key", "Dynamic", "value", nil, "key_decoder", nil, "value_decoder", nil), nil, nil),
-					makeFunctionStmt(true, "high_order_function", makeParameters("effect", nil), nil, string("Effect")),
-					makeFunctionStmt(true, "attach_many", makeParameters("id", "String", "path", "List", "handler", nil), nil, string("Nil")),
+					makeFunctionStmt(true, "main", makeParameters("test", function(named("Int"), named("Int"))), nil, nil),
+					makeFunctionStmt(true, "subfield", makeParameters(
+						"field_path", named("List", named("name")),
+						"field_decoder", named("Decoder", named("t")),
+						"next", function(named("Decoder", named("final")), named("t")),
+					), nil, named("Decoder", named("final"))),
+					makeFunctionStmt(false, "fold_dict", makeParameters(
+						"acc", tuple(named("Dict", named("k"), named("v")), qualified("dynamic", "List", qualified("dynamic", "DecodeError"))),
+						"key", qualified("dynamic", "Dynamic"),
+						"value", function(nil),
+						"key_decoder", function(tuple(named("k"), named("List", named("DecodeError"))), named("Dynamic")),
+						"value_decoder", function(tuple(named("v"), named("List", named("DecodeError"))), named("Dynamic")),
+					), nil, tuple(named("Dict", named("k"), named("v")), named("List", named("DecodeError")))),
+					makeFunctionStmt(true, "high_order_function", makeParameters("effect", function(named("Nil"), function(named("Nil"), named("msg")))), nil, named("Effect", named("msg"))),
+					makeFunctionStmt(true, "attach_many", makeParameters(
+						"id", "String",
+						"path", named("List", named("List", named("Event"))),
+						"handler", function(named("Nil"), named("List", named("config"))),
+					), nil, "Nil"),
 				},
 			},
 		},
@@ -269,7 +312,13 @@ pub type Result2(data1, data2, error) {
 type Token =
   List(Nil)
 `, ast: SourceFile{
-				Statements: []Node{},
+				Statements: []Node{
+					TypeDefinition{Public: true, Name: "Result2", Parameters: []string{"data1", "data2", "error"}, Constructors: []TypeConstructor{
+						{Name: "Ok", Fields: []TypeField{{Type: named("data1")}, {Type: named("data2")}}},
+						{Name: "Error", Fields: []TypeField{{Type: named("error")}}},
+					}},
+					TypeDefinition{Name: "Token", Alias: true, AliasedType: named("List", named("Nil")), Target: &TargetAttribute{TargetLang: "erlang"}},
+				},
 			},
 		},
 
@@ -301,12 +350,15 @@ import gleam/int
 			ast: SourceFile{
 				Statements: []Node{
 					makeImportStmt("gleam/dynamic", "type:Dynamic"),
+					TypeDefinition{Public: true, Opaque: true, Name: "Supervisor", Constructors: []TypeConstructor{
+						{Name: "Supervisor", Fields: []TypeField{{Label: "pid", Type: named("Pid")}}},
+					}},
 					makeImportStmt("gleam/int"),
 				},
 			},
 		},
 		{
-			desc: "ignore types",
+			desc: "types",
 			input: `
 
 pub type LogLevel {
@@ -332,6 +384,11 @@ type Key {
 `,
 			ast: SourceFile{
 				Statements: []Node{
+					TypeDefinition{Public: true, Name: "LogLevel", Constructors: []TypeConstructor{
+						{Name: "Emergency"},
+						{Name: "Alert"},
+					}},
+					TypeDefinition{Name: "DoNotLeak"},
 					makeFunctionStmt(true, "configure",
 						makeParameters(), []ExternalAttribute{
 							{TargetLang: "erlang", Module: "logging_ffi", Function: "configure"},
@@ -343,6 +400,9 @@ type Key {
 					makeFunctionStmt(false, "set_primary_config_level", makeParameters("key", "Key", "level", "LogLevel"), []ExternalAttribute{
 						{TargetLang: "erlang", Module: "logger", Function: "set_primary_config"},
 					}, string("DoNotLeak")),
+					TypeDefinition{Name: "Key", Constructors: []TypeConstructor{
+						{Name: "Level"},
+					}},
 				},
 			},
 		},
@@ -472,18 +532,111 @@ pub fn from_list(list: List(#(k, v))) -> Dict(k, v) {
 				}, []ExternalAttribute{
 					{TargetLang: "javascript", Module: "./user_utils.mjs", Function: "log_external"},
 				}, string("Nil")),
+				TypeDefinition{Public: true, Name: "ValidationError", Constructors: []TypeConstructor{
+					{Name: "InvalidName", Fields: []TypeField{{Label: "reason", Type: named("String")}}},
+					{Name: "InvalidAge", Fields: []TypeField{{Label: "reason", Type: named("String")}}},
+				}},
+				TypeDefinition{Public: true, Name: "User", Constructors: []TypeConstructor{
+					{Name: "User", Fields: []TypeField{{Label: "name", Type: named("String")}, {Label: "age", Type: named("Int")}}},
+				}},
 				makeFunctionStmt(true, "create_user",
-					makeParameters("name", "String", "age", "Int"), nil, string("Result")),
+					makeParameters("name", "String", "age", "Int"), nil, named("Result", named("User"), named("ValidationError"))),
 				makeFunctionStmt(false, "is_valid_name",
-					makeParameters("name", "String"), nil, string("Result")),
+					makeParameters("name", "String"), nil, named("Result", named("String"), named("String"))),
 				makeFunctionStmt(false, "is_valid_age",
-					makeParameters("age", "Int"), nil, string("Result")),
+					makeParameters("age", "Int"), nil, named("Result", named("Int"), named("String"))),
 				makeFunctionStmt(true, "greeting",
 					makeParameters("user", "User"), nil, string("String")),
 				makeFunctionStmt(true, "main", nil, nil, nil),
 				makeFunctionStmt(true, "one_of",
-					makeParameters("first", "Decoder", "or alternatives", "List"), nil, string("Decoder")),
-				makeFunctionStmt(true, "from_list", makeParameters("list", "List"), nil, string("Dict")),
+					makeParameters("first", named("Decoder", named("a")), "or alternatives", named("List", named("Decoder", named("a")))), nil, named("Decoder", named("a"))),
+				makeFunctionStmt(true, "from_list", makeParameters("list", named("List", tuple(named("k"), named("v")))), nil, named("Dict", named("k"), named("v"))),
+			}},
+		},
+		{
+			desc: "custom types",
+			input: `
+import gleam/dict.{type Dict}
+import gleam/option
+
+/// A queue of items.
+pub opaque type Queue(a) {
+  Queue(in: List(a), out: List(a))
+}
+
+pub type Event(msg) {
+  @deprecated("Use Message instead")
+  Legacy(msg)
+  Message(
+    payload: msg,
+    meta: Dict(String, String),
+    callback: fn(msg) -> Nil,
+  )
+  Pair(#(Int, String), option.Option(Int))
+  Tick
+}
+
+pub type Cache(k, v) =
+  Dict(k, v)
+
+pub type Callback =
+  fn(Int) -> Nil
+
+pub type Pid
+
+type Skipped {
+  Skipped(..)
+}
+`,
+			ast: SourceFile{Statements: []Node{
+				makeImportStmt("gleam/dict", "type:Dict"),
+				makeImportStmt("gleam/option"),
+				TypeDefinition{Public: true, Opaque: true, Name: "Queue", Parameters: []string{"a"}, Constructors: []TypeConstructor{
+					{Name: "Queue", Fields: []TypeField{{Label: "in", Type: named("List", named("a"))}, {Label: "out", Type: named("List", named("a"))}}},
+				}},
+				TypeDefinition{Public: true, Name: "Event", Parameters: []string{"msg"}, Constructors: []TypeConstructor{
+					{Name: "Legacy", Fields: []TypeField{{Type: named("msg")}}},
+					{Name: "Message", Fields: []TypeField{{Label: "payload", Type: named("msg")}, {Label: "meta", Type: named("Dict", named("String"), named("String"))}, {Label: "callback", Type: function(named("Nil"), named("msg"))}}},
+					{Name: "Pair", Fields: []TypeField{{Type: tuple(named("Int"), named("String"))}, {Type: qualified("option", "Option", named("Int"))}}},
+					{Name: "Tick"},
+				}},
+				TypeDefinition{Public: true, Name: "Cache", Parameters: []string{"k", "v"}, Alias: true, AliasedType: named("Dict", named("k"), named("v"))},
+				TypeDefinition{Public: true, Name: "Callback", Alias: true, AliasedType: function(named("Nil"), named("Int"))},
+				TypeDefinition{Public: true, Name: "Pid"},
+				TypeDefinition{Name: "Skipped", Constructors: []TypeConstructor{}},
+			}},
+		},
+		{
+			desc: "constants",
+			input: `
+import gleam/int
+
+pub const answer: Int = 42
+
+const greeting = "import not_a_module {"
+
+@target(erlang)
+pub const names: List(String) = [
+  "a",
+  "b",
+]
+
+const point = Point(
+  x: 1,
+  y: int.absolute_value(-2),
+)
+
+pub fn main() {
+  Nil
+}
+`,
+			ast: SourceFile{Statements: []Node{
+				makeImportStmt("gleam/int"),
+				Constant{Public: true, Name: "answer", Type: named("Int")},
+				Constant{Name: "greeting"},
+				Constant{Public: true, Name: "names", Type: named("List", named("String")), Target: &TargetAttribute{TargetLang: "erlang"}},
+				Constant{Name: "point"},
+				makeFunctionStmt(true, "main", nil, nil, nil),
 			}},
 		},
 	}
@@ -499,8 +652,135 @@ pub fn from_list(list: List(#(k, v))) -> Dict(k, v) {
 
 			t.Fatalf("expected no error, got %v, test line:\n\n\t%v", err, strings.Split(tc.input, "\n")[line-1])
 		}
-		if diff := cmp.Diff(tc.ast, ast); diff != "" {
+		if diff := cmp.Diff(tc.ast, ast, cmpopts.IgnoreTypes(Span{})); diff != "" {
 			t.Fatalf("desc: (%s)\n(-want, +got)=\n%s", tc.desc, diff)
 		}
 	}
 }
+
+func TestParserPositions(t *testing.T) {
+	input := `import gleam/io
+import gleam/option.{type Option, Some}
+
+@target(erlang)
+import gleam/erlang/process
+
+@external(erlang, "logger", "log")
+fn log(level: Int, _msg: String) -> Nil
+
+pub fn main() {
+  io.println("hello")
+}
+`
+	ast, err := testParse(input)
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	pos := func(line, col int) Position {
+		offset := 0
+		for _, l := range strings.Split(input, "\n")[:line-1] {
+			offset += len(l) + 1
+		}
+		return Position{Line: line, Col: col, Offset: offset + col - 1}
+	}
+	span := func(startLine, startCol, endLine, endCol int) Span {
+		return Span{Start: pos(startLine, startCol), End: pos(endLine, endCol)}
+	}
+
+	want := []Node{
+		Import{Span: span(1, 1, 1, 16), Module: "gleam/io"},
+		Import{Span: span(2, 1, 2, 40), Module: "gleam/option", Unqualified: []UnqualifiedImport{
+			{Span: span(2, 22, 2, 33), Name: "Option", IsType: true},
+			{Span: span(2, 35, 2, 39), Name: "Some"},
+		}},
+		Import{Span: span(4, 1, 5, 28), Module: "gleam/erlang/process", Target: &TargetAttribute{
+			Span: span(4, 1, 4, 16), TargetLang: "erlang",
+		}},
+		Function{Span: span(7, 1, 8, 40), Name: "log", Parameters: []Parameter{
+			{Span: span(8, 8, 8, 18), Name: "level", Type: NamedType{Span: span(8, 15, 8, 18), Name: "Int"}},
+			{Span: span(8, 20, 8, 32), Name: "_msg", Type: NamedType{Span: span(8, 26, 8, 32), Name: "String"}},
+		}, ReturnType: NamedType{Span: span(8, 37, 8, 40), Name: "Nil"}, ExternalAttributes: []ExternalAttribute{
+			{Span: span(7, 1, 7, 35), TargetLang: "erlang", Module: "logger", Function: "log"},
+		}},
+		Function{Span: span(10, 1, 12, 2), Public: true, Name: "main", Parameters: []Parameter{}, ExternalAttributes: []ExternalAttribute{}},
+	}
+	if diff := cmp.Diff(want, ast.Statements); diff != "" {
+		t.Fatalf("(-want, +got)=\n%s", diff)
+	}
+}
+
+func TestParserRecovery(t *testing.T) {
+	testCases := []struct {
+		desc       string
+		input      string
+		statements []Node
+		errors     []SyntaxError
+	}{
+		{
+			desc: "unterminated function parameters",
+			input: `import gleam/io
+pub fn broken(a,
+  io.println(
+import gleam/list
+
+pub fn main() {
+  io.println("hello")
+}
+`,
+			statements: []Node{
+				Import{Module: "gleam/io"},
+				Import{Module: "gleam/list"},
+				makeFunctionStmt(true, "main", nil, nil, nil),
+			},
+			errors: []SyntaxError{
+				{Span: Span{Start: Position{Line: 2, Col: 1, Offset: 16}, End: Position{Line: 3, Col: 14, Offset: 46}}, Message: `invalid statement "pub fn broken(a,"`},
+			},
+		},
+		{
+			desc: "incomplete import",
+			input: `import gleam/io
+import
+`,
+			statements: []Node{
+				Import{Module: "gleam/io"},
+			},
+			errors: []SyntaxError{
+				{Span: Span{Start: Position{Line: 2, Col: 1, Offset: 16}, End: Position{Line: 2, Col: 7, Offset: 22}}, Message: `invalid statement "import"`},
+			},
+		},
+		{
+			desc: "several broken statements",
+			input: `import gleam/io
+pub fn (x) {
+  x
+}
+import gleam/string
+const
+`,
+			statements: []Node{
+				Import{Module: "gleam/io"},
+				Import{Module: "gleam/string"},
+			},
+			errors: []SyntaxError{
+				{Span: Span{Start: Position{Line: 2, Col: 1, Offset: 16}, End: Position{Line: 4, Col: 2, Offset: 34}}, Message: `invalid statement "pub fn (x) {"`},
+				{Span: Span{Start: Position{Line: 6, Col: 1, Offset: 55}, End: Position{Line: 6, Col: 6, Offset: 60}}, Message: `invalid statement "const"`},
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.desc, func(t *testing.T) {
+			ast, err := testParse(tc.input)
+			if err != nil {
+				t.Fatalf("expected no error, got %v", err)
+			}
+			if diff := cmp.Diff(tc.statements, ast.Statements, cmpopts.IgnoreTypes(Span{})); diff != "" {
+				t.Errorf("statements (-want, +got)=\n%s", diff)
+			}
+			if diff := cmp.Diff(tc.errors, ast.Errors); diff != "" {
+				t.Errorf("errors (-want, +got)=\n%s", diff)
+			}
+		})
+	}
+}
@@ -2,15 +2,18 @@ package gleam
 
 import (
 	"fmt"
+	"os"
 	"path/filepath"
 	"strings"
 	"testing"
 
 	"github.com/bazelbuild/bazel-gazelle/config"
 	"github.com/bazelbuild/bazel-gazelle/label"
-	"github.com/bazelbuild/bazel-gazelle/repo"
+	"github.com/bazelbuild/bazel-gazelle/language"
+	"github.com/bazelbuild/bazel-gazelle/merger"
 	"github.com/bazelbuild/bazel-gazelle/resolve"
 	"github.com/bazelbuild/bazel-gazelle/rule"
+	"github.com/bazelbuild/bazel-gazelle/walk"
 	bzl "github.com/bazelbuild/buildtools/build"
 
 	"github.com/google/go-cmp/cmp"
@@ -25,8 +28,10 @@ type resolveTestCase struct {
 	desc      string
 	index     []buildFile
 	skipIndex bool
-	old       buildFile
-	want      string
+	// Whether the rules are resolved for an external (Hex) repository.
+	externalRepo bool
+	old          buildFile
+	want         string
 }
 
 type mapResolver map[string]resolve.Resolver
@@ -221,6 +226,616 @@ var testCases = []resolveTestCase{
 							"@hex_gleeunit//gleeunit",
 						],
 					)
+`,
+	},
+	{
+		desc: "import javascript ffi",
+		index: []buildFile{
+			{
+				pkg: "foo/ffi",
+				content: `
+					gleam_js_library(
+						name = "clock_js_ffi",
+						srcs = [
+							"clock.mjs",
+						],
+						visibility = ["//visibility:public"],
+					)
+`,
+			},
+		},
+		old: buildFile{
+			pkg: "foo",
+			content: `
+					gleam_js_library(
+						name = "now_js_ffi",
+						srcs = [
+							"now.mjs",
+						],
+						visibility = ["//visibility:public"],
+					)
+
+					gleam_library(
+						name = "foo",
+						srcs = [
+							"foo.gleam"
+						],
+						visibility = ["//visibility:public"],
+						_gazelle_imports = [
+							"js:foo/ffi/clock.mjs",
+							"js:foo/now.mjs",
+						],
+					)
+	`,
+		},
+		want: `
+					gleam_js_library(
+						name = "now_js_ffi",
+						srcs = [
+							"now.mjs",
+						],
+						visibility = ["//visibility:public"],
+					)
+
+					gleam_library(
+						name = "foo",
+						srcs = [
+							"foo.gleam",
+						],
+						visibility = ["//visibility:public"],
+						deps = [
+							":now_js_ffi",
+							"//foo/ffi:clock_js_ffi",
+						],
+					)
+`,
+	},
+	{
+		desc: "target specific imports",
+		index: []buildFile{
+			{
+				pkg: "",
+				content: `
+					# gazelle:gleam_target both
+`,
+			},
+			{
+				pkg: "foo/ffi",
+				content: `
+					gleam_js_library(
+						name = "clock_js_ffi",
+						srcs = [
+							"clock.mjs",
+						],
+						visibility = ["//visibility:public"],
+					)
+
+					gleam_erl_library(
+						name = "clock_ffi",
+						srcs = [
+							"clock.erl",
+						],
+						visibility = ["//visibility:public"],
+					)
+`,
+			},
+		},
+		old: buildFile{
+			pkg: "foo",
+			content: `
+					gleam_library(
+						name = "foo",
+						srcs = [
+							"foo.gleam"
+						],
+						visibility = ["//visibility:public"],
+						_gazelle_imports = [
+							"gleam/io",
+							"@target(erlang) erl:clock",
+							"@target(javascript) js:foo/ffi/clock.mjs",
+						],
+					)
+
+					gleam_library(
+						name = "bar",
+						srcs = [
+							"bar.gleam"
+						],
+						visibility = ["//visibility:public"],
+						_gazelle_imports = [
+							"@target(javascript) js:foo/ffi/clock.mjs",
+						],
+					)
+	`,
+		},
+		want: `
+					gleam_library(
+						name = "foo",
+						srcs = [
+							"foo.gleam",
+						],
+						visibility = ["//visibility:public"],
+						deps = ["@hex_gleam_stdlib//gleam:io"] + select({
+							"//conditions:default": ["//foo/ffi:clock_ffi"],
+							"@rules_gleam//gleam/target:javascript": ["//foo/ffi:clock_js_ffi"],
+						}),
+					)
+
+					gleam_library(
+						name = "bar",
+						srcs = [
+							"bar.gleam",
+						],
+						visibility = ["//visibility:public"],
+						deps = select({
+							"//conditions:default": [],
+							"@rules_gleam//gleam/target:javascript": ["//foo/ffi:clock_js_ffi"],
+						}),
+					)
+`,
+	},
+	{
+		desc: "gleam_resolve directive",
+		index: []buildFile{
+			{
+				pkg: "",
+				content: `
+					# gazelle:gleam_resolve internal/wrapper //third_party/wrapper:wrapper
+					# gazelle:gleam_resolve erl:vendored_ffi @vendored//src:vendored_ffi
+					# gazelle:gleam_resolve foo/generated :generated
+					# gazelle:gleam_resolve foo/bar/bar //foo/bar:custom
+`,
+			},
+			{
+				pkg: "foo/bar",
+				content: `
+					gleam_library(
+						name = "bar",
+						srcs = [
+							"bar.gleam"
+						],
+					)
+`,
+			},
+		},
+		old: buildFile{
+			pkg: "foo",
+			content: `
+					gleam_library(
+						name = "foo",
+						srcs = [
+							"foo.gleam"
+						],
+						_gazelle_imports = [
+							"erl:vendored_ffi",
+							"foo/bar/bar",
+							"foo/generated",
+							"gleam/io",
+							"internal/wrapper",
+						],
+					)
+	`,
+		},
+		want: `
+					gleam_library(
+						name = "foo",
+						srcs = [
+							"foo.gleam",
+						],
+						deps = [
+							"//:generated",
+							"//foo/bar:custom",
+							"//third_party/wrapper",
+							"@hex_gleam_stdlib//gleam:io",
+							"@vendored//src:vendored_ffi",
+						],
+					)
+`,
+	},
+	{
+		desc: "gleam_otp_version directive",
+		index: []buildFile{
+			{
+				pkg: "",
+				content: `
+					# gazelle:gleam_otp_version 26
+`,
+			},
+			{
+				pkg: "foo/ffi",
+				content: `
+					gleam_erl_library(
+						name = "json_ffi",
+						srcs = [
+							"json.erl",
+						],
+						visibility = ["//visibility:public"],
+					)
+`,
+			},
+		},
+		old: buildFile{
+			pkg: "foo",
+			content: `
+					gleam_library(
+						name = "foo",
+						srcs = [
+							"foo.gleam"
+						],
+						_gazelle_imports = [
+							"erl:json",
+							"erl:lists",
+						],
+					)
+	`,
+		},
+		want: `
+					gleam_library(
+						name = "foo",
+						srcs = [
+							"foo.gleam",
+						],
+						otp_applications = ["stdlib"],
+						deps = ["//foo/ffi:json_ffi"],
+					)
+`,
+	},
+	{
+		desc:         "OTP module of another release in an external repository",
+		externalRepo: true,
+		old: buildFile{
+			pkg: "foo",
+			content: `
+					gleam_library(
+						name = "foo",
+						srcs = [
+							"foo.gleam"
+						],
+						_gazelle_imports = [
+							"erl:zstd",
+						],
+					)
+	`,
+		},
+		want: `
+					gleam_library(
+						name = "foo",
+						srcs = [
+							"foo.gleam",
+						],
+						otp_applications = ["stdlib"],
+					)
+`,
+	},
+	{
+		desc: "otp applications",
+		old: buildFile{
+			pkg: "foo",
+			content: `
+					gleam_library(
+						name = "foo",
+						srcs = [
+							"foo.gleam"
+						],
+						otp_applications = ["mnesia"],
+						_gazelle_imports = [
+							"erl:crypto",
+							"erl:erlang",
+							"erl:ssl",
+							"erl:ssl_crl_cache",
+							"gleam/io",
+						],
+					)
+
+					gleam_binary(
+						name = "bar",
+						srcs = [
+							"bar.gleam"
+						],
+						_gazelle_imports = [
+							"erl:httpc",
+						],
+					)
+
+					gleam_test(
+						name = "foo_test",
+						srcs = [
+							"foo_test.gleam"
+						],
+						_gazelle_imports = [
+							"erl:mnesia",
+						],
+					)
+	`,
+		},
+		want: `
+					gleam_library(
+						name = "foo",
+						srcs = [
+							"foo.gleam",
+						],
+						otp_applications = [
+							"crypto",
+							"ssl",
+						],
+						deps = ["@hex_gleam_stdlib//gleam:io"],
+					)
+
+					gleam_binary(
+						name = "bar",
+						srcs = [
+							"bar.gleam",
+						],
+						otp_applications = ["inets"],
+					)
+
+					gleam_test(
+						name = "foo_test",
+						srcs = [
+							"foo_test.gleam",
+						],
+						otp_applications = ["mnesia"],
+					)
+`,
+	},
+	{
+		desc: "erlang module of a dependency",
+		old: buildFile{
+			pkg: "foo",
+			content: `
+					gleam_library(
+						name = "foo",
+						srcs = [
+							"foo.gleam"
+						],
+						_gazelle_imports = [
+							"erl:fl_native",
+							"fl",
+						],
+					)
+	`,
+		},
+		want: `
+					gleam_library(
+						name = "foo",
+						srcs = [
+							"foo.gleam",
+						],
+						deps = [
+							"@hex_fl//:fl",
+							"@hex_fl//native:fl_nif_ffi",
+						],
+					)
+`,
+	},
+	{
+		desc: "elixir modules",
+		index: []buildFile{
+			{
+				pkg: "",
+				content: `
+					# gazelle:gleam_elixir_package jason //third_party/elixir:jason
+					# gazelle:gleam_resolve erl:Elixir.Decimal @decimal//:decimal
+`,
+			},
+		},
+		old: buildFile{
+			pkg: "foo",
+			content: `
+					gleam_library(
+						name = "foo",
+						srcs = [
+							"foo.gleam"
+						],
+						_gazelle_imports = [
+							"erl:Elixir.Decimal",
+							"erl:Elixir.Jason",
+							"erl:Elixir.Plug.Conn",
+							"erl:Elixir.Unknown",
+						],
+					)
+	`,
+		},
+		want: `
+					gleam_library(
+						name = "foo",
+						srcs = [
+							"foo.gleam",
+						],
+						deps = [
+							"//third_party/elixir:jason",
+							"@decimal//:decimal",
+						],
+					)
+`,
+	},
+	{
+		desc: "excluded sources are not indexed",
+		index: []buildFile{
+			{
+				pkg: "",
+				content: `
+					# gazelle:gleam_exclude vendor/**
+`,
+			},
+			{
+				pkg: "vendor/lib",
+				content: `
+					gleam_library(
+						name = "lib",
+						srcs = [
+							"lib.gleam"
+						],
+					)
+`,
+			},
+			{
+				pkg: "app/lib",
+				content: `
+					gleam_library(
+						name = "lib",
+						srcs = [
+							"lib.gleam"
+						],
+					)
+`,
+			},
+		},
+		old: buildFile{
+			pkg: "app",
+			content: `
+					gleam_library(
+						name = "app",
+						srcs = [
+							"app.gleam"
+						],
+						_gazelle_imports = [
+							"app/lib/lib",
+							"vendor/lib/lib",
+						],
+					)
+	`,
+		},
+		want: `
+					gleam_library(
+						name = "app",
+						srcs = [
+							"app.gleam",
+						],
+						deps = ["//app/lib"],
+					)
+`,
+	},
+	{
+		desc: "package generation mode",
+		index: []buildFile{
+			{
+				pkg: "geometry",
+				content: `
+					gleam_library(
+						name = "geometry",
+						srcs = [
+							"point.gleam",
+							"vector.gleam",
+						],
+					)
+`,
+			},
+		},
+		old: buildFile{
+			pkg: "shapes",
+			content: `
+					gleam_library(
+						name = "shapes",
+						srcs = [
+							"circle.gleam",
+							"square.gleam",
+						],
+						_gazelle_imports = [
+							"geometry/point",
+							"geometry/vector",
+							"shapes/circle",
+						],
+					)
+	`,
+		},
+		want: `
+					gleam_library(
+						name = "shapes",
+						srcs = [
+							"circle.gleam",
+							"square.gleam",
+						],
+						deps = ["//geometry"],
+					)
+`,
+	},
+	{
+		desc: "dev dependency imported by a test",
+		old: buildFile{
+			pkg: "app",
+			content: `
+					gleam_test(
+						name = "app_test",
+						srcs = [
+							"app_test.gleam"
+						],
+						_gazelle_imports = [
+							"gleam/io",
+							"qcheck",
+						],
+					)
+	`,
+		},
+		want: `
+					gleam_test(
+						name = "app_test",
+						srcs = [
+							"app_test.gleam",
+						],
+						deps = [
+							"@hex_gleam_stdlib//gleam:io",
+							"@hex_qcheck//:qcheck",
+						],
+					)
+`,
+	},
+	{
+		desc: "dev dependency imported by a library",
+		old: buildFile{
+			pkg: "app",
+			content: `
+					gleam_library(
+						name = "app",
+						srcs = [
+							"app.gleam"
+						],
+						_gazelle_imports = [
+							"gleam/io",
+							"qcheck",
+						],
+					)
+	`,
+		},
+		want: `
+					gleam_library(
+						name = "app",
+						srcs = [
+							"app.gleam",
+						],
+						deps = ["@hex_gleam_stdlib//gleam:io"],
+					)
+`,
+	},
+	{
+		desc: "modules sharing a prefix",
+		old: buildFile{
+			pkg: "app",
+			content: `
+					gleam_library(
+						name = "app",
+						srcs = [
+							"app.gleam"
+						],
+						_gazelle_imports = [
+							"gleam/json",
+							"gleam/json/unknown",
+							"gleam/json_extra",
+						],
+					)
+	`,
+		},
+		want: `
+					gleam_library(
+						name = "app",
+						srcs = [
+							"app.gleam",
+						],
+						deps = [
+							"@hex_gleam_json//gleam:json",
+							"@hex_gleam_json_extra//gleam:json_extra",
+						],
+					)
 `,
 	},
 	{
@@ -315,23 +930,22 @@ var testCases = []resolveTestCase{
 	},
 }
 
-var testRepos = []repo.Repo{
-	repo.Repo{
-		Name:     "hex_gleam_stdlib",
-		GoPrefix: "gleam/io",
-	},
-	repo.Repo{
-		Name:     "hex_gleeunit",
-		GoPrefix: "gleeunit/gleeunit",
-	},
-	repo.Repo{
-		Name:     "hex_gleam_stdlib",
-		GoPrefix: "gleam/int",
-	},
-	repo.Repo{
-		Name:     "hex_fl",
-		GoPrefix: "fl",
-	},
+// testModules returns the modules of the dependencies of the test cases.
+func testModules() *moduleIndex {
+	modules := newModuleIndex()
+	modules.addGleam("hex_gleam_stdlib", "gleam/io")
+	modules.addGleam("hex_gleam_stdlib", "gleam/int")
+	modules.addGleam("hex_gleeunit", "gleeunit/gleeunit")
+	modules.addGleam("hex_gleam_json", "gleam/json")
+	modules.addGleam("hex_gleam_json_extra", "gleam/json_extra")
+	modules.addGleam("hex_fl", "fl")
+	modules.addErlang("hex_fl", "fl_native", label.New("hex_fl", "native", "fl_nif_ffi"))
+	modules.addElixir("hex_jason", "Elixir.Jason")
+	modules.addElixir("hex_plug", "Elixir.Plug.Conn")
+	// Only required by dev-dependencies.
+	modules.addGleam("hex_qcheck", "qcheck")
+	modules.markDevOnly(map[string]bool{"hex_qcheck": true})
+	return modules
 }
 
 func TestResolveGleam(t *testing.T) {
@@ -350,7 +964,8 @@ func TestResolveGleam(t *testing.T) {
 			ix := resolve.NewRuleIndex(mrslv.Resolver, exts...)
 
 			gc := GetGleamConfig(c).clone()
-			gc.repos = testRepos
+			gc.modules = testModules()
+			gc.externalRepo = testCase.externalRepo
 			c.Exts[languageName] = gc
 
 			for _, bf := range testCase.index {
@@ -393,18 +1008,101 @@ func TestResolveGleam(t *testing.T) {
 	}
 }
 
-func convertImportsAttr(r *rule.Rule) []string {
+// TestMergeResolvedAttrs runs each package of mergetestdata through
+// generation, resolution and both merge phases, like "gazelle update" does,
+// and compares the merged BUILD.in with BUILD.want.
+func TestMergeResolvedAttrs(t *testing.T) {
+	testDir := "mergetestdata"
+	c, langs, cexts := testConfig(t, "-build_file_name=BUILD.in", "-repo_root="+testDir)
+
+	mrslv := make(mapResolver)
+	exts := make([]interface{}, 0, len(langs))
+	kinds := make(map[string]rule.KindInfo)
+	var loads []rule.LoadInfo
+	for _, lang := range langs {
+		for kind, info := range lang.Kinds() {
+			mrslv[kind] = lang
+			kinds[kind] = info
+		}
+		exts = append(exts, lang)
+		loads = append(loads, lang.(language.ModuleAwareLanguage).ApparentLoads(func(string) string { return "" })...)
+	}
+	ix := resolve.NewRuleIndex(mrslv.Resolver, exts...)
+
+	type visit struct {
+		c          *config.Config
+		dir, rel   string
+		file       *rule.File
+		empty, gen []*rule.Rule
+		imports    []interface{}
+	}
+	var visits []visit
+	walk.Walk(c, cexts, []string{testDir}, walk.VisitAllUpdateSubdirsMode, func(dir, rel string, c *config.Config, update bool, oldFile *rule.File, subdirs, regularFiles, genFiles []string) {
+		if oldFile == nil {
+			return
+		}
+		v := visit{c: c, dir: dir, rel: rel, file: oldFile}
+		for _, lang := range langs {
+			res := lang.GenerateRules(language.GenerateArgs{
+				Config:       c,
+				Dir:          dir,
+				Rel:          rel,
+				File:         oldFile,
+				Subdirs:      subdirs,
+				RegularFiles: regularFiles,
+				GenFiles:     genFiles,
+				OtherEmpty:   v.empty,
+				OtherGen:     v.gen,
+			})
+			v.empty = append(v.empty, res.Empty...)
+			v.gen = append(v.gen, res.Gen...)
+			v.imports = append(v.imports, res.Imports...)
+		}
+		merger.MergeFile(oldFile, v.empty, v.gen, merger.PreResolve, kinds, nil)
+		for _, r := range v.gen {
+			ix.AddRule(c, r, oldFile)
+		}
+		visits = append(visits, v)
+	})
+	ix.Finish()
+
+	for _, v := range visits {
+		t.Run(v.rel, func(t *testing.T) {
+			for i, r := range v.gen {
+				mrslv.Resolver(r, "").Resolve(v.c, ix, nil, r, v.imports[i], label.New("", v.rel, r.Name()))
+			}
+			merger.MergeFile(v.file, v.empty, v.gen, merger.PostResolve, kinds, nil)
+			merger.FixLoads(v.file, loads)
+			v.file.Sync()
+			got := string(bzl.Format(v.file.File))
+			wantPath := filepath.Join(v.dir, "BUILD.want")
+			wantBytes, err := os.ReadFile(wantPath)
+			if err != nil {
+				t.Fatalf("error reading %s: %v", wantPath, err)
+			}
+			want := strings.ReplaceAll(string(wantBytes), "\r\n", "\n")
+			if diff := cmp.Diff(want, got); diff != "" {
+				t.Errorf("(-want, +got): %s", diff)
+			}
+		})
+	}
+}
+
+func convertImportsAttr(r *rule.Rule) ruleImports {
 	kind := r.Kind()
 	value := r.AttrStrings(config.GazelleImportsKey)
 	r.DelAttr(config.GazelleImportsKey)
 	if _, ok := gleamKinds[kind]; ok {
-		return value
+		// Imports for a single target are written "@target(<target>) <module>",
+		// like GenerateRules writes them, see importAttr.
+		return ruleImports{deps: mapper(value, func(imp string) gleamImport {
+			if rest, ok := strings.CutPrefix(imp, "@target("); ok {
+				target, module, _ := strings.Cut(rest, ") ")
+				return gleamImport{module: module, target: target}
+			}
+			return gleamImport{module: imp}
+		})}
 	} else {
-		return []string{}
+		return ruleImports{}
 	}
 }
-
-func testRemoteCache(knownRepos []repo.Repo) *repo.RemoteCache {
-	rc, _ := repo.NewRemoteCache(knownRepos)
-	return rc
-}
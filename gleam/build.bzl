@@ -297,3 +297,24 @@ COMMON_ATTRS = {
     ),
     "data": attr.label_list(doc = "The data available at runtime", allow_files = True),
 }
+
+OTP_APPLICATIONS_ATTR = attr.string_list(
+    doc = """The OTP applications the modules need started, e.g. ssl or mnesia.
+    Gazelle sets these from the "erl:" imports of OTP modules.
+
+    Binaries and tests list them, and the ones of their dependencies, in their .app manifest.""",
+)
+
+def otp_applications(ctx):
+    """Returns the OTP applications of the rule and of its dependencies.
+
+    Args:
+        ctx: The Bazel rule context object.
+
+    Returns:
+        A depset of OTP application names.
+    """
+    return depset(
+        direct = getattr(ctx.attr, "otp_applications", []),
+        transitive = [dep[GleamErlPackageInfo].otp_applications for dep in ctx.attr.deps],
+    )
@@ -1,5 +1,5 @@
 load("@bazel_skylib//lib:paths.bzl", "paths")
-load("//gleam:build.bzl", "COMMON_ATTRS", "declare_inputs", "declare_lib_files_for_dep", "declare_outputs", "get_env_path", "get_erl_binary", "get_erl_compiler_binaries", "get_erl_compiler_otp_files", "get_gleam_compiler")
+load("//gleam:build.bzl", "COMMON_ATTRS", "OTP_APPLICATIONS_ATTR", "declare_inputs", "declare_lib_files_for_dep", "declare_outputs", "get_env_path", "get_erl_binary", "get_erl_compiler_binaries", "get_erl_compiler_otp_files", "get_gleam_compiler", "otp_applications")
 load("//gleam:provider.bzl", "GLEAM_ARTEFACTS_DIR", "GleamErlPackageInfo")
 
 def _gleam_binary_impl(ctx):
@@ -42,6 +42,7 @@ def _gleam_binary_impl(ctx):
             },
         )
 
+    otp_apps = otp_applications(ctx)
     erl_mod_depset = depset(direct = outputs.erl_mods + [inputs.binary_erl_mod], transitive = [dep[GleamErlPackageInfo].erl_module for dep in ctx.attr.deps])
 
     # Manifest
@@ -50,7 +51,7 @@ def _gleam_binary_impl(ctx):
         output = outputs.beam_app_manifest,
         substitutions = {
             "{PACKAGE}": main_module,
-            "{APPS_COMMA_SEP}": "",
+            "{APPS_COMMA_SEP}": ", ".join(sorted(otp_apps.to_list())),
             "{MODS_COMMA_SEP}": ",\n\t\t\t\t".join([paths.replace_extension(paths.basename(erl_mod.path), "") for erl_mod in erl_mod_depset.to_list()]),
         },
     )
@@ -121,6 +122,7 @@ def _gleam_binary_impl(ctx):
             beam_module = depset(direct = outputs.beam_files, transitive = [dep[GleamErlPackageInfo].beam_module for dep in ctx.attr.deps]),
             gleam_cache = depset(direct = outputs.cache_files, transitive = [dep[GleamErlPackageInfo].gleam_cache for dep in ctx.attr.deps]),
             strip_src_prefix = ctx.attr.strip_src_prefix,
+            otp_applications = otp_apps,
         ),
     ]
 
@@ -140,6 +142,7 @@ gleam_binary = rule(
             doc = "The list of dependent gleam modules.",
             providers = [GleamErlPackageInfo],
         ),
+        otp_applications = OTP_APPLICATIONS_ATTR,
         _main_erl = attr.label(
             default = "//gleam/templates:[[main_module]]@@main.erl",
             allow_single_file = True,
@@ -56,6 +56,7 @@ def _gleam_erl_library_impl(ctx):
             beam_module = depset(direct = outputs.beam_files),
             gleam_cache = depset(direct = outputs.cache_files),
             strip_src_prefix = ctx.attr.strip_src_prefix,
+            otp_applications = depset(),
         ),
     ]
 
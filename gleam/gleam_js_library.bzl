@@ -0,0 +1,47 @@
+# JavaScript library for interoping with JavaScript via
+#
+# @external(javascript, "./ffi.mjs", "function")
+load("//gleam:build.bzl", "COMMON_ATTRS")
+load("//gleam:provider.bzl", "GleamErlPackageInfo", "GleamJsPackageInfo")
+
+def _gleam_js_library_impl(ctx):
+    # Accumulate runfiles.
+    runfiles = ctx.runfiles(files = ctx.files.data + ctx.files.srcs)
+    transitive_runfiles = []
+    for runfiles_attr in (
+        ctx.attr.data,
+    ):
+        for target in runfiles_attr:
+            transitive_runfiles.append(target[DefaultInfo].default_runfiles)
+    runfiles = runfiles.merge_all(transitive_runfiles)
+
+    return [
+        DefaultInfo(files = depset(ctx.files.srcs), runfiles = runfiles),
+        GleamJsPackageInfo(
+            js_module = depset(direct = ctx.files.srcs),
+            strip_src_prefix = ctx.attr.strip_src_prefix,
+        ),
+        # JavaScript externals are ignored by the Erlang target, so there is
+        # nothing to stage for a dependent Gleam package compiled to Erlang.
+        GleamErlPackageInfo(
+            module_names = [],
+            erl_module = depset(),
+            beam_module = depset(),
+            gleam_cache = depset(),
+            strip_src_prefix = ctx.attr.strip_src_prefix,
+            otp_applications = depset(),
+        ),
+    ]
+
+# Provides GleamJsPackageInfo and DefaultInfo that includes the .mjs, .js sources.
+gleam_js_library = rule(
+    implementation = _gleam_js_library_impl,
+    attrs = dict(
+        COMMON_ATTRS,
+        srcs = attr.label_list(
+            doc = "The list of JavaScript FFI files under the current package.",
+            mandatory = True,
+            allow_files = [".mjs", ".js"],
+        ),
+    ),
+)
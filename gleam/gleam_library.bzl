@@ -1,5 +1,5 @@
 load("@bazel_skylib//lib:paths.bzl", "paths")
-load("//gleam:build.bzl", "COMMON_ATTRS", "declare_inputs", "declare_lib_files_for_dep", "declare_outputs", "get_env_path", "get_erl_compiler_binaries", "get_erl_compiler_otp_files", "get_gleam_compiler")
+load("//gleam:build.bzl", "COMMON_ATTRS", "OTP_APPLICATIONS_ATTR", "declare_inputs", "declare_lib_files_for_dep", "declare_outputs", "get_env_path", "get_erl_compiler_binaries", "get_erl_compiler_otp_files", "get_gleam_compiler", "otp_applications")
 load("//gleam:provider.bzl", "GLEAM_ARTEFACTS_DIR", "GleamErlPackageInfo")
 
 def _gleam_library_impl(ctx):
@@ -55,6 +55,7 @@ def _gleam_library_impl(ctx):
             beam_module = depset(direct = outputs.beam_files, transitive = [dep[GleamErlPackageInfo].beam_module for dep in ctx.attr.deps]),
             gleam_cache = depset(direct = outputs.cache_files, transitive = [dep[GleamErlPackageInfo].gleam_cache for dep in ctx.attr.deps]),
             strip_src_prefix = ctx.attr.strip_src_prefix,
+            otp_applications = otp_applications(ctx),
         ),
     ]
 
@@ -72,6 +73,7 @@ gleam_library = rule(
             doc = "The list of dependent gleam modules.",
             providers = [GleamErlPackageInfo],
         ),
+        otp_applications = OTP_APPLICATIONS_ATTR,
     ),
     toolchains = [
         "//gleam_tools:toolchain_type",
@@ -1,5 +1,5 @@
 load("@bazel_skylib//lib:paths.bzl", "paths")
-load("//gleam:build.bzl", "COMMON_ATTRS", "declare_inputs", "declare_lib_files_for_dep", "declare_outputs", "get_env_path", "get_erl_binary", "get_erl_compiler_binaries", "get_erl_compiler_otp_files", "get_gleam_compiler")
+load("//gleam:build.bzl", "COMMON_ATTRS", "OTP_APPLICATIONS_ATTR", "declare_inputs", "declare_lib_files_for_dep", "declare_outputs", "get_env_path", "get_erl_binary", "get_erl_compiler_binaries", "get_erl_compiler_otp_files", "get_gleam_compiler", "otp_applications")
 load("//gleam:provider.bzl", "GLEAM_ARTEFACTS_DIR", "GleamErlPackageInfo")
 
 def _gleam_test_impl(ctx):
@@ -59,7 +59,7 @@ def _gleam_test_impl(ctx):
         output = outputs.beam_app_manifest,
         substitutions = {
             "{PACKAGE}": main_module,
-            "{APPS_COMMA_SEP}": "",
+            "{APPS_COMMA_SEP}": ", ".join(sorted(otp_applications(ctx).to_list())),
             "{MODS_COMMA_SEP}": ",\n\t\t\t\t".join([paths.replace_extension(paths.basename(erl_mod.path), "") for erl_mod in erl_mod_depset.to_list()]),
         },
     )
@@ -168,13 +168,14 @@ _gleam_test = rule(
         srcs = attr.label_list(
             doc = "The list of gleam module files to compile under the current package.",
             mandatory = True,
-            allow_files = ["_test.gleam", "_tests.gleam"],
+            allow_files = [".gleam"],
         ),
         # main_module = attr.string(doc = "The module name containing the main function. Must match the file name of one of the source. Default to the module at srcs[0]"),
         deps = attr.label_list(
             doc = "The list of dependent gleam modules.",
             providers = [GleamErlPackageInfo],
         ),
+        otp_applications = OTP_APPLICATIONS_ATTR,
         _main_test_module_tmpl = attr.label(
             default = "//gleam/templates:gleam_test.gleam",
             allow_single_file = True,
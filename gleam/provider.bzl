@@ -6,6 +6,15 @@ GleamErlPackageInfo = provider(
         "beam_module": "depset of Beam module compilation output files.",
         "gleam_cache": "depset of Gleam cache and cache_meta files for this module.",
         "strip_src_prefix": "the prefix to strip from all the files above for external module",
+        "otp_applications": "depset of the OTP applications the modules need started, e.g. ssl.",
+    },
+)
+
+GleamJsPackageInfo = provider(
+    "Provide JavaScript FFI sources for a Gleam package.",
+    fields = {
+        "js_module": "depset of JavaScript (.mjs, .js) source files.",
+        "strip_src_prefix": "the prefix to strip from all the files above for external module",
     },
 )
 
@@ -2,6 +2,8 @@
 load("//internal:common.bzl", "env_execute", "executable_extension", "watch")
 
 TEMP_DIR = ".__hexpkg__"
+GIT_TEMP_DIR = ".__gitpkg__"
+HEX_CACHE_DIR = ".__hexcache__"
 
 def copy(ctx, file, target, *, watch = "no"):
     f = ctx.path(file)
@@ -39,16 +41,7 @@ def _gleam_compiler_path(repository_ctx):
     compiler_path = repository_ctx.path(tool_label)
     return compiler_path
 
-def _gleam_hex_repository(ctx):
-    tar_package_url = _hex_tar_url(ctx.attr.module_name, ctx.attr.version)
-    ctx.report_progress("Downloading Repo")
-    ctx.download_and_extract(
-        url = tar_package_url,
-        sha256 = ctx.attr.checksum,
-        type = "tar",
-        output = TEMP_DIR,
-    )
-    ctx.file("BUILD.bazel", """# This file is generated by the @rules_gleam//:gleam_hex/repositories.bzl%gleam_hex_repository rule. 
+_BUILD_FILE = """# This file is generated by the @rules_gleam//:gleam_hex/repositories.bzl%{RULE} rule. 
 # DO NOT EDIT.
 load("@gazelle//:def.bzl", "gazelle")
 load("@rules_gleam//gleam:defs.bzl", "gleam_library")
@@ -66,6 +59,7 @@ filegroup(
         "**/*.gleam",
         "**/*.hrl",
         "**/*.erl",
+        "**/*.ex",
         "**/*.app"
     ], allow_empty = True, exclude = ["BUILD"]),
 )
@@ -74,14 +68,58 @@ gazelle(
     name = "gazelle",
     gazelle = "@rules_gleam//gazelle",
 )
-""")
-    ctx.extract("%s/contents.tar.gz" % TEMP_DIR, output = TEMP_DIR)
-    if ctx.path("%s/src" % TEMP_DIR).exists:
-        ctx.extract("%s/contents.tar.gz" % TEMP_DIR, output = ".", strip_prefix = "src")
-    else: 
-        ctx.extract("%s/contents.tar.gz" % TEMP_DIR, output = ".")
-    copy(ctx, "%s/gleam.toml" % TEMP_DIR, "gleam.toml")
-    ctx.delete(TEMP_DIR)
+"""
+
+# Files copied from git and local packages, the ones Gazelle and the Gleam rules use.
+# Elixir sources are only read by Gazelle, for the modules of mix packages.
+_SOURCE_EXTENSIONS = [".gleam", ".erl", ".hrl", ".ex", ".mjs", ".js", ".app"]
+
+def _copy_sources(ctx, src_dir, output):
+    """Copies the sources of a package directory into the repository.
+
+    Args:
+        ctx: The repository context.
+        src_dir: The path of the directory to copy.
+        output: The directory to copy to, relative to the repository root.
+    """
+    dirs = [(src_dir, output)]
+
+    # Starlark has no recursion nor while loops.
+    for _ in range(10000):
+        if not dirs:
+            break
+        src, dst = dirs.pop()
+        for child in src.readdir():
+            target = dst + "/" + child.basename if dst else child.basename
+            if child.is_dir:
+                dirs.append((child, target))
+            elif any([child.basename.endswith(ext) for ext in _SOURCE_EXTENSIONS]):
+                ctx.file(target, ctx.read(child))
+    if dirs:
+        fail("%s has too many directories to copy" % src_dir)
+
+def _copy_package(ctx, package_dir):
+    """Copies the sources of a Gleam package like the ones extracted from Hex.
+
+    Args:
+        ctx: The repository context.
+        package_dir: The path of the package, holding its gleam.toml.
+    """
+    src = package_dir.get_child("src")
+    if src.exists:
+        _copy_sources(ctx, src, "")
+    else:
+        _copy_sources(ctx, package_dir, "")
+    copy(ctx, package_dir.get_child("gleam.toml"), "gleam.toml")
+
+def _generate_build_files(ctx, rule_name):
+    """Writes the root BUILD.bazel and runs Gazelle on the repository.
+
+    Args:
+        ctx: The repository context.
+        rule_name: The name of the repository rule, for the generated header.
+    """
+    ctx.file("BUILD.bazel", _BUILD_FILE.format(RULE = rule_name))
 
     _gazelle_label = Label("@rules_gleam_internal_tools//:bin/gazelle{}".format(executable_extension(ctx)))
     _gazelle = ctx.path(_gazelle_label)
@@ -97,10 +135,21 @@ gazelle(
         "-repo_root",
         ctx.path(""),
     ]
+
+    # The environment is cleared for Gazelle.
+    if ctx.os.environ.get("HEX_MIRROR"):
+        cmd.extend(["-gleam_hex_mirror", ctx.os.environ["HEX_MIRROR"]])
+
+    # Downloaded packages are cached in the repository, not shared with other
+    # repositories, and the extracted packages are hidden from Gazelle.
+    cmd.extend(["-gleam_hex_cache", ctx.path(HEX_CACHE_DIR)])
+    ctx.file(".bazelignore", HEX_CACHE_DIR + "\n")
     cmd.append(ctx.path(""))
     ctx.report_progress("Runnning Gazelle")
 
     result = env_execute(ctx, cmd)
+    ctx.delete(HEX_CACHE_DIR)
+    ctx.delete(".bazelignore")
     if result.return_code:
         fail("failed to generate BUILD files for %s: %s. err: %s" % (
             ctx.attr.module_name,
@@ -108,8 +157,28 @@ gazelle(
             result.stderr,
         ))
 
+def _gleam_hex_repository(ctx):
+    tar_package_url = _hex_tar_url(ctx, ctx.attr.module_name, ctx.attr.version)
+    ctx.report_progress("Downloading Repo")
+    ctx.download_and_extract(
+        url = tar_package_url,
+        sha256 = ctx.attr.checksum,
+        type = "tar",
+        output = TEMP_DIR,
+    )
+    ctx.extract("%s/contents.tar.gz" % TEMP_DIR, output = TEMP_DIR)
+    if ctx.path("%s/src" % TEMP_DIR).exists:
+        ctx.extract("%s/contents.tar.gz" % TEMP_DIR, output = ".", strip_prefix = "src")
+    else: 
+        ctx.extract("%s/contents.tar.gz" % TEMP_DIR, output = ".")
+    copy(ctx, "%s/gleam.toml" % TEMP_DIR, "gleam.toml")
+    ctx.delete(TEMP_DIR)
+
+    _generate_build_files(ctx, "gleam_hex_repository")
+
 gleam_hex_repository = repository_rule(
     _gleam_hex_repository,
+    environ = ["HEX_MIRROR"],
     doc = """
     Creates a repository with the Gleam hex repository.
     """,
@@ -122,6 +191,71 @@ gleam_hex_repository = repository_rule(
     },
 )
 
+def _git(ctx, *args):
+    result = ctx.execute(["git", "-C", ctx.path(GIT_TEMP_DIR)] + list(args))
+    if result.return_code:
+        fail("git %s failed for %s: %s" % (" ".join(args), ctx.attr.module_name, result.stderr))
+    return result
+
+def _gleam_git_repository(ctx):
+    ctx.report_progress("Cloning Repo")
+    ctx.file("%s/.keep" % GIT_TEMP_DIR, "")
+    _git(ctx, "init", "--quiet")
+
+    # Fetching a single commit needs the server to allow it, fall back to
+    # every branch and tag of the repository otherwise.
+    fetched = ctx.execute(["git", "-C", ctx.path(GIT_TEMP_DIR), "fetch", "--quiet", "--depth=1", ctx.attr.repo, ctx.attr.commit])
+    if fetched.return_code:
+        _git(ctx, "fetch", "--quiet", "--tags", ctx.attr.repo, "+refs/heads/*:refs/remotes/origin/*")
+    _git(ctx, "checkout", "--quiet", ctx.attr.commit)
+
+    _copy_package(ctx, ctx.path(GIT_TEMP_DIR))
+    ctx.delete(GIT_TEMP_DIR)
+
+    _generate_build_files(ctx, "gleam_git_repository")
+
+gleam_git_repository = repository_rule(
+    _gleam_git_repository,
+    environ = ["HEX_MIRROR"],
+    doc = """
+    Creates a repository with a Gleam package from a git repository.
+    """,
+    attrs = {
+        "module_name": attr.string(doc = "The Gleam package name."),
+        "repo": attr.string(doc = "The URL of the git repository, from the manifest.toml file", mandatory = True),
+        "commit": attr.string(doc = "The commit to check out, from the manifest.toml file", mandatory = True),
+        "version": attr.string(doc = "Semver version for the module"),
+        "otp_app": attr.string(doc = "The otp_app from the module"),
+    },
+)
+
+def _gleam_local_repository(ctx):
+    package_dir = ctx.path(ctx.attr.path)
+    if not package_dir.exists:
+        fail("local package %s not found at %s" % (ctx.attr.module_name, package_dir))
+    if hasattr(ctx, "watch_tree"):
+        ctx.watch_tree(package_dir)
+
+    # Copied, not symlinked, so Gazelle doesn't write BUILD files in the package.
+    _copy_package(ctx, package_dir)
+
+    _generate_build_files(ctx, "gleam_local_repository")
+
+gleam_local_repository = repository_rule(
+    _gleam_local_repository,
+    environ = ["HEX_MIRROR"],
+    local = True,
+    doc = """
+    Creates a repository with a Gleam package from a local directory.
+    """,
+    attrs = {
+        "module_name": attr.string(doc = "The Gleam package name."),
+        "path": attr.string(doc = "The absolute path of the package directory", mandatory = True),
+        "version": attr.string(doc = "Semver version for the module"),
+        "otp_app": attr.string(doc = "The otp_app from the module"),
+    },
+)
+
 def _gleam_hex_repositories_config_impl(ctx):
     REPOES = {}
     for index, repo_file in enumerate(ctx.attr.repoes):
@@ -166,20 +300,58 @@ gleam_hex_repositories_config = repository_rule(
     },
 )
 
-def _hex_tar_url(module, version):
-    return "https://repo.hex.pm/tarballs/{module}-{version}.tar".format(
+def _hex_tar_url(ctx, module, version):
+    return "{mirror}/tarballs/{module}-{version}.tar".format(
+        mirror = ctx.os.environ.get("HEX_MIRROR", "https://repo.hex.pm").rstrip("/"),
         module = module,
         version = version,
     )
 
+def _resolve_manifest(module_ctx, gleam_toml, manifest, hex_registry, get_hex_repos):
+    """Resolves the gleam.toml requirements against a registry snapshot.
+
+    The source manifest.toml is kept when it's up to date. Otherwise, a new one is written in the
+    extension's working directory, the source tree is never modified.
+
+    Returns:
+        The path of the manifest.toml to use.
+    """
+    gleam_toml_path = module_ctx.path(gleam_toml)
+    registry = gleam_toml_path.dirname.get_child(hex_registry)
+    module_ctx.watch(gleam_toml_path)
+    if hasattr(module_ctx, "watch_tree"):
+        module_ctx.watch_tree(registry)
+    resolved = module_ctx.path("manifest.toml")
+    if manifest.exists:
+        module_ctx.watch(manifest)
+        module_ctx.file("manifest.toml", module_ctx.read(manifest))
+
+    result = module_ctx.execute([
+        module_ctx.path(get_hex_repos),
+        "resolve",
+        "--gleam_toml",
+        gleam_toml_path,
+        "--registry",
+        registry,
+        "--manifest",
+        resolved,
+    ])
+    if result.return_code:
+        fail("failed to resolve %s: %s" % (gleam_toml, result.stderr))
+    return resolved
+
 # A macro (like a repository rule) to download hex repositories.
-def gleam_hex_repositories(module_ctx, *, gleam_toml, _get_hex_repos = Label("@rules_gleam_internal_tools//:bin/get_hex_repos"), _module_prefix = "hex_"):
+def gleam_hex_repositories(module_ctx, *, gleam_toml, verify_tarballs = "", hex_registry = "", _get_hex_repos = Label("@rules_gleam_internal_tools//:bin/get_hex_repos"), _module_prefix = "hex_"):
     """Creates repositories with the Gleam hex repository.
 
     Args:
         module_ctx (module_ctx): The module context.
         *: Additional arguments.
         gleam_toml (Label): The path to the gleam.toml file to be included.
+        verify_tarballs (str): A directory of downloaded Hex tarballs, relative to the gleam.toml, to check
+          against the manifest.toml checksums. Fails on tampered, missing or extra tarballs.
+        hex_registry (str): A Hex registry snapshot directory, relative to the gleam.toml, to resolve
+          the gleam.toml requirements with when manifest.toml is missing or stale.
         _get_hex_repos (Label): The path to the get_hex_repos script to translate the manifest.toml to json
           that bazel can consume.
         _module_prefix (str): The prefix to add to the module name to create the repository name.
@@ -190,6 +362,8 @@ def gleam_hex_repositories(module_ctx, *, gleam_toml, _get_hex_repos = Label("@r
     repos = []
     if gleam_toml != None:
         file = module_ctx.path(gleam_toml).dirname.get_child("manifest.toml")
+        if hex_registry:
+            file = _resolve_manifest(module_ctx, gleam_toml, file, hex_registry, _get_hex_repos)
         if file.exists:
             get_hex_repos = module_ctx.path(_get_hex_repos)
             module_ctx.watch(file)
@@ -201,18 +375,47 @@ def gleam_hex_repositories(module_ctx, *, gleam_toml, _get_hex_repos = Label("@r
             if dependencies.return_code:
                 fail("failed to read manifest.toml file: %s" % dependencies.stderr)
 
+            if verify_tarballs:
+                tarballs = module_ctx.path(gleam_toml).dirname.get_child(verify_tarballs)
+                verified = module_ctx.execute([get_hex_repos, "--manifest", file, "--verify", tarballs])
+                if verified.return_code:
+                    fail("Hex tarballs in %s don't match manifest.toml: %s %s" % (tarballs, verified.stdout, verified.stderr))
+
             dep_json = json.decode(dependencies.stdout)
             repos = dep_json.get("repos", default = [])
 
     for repo in repos:
-        gleam_hex_repository(
-            name = _module_prefix + repo.get("module_name"),
-            module_name = repo.get("module_name"),
-            checksum = repo.get("checksum"),
-            version = repo.get("version"),
-            otp_app = repo.get("otp_app"),
-            # deps = [Label("@%s%s//:REPO" % (_module_prefix, dep)) for dep in repo.get("deps")],
-        )
+        source = repo.get("source", "hex")
+        if source == "git":
+            gleam_git_repository(
+                name = _module_prefix + repo.get("module_name"),
+                module_name = repo.get("module_name"),
+                repo = repo.get("repo"),
+                commit = repo.get("commit"),
+                version = repo.get("version"),
+                otp_app = repo.get("otp_app"),
+            )
+        elif source == "local":
+            # Paths are relative to the manifest.toml.
+            path = repo.get("path")
+            if not path.startswith("/"):
+                path = str(module_ctx.path(gleam_toml).dirname) + "/" + path
+            gleam_local_repository(
+                name = _module_prefix + repo.get("module_name"),
+                module_name = repo.get("module_name"),
+                path = path,
+                version = repo.get("version"),
+                otp_app = repo.get("otp_app"),
+            )
+        else:
+            gleam_hex_repository(
+                name = _module_prefix + repo.get("module_name"),
+                module_name = repo.get("module_name"),
+                checksum = repo.get("checksum"),
+                version = repo.get("version"),
+                otp_app = repo.get("otp_app"),
+                # deps = [Label("@%s%s//:REPO" % (_module_prefix, dep)) for dep in repo.get("deps")],
+            )
 
     gleam_hex_repositories_config(
         name = "gleam_hex_repositories_config",
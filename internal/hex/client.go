@@ -0,0 +1,182 @@
+package hex
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+)
+
+// DefaultMirror is the Hex repository packages are downloaded from when
+// neither the client nor the HEX_MIRROR environment variable sets one.
+const DefaultMirror = "https://repo.hex.pm"
+
+// ChecksumError is returned when a downloaded tarball doesn't match the
+// outer_checksum of manifest.toml.
+type ChecksumError struct {
+	Package   string
+	Version   string
+	Want, Got string
+}
+
+func (e *ChecksumError) Error() string {
+	return fmt.Sprintf("%s %s: tarball checksum is %s, manifest.toml has %s", e.Package, e.Version, e.Got, e.Want)
+}
+
+// Client downloads Hex packages into a content-addressed cache, one
+// directory per outer checksum holding the unpacked contents.tar.gz.
+type Client struct {
+	// Base URL of the Hex repository, e.g. DefaultMirror.
+	Mirror string
+	// Directory of the unpacked packages.
+	CacheDir string
+	// Defaults to http.DefaultClient.
+	HTTPClient *http.Client
+}
+
+// NewClient returns a client caching packages in cacheDir. An empty mirror
+// defaults to the HEX_MIRROR environment variable, then to DefaultMirror.
+func NewClient(mirror, cacheDir string) *Client {
+	if mirror == "" {
+		mirror = os.Getenv("HEX_MIRROR")
+	}
+	if mirror == "" {
+		mirror = DefaultMirror
+	}
+	return &Client{
+		Mirror:   strings.TrimSuffix(mirror, "/"),
+		CacheDir: cacheDir,
+	}
+}
+
+// DefaultCacheDir returns the directory packages are cached in by default,
+// under the user cache directory.
+func DefaultCacheDir() (string, error) {
+	dir, err := os.UserCacheDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, "rules_gleam", "hex"), nil
+}
+
+// TarballURL returns the URL of the tarball of a package.
+func (c *Client) TarballURL(pkg Package) string {
+	return fmt.Sprintf("%s/tarballs/%s-%s.tar", c.Mirror, pkg.Name, pkg.Version)
+}
+
+// Fetch downloads a package, unless it's already cached, and returns the
+// directory its contents are unpacked in.
+func (c *Client) Fetch(ctx context.Context, pkg Package) (string, error) {
+	if pkg.OuterChecksum == "" {
+		return "", fmt.Errorf("%s %s: no outer_checksum in manifest.toml", pkg.Name, pkg.Version)
+	}
+	dir := filepath.Join(c.CacheDir, strings.ToLower(pkg.OuterChecksum))
+	if _, err := os.Stat(dir); err == nil {
+		return dir, nil
+	}
+
+	tarball, err := c.Download(ctx, pkg)
+	if err != nil {
+		return "", err
+	}
+	contents, err := readContents(tarball)
+	if err != nil {
+		return "", fmt.Errorf("%s %s: %w", pkg.Name, pkg.Version, err)
+	}
+
+	// Unpacked next to the cache entry and renamed, so an interrupted
+	// download never leaves a partial entry behind.
+	if err := os.MkdirAll(c.CacheDir, 0755); err != nil {
+		return "", err
+	}
+	tmp, err := os.MkdirTemp(c.CacheDir, ".tmp-")
+	if err != nil {
+		return "", err
+	}
+	defer os.RemoveAll(tmp)
+	if err := unpackContents(contents, tmp); err != nil {
+		return "", fmt.Errorf("%s %s: %w", pkg.Name, pkg.Version, err)
+	}
+	if err := os.Rename(tmp, dir); err != nil {
+		// Another run may have cached the same package in the meantime.
+		if _, statErr := os.Stat(dir); statErr == nil {
+			return dir, nil
+		}
+		return "", err
+	}
+	return dir, nil
+}
+
+// Download downloads the tarball of a package and checks it against the
+// outer checksum of the manifest.
+func (c *Client) Download(ctx context.Context, pkg Package) ([]byte, error) {
+	url := c.TarballURL(pkg)
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return nil, err
+	}
+	httpClient := c.HTTPClient
+	if httpClient == nil {
+		httpClient = http.DefaultClient
+	}
+	resp, err := httpClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("%s %s: %w", pkg.Name, pkg.Version, err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("%s %s: GET %s: %s", pkg.Name, pkg.Version, url, resp.Status)
+	}
+	var tarball bytes.Buffer
+	hash := sha256.New()
+	if _, err := io.Copy(io.MultiWriter(&tarball, hash), resp.Body); err != nil {
+		return nil, fmt.Errorf("%s %s: %w", pkg.Name, pkg.Version, err)
+	}
+	if got := hex.EncodeToString(hash.Sum(nil)); !strings.EqualFold(got, pkg.OuterChecksum) {
+		return nil, &ChecksumError{
+			Package: pkg.Name,
+			Version: pkg.Version,
+			Want:    pkg.OuterChecksum,
+			Got:     strings.ToUpper(got),
+		}
+	}
+	return tarball.Bytes(), nil
+}
+
+// FetchAll fetches packages concurrently and returns the directory of each
+// one by name.
+func (c *Client) FetchAll(ctx context.Context, pkgs []Package) (map[string]string, error) {
+	var (
+		wg   sync.WaitGroup
+		mu   sync.Mutex
+		dirs = make(map[string]string, len(pkgs))
+		errs []error
+	)
+	for _, pkg := range pkgs {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			dir, err := c.Fetch(ctx, pkg)
+			mu.Lock()
+			defer mu.Unlock()
+			if err != nil {
+				errs = append(errs, err)
+				return
+			}
+			dirs[pkg.Name] = dir
+		}()
+	}
+	wg.Wait()
+	if err := errors.Join(errs...); err != nil {
+		return nil, err
+	}
+	return dirs, nil
+}
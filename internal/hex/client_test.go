@@ -0,0 +1,129 @@
+package hex
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/iocat/rules_gleam/internal/hex/hextest"
+)
+
+func TestFetch(t *testing.T) {
+	tarball, checksum := hextest.Tarball(t, map[string]string{
+		"gleam.toml":             `name = "gleam_json"`,
+		"src/gleam/json.gleam":   "pub fn decode() { Nil }\n",
+		"src/gleam_json_ffi.erl": "-module(gleam_json_ffi).\n",
+	})
+	srv := hextest.NewServer(t, map[string][]byte{
+		"gleam_json-3.0.2": tarball,
+	})
+	c := NewClient(srv.URL+"/", t.TempDir())
+	pkg := Package{Name: "gleam_json", Version: "3.0.2", Source: "hex", OuterChecksum: checksum}
+
+	dir, err := c.Fetch(context.Background(), pkg)
+	if err != nil {
+		t.Fatalf("Fetch() failed: %v", err)
+	}
+	if want := filepath.Join(c.CacheDir, strings.ToLower(checksum)); dir != want {
+		t.Errorf("Fetch() = %s, want %s", dir, want)
+	}
+	got, err := os.ReadFile(filepath.Join(dir, "src", "gleam", "json.gleam"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(got) != "pub fn decode() { Nil }\n" {
+		t.Errorf("unexpected json.gleam: %q", got)
+	}
+
+	// The second fetch is served by the cache.
+	if _, err := c.Fetch(context.Background(), pkg); err != nil {
+		t.Fatalf("Fetch() failed: %v", err)
+	}
+	if n := srv.Requests.Load(); n != 1 {
+		t.Errorf("got %d requests, want 1", n)
+	}
+}
+
+func TestFetchChecksumMismatch(t *testing.T) {
+	tarball, _ := hextest.Tarball(t, map[string]string{"src/a.gleam": ""})
+	srv := hextest.NewServer(t, map[string][]byte{
+		"a-1.0.0": tarball,
+	})
+	c := NewClient(srv.URL, t.TempDir())
+	pkg := Package{Name: "a", Version: "1.0.0", Source: "hex", OuterChecksum: strings.Repeat("0", 64)}
+
+	_, err := c.Fetch(context.Background(), pkg)
+	var checksumErr *ChecksumError
+	if !errors.As(err, &checksumErr) {
+		t.Fatalf("Fetch() error = %v, want a ChecksumError", err)
+	}
+	entries, err := os.ReadDir(c.CacheDir)
+	if err != nil && !os.IsNotExist(err) {
+		t.Fatal(err)
+	}
+	if len(entries) != 0 {
+		t.Errorf("cache has %d entries after a checksum mismatch, want 0", len(entries))
+	}
+}
+
+func TestFetchUnsafePath(t *testing.T) {
+	tarball, checksum := hextest.Tarball(t, map[string]string{"../escape.gleam": ""})
+	srv := hextest.NewServer(t, map[string][]byte{
+		"a-1.0.0": tarball,
+	})
+	c := NewClient(srv.URL, t.TempDir())
+	pkg := Package{Name: "a", Version: "1.0.0", Source: "hex", OuterChecksum: checksum}
+
+	if _, err := c.Fetch(context.Background(), pkg); err == nil {
+		t.Fatal("Fetch() succeeded, want an error")
+	}
+	if _, err := os.Stat(filepath.Join(c.CacheDir, strings.ToLower(checksum))); err == nil {
+		t.Error("package with an unsafe path was cached")
+	}
+}
+
+func TestFetchAll(t *testing.T) {
+	stdlib, stdlibChecksum := hextest.Tarball(t, map[string]string{"src/gleam/list.gleam": ""})
+	json, jsonChecksum := hextest.Tarball(t, map[string]string{"src/gleam/json.gleam": ""})
+	srv := hextest.NewServer(t, map[string][]byte{
+		"gleam_stdlib-0.60.0": stdlib,
+		"gleam_json-3.0.2":    json,
+	})
+	manifestPath := filepath.Join(t.TempDir(), "manifest.toml")
+	manifest := `
+packages = [
+  { name = "gleam_json", version = "3.0.2", build_tools = ["gleam"], requirements = ["gleam_stdlib"], otp_app = "gleam_json", source = "hex", outer_checksum = "` + jsonChecksum + `" },
+  { name = "gleam_stdlib", version = "0.60.0", build_tools = ["gleam"], requirements = [], otp_app = "gleam_stdlib", source = "hex", outer_checksum = "` + stdlibChecksum + `" },
+  { name = "local_lib", version = "1.0.0", build_tools = ["gleam"], requirements = [], source = "local", path = "../local_lib" },
+]
+`
+	if err := os.WriteFile(manifestPath, []byte(manifest), 0644); err != nil {
+		t.Fatal(err)
+	}
+	m, err := ReadManifest(manifestPath)
+	if err != nil {
+		t.Fatalf("ReadManifest() failed: %v", err)
+	}
+
+	// The mirror comes from the environment.
+	t.Setenv("HEX_MIRROR", srv.URL)
+	c := NewClient("", t.TempDir())
+	dirs, err := c.FetchAll(context.Background(), m.HexPackages())
+	if err != nil {
+		t.Fatalf("FetchAll() failed: %v", err)
+	}
+	if len(dirs) != 2 {
+		t.Fatalf("FetchAll() = %v, want gleam_json and gleam_stdlib", dirs)
+	}
+	for name, file := range map[string]string{
+		"gleam_json":   "src/gleam/json.gleam",
+		"gleam_stdlib": "src/gleam/list.gleam",
+	} {
+		if _, err := os.Stat(filepath.Join(dirs[name], file)); err != nil {
+			t.Errorf("%s: %v", name, err)
+		}
+	}
+}
@@ -0,0 +1,92 @@
+// Package hextest serves Hex package tarballs for tests, like repo.hex.pm.
+package hextest
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"crypto/sha256"
+	"encoding/hex"
+	"net/http"
+	"net/http/httptest"
+	"sort"
+	"strings"
+	"sync/atomic"
+	"testing"
+)
+
+// Tarball returns a package tarball whose contents.tar.gz holds files, by
+// path, and its outer checksum as written in manifest.toml.
+func Tarball(t testing.TB, files map[string]string) ([]byte, string) {
+	t.Helper()
+	var contents bytes.Buffer
+	gz := gzip.NewWriter(&contents)
+	if _, err := gz.Write(writeTar(t, files)); err != nil {
+		t.Fatal(err)
+	}
+	if err := gz.Close(); err != nil {
+		t.Fatal(err)
+	}
+	tarball := writeTar(t, map[string]string{
+		"VERSION":         "3",
+		"metadata.config": "",
+		"contents.tar.gz": contents.String(),
+	})
+	sum := sha256.Sum256(tarball)
+	return tarball, strings.ToUpper(hex.EncodeToString(sum[:]))
+}
+
+func writeTar(t testing.TB, files map[string]string) []byte {
+	t.Helper()
+	names := make([]string, 0, len(files))
+	for name := range files {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var buf bytes.Buffer
+	tw := tar.NewWriter(&buf)
+	for _, name := range names {
+		content := files[name]
+		if err := tw.WriteHeader(&tar.Header{Name: name, Mode: 0644, Size: int64(len(content))}); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := tw.Write([]byte(content)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := tw.Close(); err != nil {
+		t.Fatal(err)
+	}
+	return buf.Bytes()
+}
+
+// Server is a stand-in for a Hex repository.
+type Server struct {
+	*httptest.Server
+	// Number of requests served.
+	Requests atomic.Int32
+}
+
+// NewServer serves tarballs by "<name>-<version>" at /tarballs/, like
+// repo.hex.pm. It's closed when the test ends.
+func NewServer(t testing.TB, tarballs map[string][]byte) *Server {
+	t.Helper()
+	s := &Server{}
+	s.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		s.Requests.Add(1)
+		name, ok := strings.CutPrefix(r.URL.Path, "/tarballs/")
+		if !ok {
+			http.NotFound(w, r)
+			return
+		}
+		tarball, ok := tarballs[strings.TrimSuffix(name, ".tar")]
+		if !ok {
+			http.NotFound(w, r)
+			return
+		}
+		w.Write(tarball)
+	}))
+	t.Cleanup(s.Close)
+	return s
+}
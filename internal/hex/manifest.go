@@ -0,0 +1,111 @@
+// Package hex reads Gleam manifest.toml files, and downloads the Hex packages
+// they lock.
+package hex
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+
+	"github.com/BurntSushi/toml"
+)
+
+// Sources of manifest.toml packages.
+const (
+	SourceHex   = "hex"
+	SourceGit   = "git"
+	SourceLocal = "local"
+)
+
+// Package is a package locked by manifest.toml.
+type Package struct {
+	Name         string   `toml:"name"`
+	Version      string   `toml:"version"`
+	BuildTools   []string `toml:"build_tools"`
+	Requirements []string `toml:"requirements"`
+	OtpApp       string   `toml:"otp_app"`
+	Source       string   `toml:"source"`
+	// For source "hex", SHA-256 of the tarball, in hexadecimal.
+	OuterChecksum string `toml:"outer_checksum"`
+	// For source "git".
+	Repo   string `toml:"repo"`
+	Commit string `toml:"commit"`
+	// For source "local", relative to the manifest.toml directory.
+	Path string `toml:"path"`
+}
+
+// Origin returns the source of the package, manifests without one only hold
+// Hex packages.
+func (p Package) Origin() string {
+	if p.Source == "" {
+		return SourceHex
+	}
+	return p.Source
+}
+
+// Requirement is a requirement of gleam.toml, as recorded in manifest.toml
+// when it was resolved.
+type Requirement struct {
+	// For Hex dependencies.
+	Version string `toml:"version"`
+	// For git dependencies.
+	Git string `toml:"git"`
+	Ref string `toml:"ref"`
+	// For local dependencies, relative to gleam.toml.
+	Path string `toml:"path"`
+}
+
+// Source returns the source of the packages meeting the requirement.
+func (r Requirement) Source() string {
+	switch {
+	case r.Git != "":
+		return SourceGit
+	case r.Path != "":
+		return SourceLocal
+	}
+	return SourceHex
+}
+
+func (r Requirement) String() string {
+	switch r.Source() {
+	case SourceGit:
+		if r.Ref == "" {
+			return "git " + r.Git
+		}
+		return fmt.Sprintf("git %s at %s", r.Git, r.Ref)
+	case SourceLocal:
+		return "path " + r.Path
+	}
+	return strconv.Quote(r.Version)
+}
+
+// Manifest is a Gleam manifest.toml.
+type Manifest struct {
+	Packages []Package `toml:"packages"`
+	// Requirements of gleam.toml the packages were resolved from.
+	Requirements map[string]Requirement `toml:"requirements"`
+}
+
+// ReadManifest reads the manifest.toml at path.
+func ReadManifest(path string) (*Manifest, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	manifest := new(Manifest)
+	if err := toml.Unmarshal(data, manifest); err != nil {
+		return nil, fmt.Errorf("parsing %s: %w", path, err)
+	}
+	return manifest, nil
+}
+
+// HexPackages returns the packages downloaded from Hex.
+func (m *Manifest) HexPackages() []Package {
+	var pkgs []Package
+	for _, pkg := range m.Packages {
+		if pkg.Origin() == SourceHex {
+			pkgs = append(pkgs, pkg)
+		}
+	}
+	return pkgs
+}
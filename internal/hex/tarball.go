@@ -0,0 +1,82 @@
+package hex
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+)
+
+// readContents returns contents.tar.gz from the outer tarball of a package.
+func readContents(tarball []byte) ([]byte, error) {
+	tr := tar.NewReader(bytes.NewReader(tarball))
+	for {
+		hdr, err := tr.Next()
+		if err == io.EOF {
+			return nil, errors.New("no contents.tar.gz in tarball")
+		}
+		if err != nil {
+			return nil, err
+		}
+		if hdr.Name == "contents.tar.gz" {
+			return io.ReadAll(tr)
+		}
+	}
+}
+
+// unpackContents unpacks contents.tar.gz into dir.
+func unpackContents(contents []byte, dir string) error {
+	gz, err := gzip.NewReader(bytes.NewReader(contents))
+	if err != nil {
+		return err
+	}
+	defer gz.Close()
+
+	tr := tar.NewReader(gz)
+	for {
+		hdr, err := tr.Next()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		name := filepath.FromSlash(hdr.Name)
+		if !filepath.IsLocal(name) {
+			return fmt.Errorf("%s: path outside of the package", hdr.Name)
+		}
+		target := filepath.Join(dir, name)
+		switch hdr.Typeflag {
+		case tar.TypeDir:
+			if err := os.MkdirAll(target, 0755); err != nil {
+				return err
+			}
+		case tar.TypeReg:
+			if err := writeFile(target, tr, hdr.FileInfo().Mode()); err != nil {
+				return err
+			}
+		default:
+			// Links and devices aren't needed to build Gleam sources.
+			continue
+		}
+	}
+}
+
+func writeFile(path string, r io.Reader, mode os.FileMode) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return err
+	}
+	f, err := os.OpenFile(path, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, mode.Perm()|0600)
+	if err != nil {
+		return err
+	}
+	if _, err := io.Copy(f, r); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
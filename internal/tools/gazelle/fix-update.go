@@ -260,10 +260,11 @@ var genericLoads = []rule.LoadInfo{
 }
 
 func runFixUpdate(wd string, cmd command, args []string) (err error) {
-	cexts := make([]config.Configurer, 0, len(languages)+4)
+	cexts := make([]config.Configurer, 0, len(languages)+5)
 	cexts = append(cexts,
 		&config.CommonConfigurer{},
 		&updateConfigurer{},
+		&graphConfigurer{},
 		&walk.Configurer{},
 		&resolve.Configurer{})
 
@@ -519,6 +520,10 @@ func runFixUpdate(wd string, cmd command, args []string) (err error) {
 		}
 	}
 
+	if cmd == graphCmd {
+		return writeGraph(c, visits, mrslv)
+	}
+
 	// Emit merged files.
 	var exit error
 	for _, v := range visits {
@@ -586,7 +591,11 @@ func newFixUpdateConfiguration(wd string, cmd command, args []string, cexts []co
 
 	if err := fs.Parse(args); err != nil {
 		if err == flag.ErrHelp {
-			fixUpdateUsage(fs)
+			if cmd == graphCmd {
+				graphUsage(fs)
+			} else {
+				fixUpdateUsage(fs)
+			}
 			return nil, err
 		}
 		// flag already prints the error; don't print it again.
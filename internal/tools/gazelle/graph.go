@@ -0,0 +1,293 @@
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+
+	"github.com/bazelbuild/bazel-gazelle/config"
+	"github.com/bazelbuild/bazel-gazelle/label"
+	"github.com/bazelbuild/bazel-gazelle/rule"
+	"github.com/bazelbuild/buildtools/build"
+	"github.com/iocat/rules_gleam/gazelle/gleam"
+)
+
+const graphName = "_graph"
+
+// graphConfig holds the configuration of the graph command.
+type graphConfig struct {
+	format string
+	level  string
+	out    string
+	outDir string
+}
+
+func getGraphConfig(c *config.Config) *graphConfig {
+	return c.Exts[graphName].(*graphConfig)
+}
+
+var _ config.Configurer = (*graphConfigurer)(nil)
+
+type graphConfigurer struct{}
+
+func (*graphConfigurer) RegisterFlags(fs *flag.FlagSet, cmd string, c *config.Config) {
+	gc := &graphConfig{}
+	c.Exts[graphName] = gc
+	if cmd != graphCmd.String() {
+		return
+	}
+
+	fs.StringVar(&gc.format, "format", "dot", "dot: writes the graph in Graphviz DOT format\n\tjson: writes the graph as JSON")
+	fs.StringVar(&gc.level, "level", "module", "module: graph of Gleam module imports\n\ttarget: graph of dependencies between Gleam targets")
+	fs.StringVar(&gc.out, "out", "", "file to write the graph to. Defaults to stdout.")
+	fs.StringVar(&gc.outDir, "out_dir", "", "directory to write both graphs to in both formats, ignoring -format and -level:\n\tmodule.dot, module.json, target.dot and target.json")
+}
+
+func (*graphConfigurer) CheckFlags(fs *flag.FlagSet, c *config.Config) error {
+	gc := getGraphConfig(c)
+	if gc.format == "" {
+		// Not running the graph command.
+		return nil
+	}
+	if gc.format != "dot" && gc.format != "json" {
+		return fmt.Errorf("unrecognized graph format: %q", gc.format)
+	}
+	if gc.level != "module" && gc.level != "target" {
+		return fmt.Errorf("unrecognized graph level: %q", gc.level)
+	}
+	if gc.out != "" && gc.outDir != "" {
+		return fmt.Errorf("-out and -out_dir can't be set together")
+	}
+	if gc.out != "" && !filepath.IsAbs(gc.out) {
+		gc.out = filepath.Join(c.WorkDir, gc.out)
+	}
+	if gc.outDir != "" && !filepath.IsAbs(gc.outDir) {
+		gc.outDir = filepath.Join(c.WorkDir, gc.outDir)
+	}
+	return nil
+}
+
+func (*graphConfigurer) KnownDirectives() []string { return nil }
+
+func (*graphConfigurer) Configure(c *config.Config, rel string, f *rule.File) {}
+
+// dependencyGraph is a directed graph of Gleam modules or targets.
+type dependencyGraph struct {
+	Nodes []string    `json:"nodes"`
+	Edges []graphEdge `json:"edges"`
+
+	nodes map[string]bool
+	edges map[graphEdge]bool
+}
+
+type graphEdge struct {
+	From string `json:"from"`
+	To   string `json:"to"`
+	// Where the import was made, "<file>:<line>:<col>". Only set for module
+	// imports.
+	Location string `json:"location,omitempty"`
+}
+
+func newDependencyGraph() *dependencyGraph {
+	return &dependencyGraph{
+		Nodes: []string{},
+		Edges: []graphEdge{},
+		nodes: make(map[string]bool),
+		edges: make(map[graphEdge]bool),
+	}
+}
+
+func (g *dependencyGraph) addNode(node string) {
+	if g.nodes[node] {
+		return
+	}
+	g.nodes[node] = true
+	g.Nodes = append(g.Nodes, node)
+}
+
+func (g *dependencyGraph) addEdge(edge graphEdge) {
+	g.addNode(edge.From)
+	g.addNode(edge.To)
+	key := graphEdge{From: edge.From, To: edge.To}
+	if g.edges[key] {
+		return
+	}
+	g.edges[key] = true
+	g.Edges = append(g.Edges, edge)
+}
+
+// sort orders nodes and edges so the output is stable between runs.
+func (g *dependencyGraph) sort() {
+	sort.Strings(g.Nodes)
+	sort.Slice(g.Edges, func(i, j int) bool {
+		if g.Edges[i].From != g.Edges[j].From {
+			return g.Edges[i].From < g.Edges[j].From
+		}
+		return g.Edges[i].To < g.Edges[j].To
+	})
+}
+
+func (g *dependencyGraph) writeJSON(w io.Writer) error {
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(g)
+}
+
+func (g *dependencyGraph) writeDOT(w io.Writer, name string) error {
+	if _, err := fmt.Fprintf(w, "digraph %s {\n", strconv.Quote(name)); err != nil {
+		return err
+	}
+	for _, node := range g.Nodes {
+		if _, err := fmt.Fprintf(w, "  %s;\n", strconv.Quote(node)); err != nil {
+			return err
+		}
+	}
+	for _, edge := range g.Edges {
+		if _, err := fmt.Fprintf(w, "  %s -> %s;\n", strconv.Quote(edge.From), strconv.Quote(edge.To)); err != nil {
+			return err
+		}
+	}
+	_, err := fmt.Fprintln(w, "}")
+	return err
+}
+
+// buildGraphs builds the module and target level graphs of the Gleam rules
+// generated during the visits. It must be called after dependency resolution.
+func buildGraphs(visits []visitRecord, mrslv *metaResolver) (modules, targets *dependencyGraph) {
+	modules = newDependencyGraph()
+	targets = newDependencyGraph()
+	for _, v := range visits {
+		for i, r := range v.rules {
+			rslv := mrslv.Resolver(r, v.pkgRel)
+			if rslv == nil || rslv.Name() != "gleam" {
+				continue
+			}
+			for _, spec := range rslv.Imports(v.c, r, v.file) {
+				modules.addNode(spec.Imp)
+			}
+			for _, imp := range gleam.ModuleImports(v.imports[i]) {
+				modules.addEdge(graphEdge{From: imp.Module, To: imp.Import, Location: imp.Location})
+			}
+
+			from := label.New(v.c.RepoName, v.pkgRel, r.Name())
+			targets.addNode(from.String())
+			for _, dep := range ruleDeps(r) {
+				l, err := label.Parse(dep)
+				if err != nil {
+					continue
+				}
+				targets.addEdge(graphEdge{From: from.String(), To: l.Abs(from.Repo, from.Pkg).String()})
+			}
+		}
+	}
+	modules.sort()
+	targets.sort()
+	return modules, targets
+}
+
+// ruleDeps returns every label in the deps attribute of r, including the ones
+// in select() branches.
+func ruleDeps(r *rule.Rule) []string {
+	var deps []string
+	if expr := r.Attr("deps"); expr != nil {
+		build.Walk(expr, func(x build.Expr, stk []build.Expr) {
+			s, ok := x.(*build.StringExpr)
+			if !ok {
+				return
+			}
+			// Keys of a select() are conditions, not dependencies.
+			if len(stk) > 0 {
+				if kv, ok := stk[len(stk)-1].(*build.KeyValueExpr); ok && kv.Key == x {
+					return
+				}
+			}
+			deps = append(deps, s.Value)
+		})
+	}
+	return deps
+}
+
+func writeGraph(c *config.Config, visits []visitRecord, mrslv *metaResolver) error {
+	gc := getGraphConfig(c)
+	modules, targets := buildGraphs(visits, mrslv)
+	if gc.outDir != "" {
+		return writeGraphDir(gc.outDir, modules, targets)
+	}
+
+	graph := modules
+	if gc.level == "target" {
+		graph = targets
+	}
+	if gc.out == "" {
+		return graph.write(os.Stdout, gc.format, gc.level)
+	}
+	return graph.writeFile(gc.out, gc.format, gc.level)
+}
+
+// writeGraphDir writes the module and target graphs to dir in both formats,
+// as <level>.<format>.
+func writeGraphDir(dir string, modules, targets *dependencyGraph) error {
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return err
+	}
+	graphs := map[string]*dependencyGraph{"module": modules, "target": targets}
+	for _, level := range []string{"module", "target"} {
+		for _, format := range []string{"dot", "json"} {
+			if err := graphs[level].writeFile(filepath.Join(dir, level+"."+format), format, level); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+func (g *dependencyGraph) writeFile(path, format, level string) (err error) {
+	f, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if cerr := f.Close(); err == nil && cerr != nil {
+			err = cerr
+		}
+	}()
+	return g.write(f, format, level)
+}
+
+func (g *dependencyGraph) write(w io.Writer, format, level string) error {
+	if format == "json" {
+		return g.writeJSON(w)
+	}
+	return g.writeDOT(w, level+"s")
+}
+
+func graphUsage(fs *flag.FlagSet) {
+	fmt.Fprint(os.Stderr, `usage: gazelle graph [flags...] [package-dirs...]
+
+The graph command generates rules like the update command, without writing any
+BUILD file, and writes the import graph of the Gleam code in the given
+directories instead.
+
+The -level flag selects which graph is written:
+
+  module (default) - Gleam modules and the modules they import.
+  target - Gleam targets and the targets they depend on, after resolution.
+
+The -format flag selects how the graph is written:
+
+  dot (default) - Graphviz DOT format.
+  json - a JSON object with "nodes" and "edges".
+
+With -out_dir, both graphs are written in both formats in one run, to
+module.dot, module.json, target.dot and target.json in that directory.
+
+FLAGS:
+
+`)
+	fs.PrintDefaults()
+}
@@ -0,0 +1,129 @@
+package main
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/bazelbuild/bazel-gazelle/rule"
+	"github.com/google/go-cmp/cmp"
+)
+
+func testGraph() *dependencyGraph {
+	g := newDependencyGraph()
+	g.addEdge(graphEdge{From: "app/main", To: "gleam/io", Location: "app/main.gleam:1:1"})
+	g.addEdge(graphEdge{From: "app/main", To: "app/util", Location: "app/main.gleam:2:1"})
+	// Duplicate edges made from another location are dropped.
+	g.addEdge(graphEdge{From: "app/main", To: "gleam/io", Location: "app/main.gleam:3:1"})
+	g.addEdge(graphEdge{From: "app/util", To: "erl:util_ffi", Location: "app/util.gleam:4:1"})
+	g.addNode("app/unused")
+	g.sort()
+	return g
+}
+
+func TestDependencyGraphDOT(t *testing.T) {
+	var buf bytes.Buffer
+	if err := testGraph().writeDOT(&buf, "modules"); err != nil {
+		t.Fatal(err)
+	}
+	want := `digraph "modules" {
+  "app/main";
+  "app/unused";
+  "app/util";
+  "erl:util_ffi";
+  "gleam/io";
+  "app/main" -> "app/util";
+  "app/main" -> "gleam/io";
+  "app/util" -> "erl:util_ffi";
+}
+`
+	if diff := cmp.Diff(want, buf.String()); diff != "" {
+		t.Errorf("(-want, +got):\n%s", diff)
+	}
+}
+
+func TestDependencyGraphJSON(t *testing.T) {
+	var buf bytes.Buffer
+	if err := testGraph().writeJSON(&buf); err != nil {
+		t.Fatal(err)
+	}
+	want := `{
+  "nodes": [
+    "app/main",
+    "app/unused",
+    "app/util",
+    "erl:util_ffi",
+    "gleam/io"
+  ],
+  "edges": [
+    {
+      "from": "app/main",
+      "to": "app/util",
+      "location": "app/main.gleam:2:1"
+    },
+    {
+      "from": "app/main",
+      "to": "gleam/io",
+      "location": "app/main.gleam:1:1"
+    },
+    {
+      "from": "app/util",
+      "to": "erl:util_ffi",
+      "location": "app/util.gleam:4:1"
+    }
+  ]
+}
+`
+	if diff := cmp.Diff(want, buf.String()); diff != "" {
+		t.Errorf("(-want, +got):\n%s", diff)
+	}
+}
+
+func TestWriteGraphDir(t *testing.T) {
+	targets := newDependencyGraph()
+	targets.addEdge(graphEdge{From: "//app:main", To: "//app:util"})
+	targets.sort()
+
+	dir := filepath.Join(t.TempDir(), "graphs")
+	if err := writeGraphDir(dir, testGraph(), targets); err != nil {
+		t.Fatal(err)
+	}
+	want := map[string]string{
+		"module.dot":  `digraph "modules" {`,
+		"module.json": `"from": "app/main"`,
+		"target.dot":  `"//app:main" -> "//app:util";`,
+		"target.json": `"to": "//app:util"`,
+	}
+	for name, content := range want {
+		got, err := os.ReadFile(filepath.Join(dir, name))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !strings.Contains(string(got), content) {
+			t.Errorf("%s doesn't contain %q:\n%s", name, content, got)
+		}
+	}
+}
+
+func TestRuleDeps(t *testing.T) {
+	f, err := rule.LoadData("BUILD", "app", []byte(`
+gleam_library(
+    name = "main",
+    srcs = ["main.gleam"],
+    deps = [":util"] + select({
+        "//conditions:default": ["//erl:util_ffi"],
+        "@rules_gleam//gleam/target:javascript": ["//js:util_js_ffi"],
+    }),
+)
+`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []string{":util", "//erl:util_ffi", "//js:util_js_ffi"}
+	got := ruleDeps(f.Rules[0])
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("(-want, +got):\n%s", diff)
+	}
+}
@@ -34,10 +34,12 @@ const (
 	fixCmd
 	updateReposCmd
 	helpCmd
+	graphCmd
 )
 
 var commandFromName = map[string]command{
 	"fix":          fixCmd,
+	"graph":        graphCmd,
 	"help":         helpCmd,
 	"update":       updateCmd,
 	"update-repos": updateReposCmd,
@@ -49,6 +51,7 @@ var nameFromCommand = []string{
 	"fix",
 	"update-repos",
 	"help",
+	"graph",
 }
 
 func (cmd command) String() string {
@@ -91,7 +94,7 @@ func run(wd string, args []string) error {
 	}
 
 	switch cmd {
-	case fixCmd, updateCmd:
+	case fixCmd, updateCmd, graphCmd:
 		return runFixUpdate(wd, cmd, args)
 	case helpCmd:
 		return help()
@@ -122,6 +125,8 @@ Gazelle defaults to "update".
       existing rules.
   update-repos - updates repository rules in the WORKSPACE file. Run with
       -h for details.
+  graph - writes the import graph of Gleam modules or targets as DOT or JSON,
+      without updating any BUILD file. Run with -h for details.
   help - show this message.
 
 For usage information for a specific command, run the command with the -h flag.
@@ -0,0 +1,340 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/BurntSushi/toml"
+	"github.com/bazelbuild/buildtools/build"
+	"github.com/iocat/rules_gleam/internal/hex"
+)
+
+// errDrift is returned by the check subcommand when gleam.toml,
+// manifest.toml and MODULE.bazel don't match.
+var errDrift = errors.New("gleam.toml, manifest.toml and MODULE.bazel don't match")
+
+// repoPrefix is the prefix of the repositories the gleam module extension
+// creates for packages.
+const repoPrefix = "hex_"
+
+// ChangedPackage is a requirement of gleam.toml that manifest.toml doesn't
+// match anymore.
+type ChangedPackage struct {
+	ModuleName string `json:"module_name"`
+	// Requirement of manifest.toml and of gleam.toml: a version requirement,
+	// or where a git or local dependency comes from.
+	ManifestRequirement  string `json:"manifest_requirement"`
+	GleamTomlRequirement string `json:"gleam_toml_requirement"`
+	// Version locked by manifest.toml.
+	Locked string `json:"locked"`
+	// Why manifest.toml is stale for the requirement, as reported by resolve.
+	Reasons []string `json:"reasons"`
+}
+
+// Drift is the result of checking gleam.toml, manifest.toml and
+// MODULE.bazel against each other.
+type Drift struct {
+	OK bool `json:"ok"`
+	// Requirements of gleam.toml manifest.toml doesn't have.
+	Added []string `json:"added"`
+	// Requirements of manifest.toml gleam.toml no longer has.
+	Removed []string         `json:"removed"`
+	Changed []ChangedPackage `json:"changed"`
+	// Repositories of manifest.toml packages use_repo doesn't list, and the
+	// ones it lists for no package.
+	MissingUseRepo []string `json:"missing_use_repo"`
+	ExtraUseRepo   []string `json:"extra_use_repo"`
+	// Whether the use_repo call was rewritten.
+	Fixed bool `json:"fixed"`
+}
+
+// runCheck is the check subcommand: it reports the drift between gleam.toml,
+// manifest.toml and the use_repo of the gleam module extension, and can
+// rewrite the use_repo call.
+func runCheck(args []string, w io.Writer) error {
+	fs := flag.NewFlagSet("check", flag.ContinueOnError)
+	gleamTomlFlag := fs.String("gleam_toml", "", "Path to gleam.toml")
+	manifestFlag := fs.String("manifest", "", "Path to manifest.toml, defaults to the one next to gleam.toml")
+	moduleFlag := fs.String("module_bazel", "", "Path to MODULE.bazel, defaults to the one next to gleam.toml")
+	fixFlag := fs.Bool("fix", false, "Rewrite the use_repo call of MODULE.bazel to match manifest.toml")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if *gleamTomlFlag == "" {
+		return logAndExit(w, "gleam_toml flag is required")
+	}
+	dir := filepath.Dir(*gleamTomlFlag)
+	manifestFile := *manifestFlag
+	if manifestFile == "" {
+		manifestFile = filepath.Join(dir, "manifest.toml")
+	}
+	moduleFile := *moduleFlag
+	if moduleFile == "" {
+		moduleFile = filepath.Join(dir, "MODULE.bazel")
+	}
+
+	var gleamToml GleamToml
+	if _, err := toml.DecodeFile(*gleamTomlFlag, &gleamToml); err != nil {
+		return logAndExit(w, "failed to decode gleam.toml: %v", err)
+	}
+	requirements, err := gleamRequirements(&gleamToml)
+	if err != nil {
+		return logAndExit(w, "%v", err)
+	}
+	manifest, err := readManifest(manifestFile, w)
+	if err != nil {
+		return err
+	}
+	data, err := os.ReadFile(moduleFile)
+	if err != nil {
+		return logAndExit(w, "failed to read MODULE.bazel: %v", err)
+	}
+	module, err := build.ParseModule(moduleFile, data)
+	if err != nil {
+		return logAndExit(w, "failed to parse MODULE.bazel: %v", err)
+	}
+
+	drift := checkDrift(requirements, manifest, module)
+	if *fixFlag && (len(drift.MissingUseRepo) > 0 || len(drift.ExtraUseRepo) > 0) {
+		if err := fixUseRepo(module, manifest); err != nil {
+			return logAndExit(w, "failed to fix MODULE.bazel: %v", err)
+		}
+		if err := os.WriteFile(moduleFile, build.Format(module), 0644); err != nil {
+			return logAndExit(w, "failed to write MODULE.bazel: %v", err)
+		}
+		drift.Fixed = true
+	}
+
+	str := strings.Builder{}
+	encoder := json.NewEncoder(&str)
+	encoder.SetIndent("", "  ")
+	encoder.Encode(drift)
+	logResult(w, "%s", str.String())
+	if !drift.OK && !(drift.Fixed && len(drift.Added)+len(drift.Removed)+len(drift.Changed) == 0) {
+		return errDrift
+	}
+	return nil
+}
+
+// checkDrift compares the requirements of gleam.toml with the ones of
+// manifest.toml, like resolve does to tell whether manifest.toml is stale,
+// and the packages of manifest.toml with the repositories use_repo lists.
+func checkDrift(requirements map[string]ManifestRequirement, manifest *Manifest, module *build.File) Drift {
+	drift := Drift{
+		Added:          []string{},
+		Removed:        []string{},
+		Changed:        []ChangedPackage{},
+		MissingUseRepo: []string{},
+		ExtraUseRepo:   []string{},
+	}
+
+	locked := make(map[string]Package)
+	for _, pkg := range manifest.Packages {
+		locked[pkg.Name] = pkg
+	}
+	for name, requirement := range requirements {
+		recorded, ok := manifest.Requirements[name]
+		if !ok {
+			drift.Added = append(drift.Added, name)
+			continue
+		}
+		if reasons := staleRequirement(name, requirement, recorded, locked); len(reasons) > 0 {
+			slices.Sort(reasons)
+			drift.Changed = append(drift.Changed, ChangedPackage{
+				ModuleName:           name,
+				ManifestRequirement:  requirementString(recorded),
+				GleamTomlRequirement: requirementString(requirement),
+				Locked:               locked[name].Version,
+				Reasons:              reasons,
+			})
+		}
+	}
+	for name := range manifest.Requirements {
+		if _, ok := requirements[name]; !ok {
+			drift.Removed = append(drift.Removed, name)
+		}
+	}
+
+	listed := make(map[string]bool)
+	for _, call := range useRepoCalls(module) {
+		for _, repo := range useRepoNames(call) {
+			listed[repo] = true
+		}
+	}
+	for _, pkg := range manifest.Packages {
+		repo := repoPrefix + pkg.Name
+		if !listed[repo] {
+			drift.MissingUseRepo = append(drift.MissingUseRepo, repo)
+		}
+		delete(listed, repo)
+	}
+	for repo := range listed {
+		if strings.HasPrefix(repo, repoPrefix) {
+			drift.ExtraUseRepo = append(drift.ExtraUseRepo, repo)
+		}
+	}
+
+	slices.Sort(drift.Added)
+	slices.Sort(drift.Removed)
+	slices.SortFunc(drift.Changed, func(a, b ChangedPackage) int {
+		return strings.Compare(a.ModuleName, b.ModuleName)
+	})
+	slices.Sort(drift.MissingUseRepo)
+	slices.Sort(drift.ExtraUseRepo)
+	drift.OK = len(drift.Added) == 0 && len(drift.Removed) == 0 && len(drift.Changed) == 0 &&
+		len(drift.MissingUseRepo) == 0 && len(drift.ExtraUseRepo) == 0
+	return drift
+}
+
+// requirementString returns the version requirement of a Hex dependency, or
+// where a git or local dependency comes from.
+func requirementString(r ManifestRequirement) string {
+	if r.Source() == hex.SourceHex {
+		return r.Version
+	}
+	return r.String()
+}
+
+// gleamExtension returns the name of the variable holding the gleam module
+// extension, e.g. gleam in
+// gleam = use_extension("@rules_gleam//:extensions.bzl", "gleam").
+func gleamExtension(module *build.File) (string, int) {
+	for i, stmt := range module.Stmt {
+		assign, ok := stmt.(*build.AssignExpr)
+		if !ok {
+			continue
+		}
+		lhs, ok := assign.LHS.(*build.Ident)
+		if !ok {
+			continue
+		}
+		call, ok := assign.RHS.(*build.CallExpr)
+		if !ok || len(call.List) < 2 {
+			continue
+		}
+		if fn, ok := call.X.(*build.Ident); !ok || fn.Name != "use_extension" {
+			continue
+		}
+		file, ok1 := call.List[0].(*build.StringExpr)
+		name, ok2 := call.List[1].(*build.StringExpr)
+		if ok1 && ok2 && name.Value == "gleam" && strings.HasSuffix(file.Value, "//:extensions.bzl") {
+			return lhs.Name, i
+		}
+	}
+	return "", -1
+}
+
+// useRepoCalls returns the use_repo calls of the gleam module extension.
+func useRepoCalls(module *build.File) []*build.CallExpr {
+	extension, _ := gleamExtension(module)
+	if extension == "" {
+		return nil
+	}
+	var calls []*build.CallExpr
+	for _, stmt := range module.Stmt {
+		call, ok := stmt.(*build.CallExpr)
+		if !ok || len(call.List) == 0 {
+			continue
+		}
+		if fn, ok := call.X.(*build.Ident); !ok || fn.Name != "use_repo" {
+			continue
+		}
+		if arg, ok := call.List[0].(*build.Ident); ok && arg.Name == extension {
+			calls = append(calls, call)
+		}
+	}
+	return calls
+}
+
+// useRepoNames returns the repositories listed by name in a use_repo call.
+func useRepoNames(call *build.CallExpr) []string {
+	var names []string
+	for _, arg := range call.List[1:] {
+		if s, ok := arg.(*build.StringExpr); ok {
+			names = append(names, s.Value)
+		}
+	}
+	return names
+}
+
+// fixUseRepo rewrites the use_repo calls of the gleam module extension to
+// list the repositories of the manifest.toml packages. Other repositories
+// are kept, the ones of packages are all moved to the first call, and a
+// use_repo call is added if there's none.
+func fixUseRepo(module *build.File, manifest *Manifest) error {
+	extension, index := gleamExtension(module)
+	if extension == "" {
+		return fmt.Errorf("no use_extension of the gleam module extension")
+	}
+	calls := useRepoCalls(module)
+	if len(calls) == 0 {
+		call := &build.CallExpr{
+			X:    &build.Ident{Name: "use_repo"},
+			List: []build.Expr{&build.Ident{Name: extension}},
+		}
+		// After the last use of the extension.
+		for i, stmt := range module.Stmt[index+1:] {
+			if usesExtension(stmt, extension) {
+				index = index + 1 + i
+			}
+		}
+		module.Stmt = slices.Insert(module.Stmt, index+1, build.Expr(call))
+		calls = append(calls, call)
+	}
+
+	for _, call := range calls {
+		call.List = slices.DeleteFunc(call.List, func(arg build.Expr) bool {
+			s, ok := arg.(*build.StringExpr)
+			return ok && strings.HasPrefix(s.Value, repoPrefix)
+		})
+	}
+	first := calls[0]
+	var names []build.Expr
+	var kwargs []build.Expr
+	for _, arg := range first.List[1:] {
+		if _, ok := arg.(*build.StringExpr); ok {
+			names = append(names, arg)
+		} else {
+			kwargs = append(kwargs, arg)
+		}
+	}
+	for _, pkg := range manifest.Packages {
+		names = append(names, &build.StringExpr{Value: repoPrefix + pkg.Name})
+	}
+	slices.SortFunc(names, func(a, b build.Expr) int {
+		return strings.Compare(a.(*build.StringExpr).Value, b.(*build.StringExpr).Value)
+	})
+	names = slices.CompactFunc(names, func(a, b build.Expr) bool {
+		return a.(*build.StringExpr).Value == b.(*build.StringExpr).Value
+	})
+	first.List = append(append([]build.Expr{first.List[0]}, names...), kwargs...)
+
+	// Drop the other calls that list nothing anymore.
+	module.Stmt = slices.DeleteFunc(module.Stmt, func(stmt build.Expr) bool {
+		call, ok := stmt.(*build.CallExpr)
+		return ok && call != first && slices.Contains(calls, call) && len(call.List) == 1
+	})
+	return nil
+}
+
+// usesExtension reports whether stmt calls a method of the extension, e.g.
+// gleam.deps(...).
+func usesExtension(stmt build.Expr, extension string) bool {
+	call, ok := stmt.(*build.CallExpr)
+	if !ok {
+		return false
+	}
+	dot, ok := call.X.(*build.DotExpr)
+	if !ok {
+		return false
+	}
+	x, ok := dot.X.(*build.Ident)
+	return ok && x.Name == extension
+}
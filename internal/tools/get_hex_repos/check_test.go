@@ -0,0 +1,282 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+const checkManifest = `
+packages = [
+  { name = "gleam_json", version = "2.3.0", build_tools = ["gleam"], requirements = ["gleam_stdlib"], otp_app = "gleam_json", source = "hex", outer_checksum = "JSON" },
+  { name = "gleam_stdlib", version = "0.60.0", build_tools = ["gleam"], requirements = [], otp_app = "gleam_stdlib", source = "hex", outer_checksum = "STDLIB" },
+  { name = "gleeunit", version = "1.6.0", build_tools = ["gleam"], requirements = ["gleam_stdlib"], otp_app = "gleeunit", source = "hex", outer_checksum = "GLEEUNIT" },
+]
+
+[requirements]
+gleam_json = { version = ">= 2.0.0 and < 3.0.0" }
+gleam_stdlib = { version = ">= 0.44.0 and < 2.0.0" }
+gleeunit = { version = ">= 1.0.0 and < 2.0.0" }
+`
+
+// writeCheckFiles writes gleam.toml, manifest.toml and MODULE.bazel to a
+// temporary directory and returns the path of gleam.toml.
+func writeCheckFiles(t *testing.T, gleamToml, manifest, module string) string {
+	t.Helper()
+	dir := t.TempDir()
+	for name, content := range map[string]string{
+		"gleam.toml":    gleamToml,
+		"manifest.toml": manifest,
+		"MODULE.bazel":  module,
+	} {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return filepath.Join(dir, "gleam.toml")
+}
+
+func TestCheck(t *testing.T) {
+	testCases := []struct {
+		desc      string
+		gleamToml string
+		// Defaults to checkManifest.
+		manifest string
+		module   string
+		want     Drift
+	}{
+		{
+			desc: "up to date",
+			gleamToml: `
+[dependencies]
+gleam_json = ">= 2.0.0 and < 3.0.0"
+gleam_stdlib = ">= 0.44.0 and < 2.0.0"
+
+[dev-dependencies]
+gleeunit = ">= 1.0.0 and < 2.0.0"
+`,
+			module: `
+gleam = use_extension("@rules_gleam//:extensions.bzl", "gleam")
+gleam.deps(gleam_toml = "//:gleam.toml")
+use_repo(gleam, "gleam_toolchains", "hex_gleam_json", "hex_gleam_stdlib")
+use_repo(gleam, "hex_gleeunit")
+`,
+			want: Drift{OK: true},
+		},
+		{
+			desc: "drift",
+			gleamToml: `
+[dependencies]
+gleam_json = ">= 3.0.0 and < 4.0.0"
+gleam_stdlib = ">= 0.44.0 and < 2.0.0"
+lustre = ">= 5.0.0 and < 6.0.0"
+`,
+			module: `
+gleam = use_extension("@rules_gleam//:extensions.bzl", "gleam")
+gleam.deps(gleam_toml = "//:gleam.toml")
+use_repo(gleam, "hex_gleam_json", "hex_gleam_stdlib", "hex_gleam_otp")
+
+go_deps = use_extension("@gazelle//:extensions.bzl", "go_deps")
+use_repo(go_deps, "hex_gleeunit")
+`,
+			want: Drift{
+				Added:   []string{"lustre"},
+				Removed: []string{"gleeunit"},
+				Changed: []ChangedPackage{{
+					ModuleName:           "gleam_json",
+					ManifestRequirement:  ">= 2.0.0 and < 3.0.0",
+					GleamTomlRequirement: ">= 3.0.0 and < 4.0.0",
+					Locked:               "2.3.0",
+					Reasons: []string{
+						`locked gleam_json 2.3.0 doesn't match ">= 3.0.0 and < 4.0.0"`,
+						`requirement of gleam_json changed from ">= 2.0.0 and < 3.0.0" to ">= 3.0.0 and < 4.0.0"`,
+					},
+				}},
+				MissingUseRepo: []string{"hex_gleeunit"},
+				ExtraUseRepo:   []string{"hex_gleam_otp"},
+			},
+		},
+		{
+			desc: "table requirements",
+			gleamToml: `
+[dependencies]
+gleam_json = { version = ">= 3.0.0 and < 4.0.0" }
+gleam_stdlib = { version = ">= 0.44.0 and < 2.0.0" }
+
+[dev-dependencies]
+gleeunit = ">= 1.0.0 and < 2.0.0"
+`,
+			module: `
+gleam = use_extension("@rules_gleam//:extensions.bzl", "gleam")
+use_repo(gleam, "hex_gleam_json", "hex_gleam_stdlib", "hex_gleeunit")
+`,
+			want: Drift{
+				Changed: []ChangedPackage{{
+					ModuleName:           "gleam_json",
+					ManifestRequirement:  ">= 2.0.0 and < 3.0.0",
+					GleamTomlRequirement: ">= 3.0.0 and < 4.0.0",
+					Locked:               "2.3.0",
+					Reasons: []string{
+						`locked gleam_json 2.3.0 doesn't match ">= 3.0.0 and < 4.0.0"`,
+						`requirement of gleam_json changed from ">= 2.0.0 and < 3.0.0" to ">= 3.0.0 and < 4.0.0"`,
+					},
+				}},
+			},
+		},
+		{
+			desc: "git and local requirements",
+			gleamToml: `
+[dependencies]
+forked = { git = "https://example.com/forked.git", ref = "v2" }
+shared = { path = "../common" }
+unchanged = { path = "../unchanged" }
+`,
+			manifest: `
+packages = [
+  { name = "forked", version = "1.2.0", build_tools = ["gleam"], requirements = [], otp_app = "forked", source = "git", repo = "https://example.com/forked.git", commit = "abc123" },
+  { name = "shared", version = "0.1.0", build_tools = ["gleam"], requirements = [], otp_app = "shared", source = "local", path = "../shared" },
+  { name = "unchanged", version = "0.2.0", build_tools = ["gleam"], requirements = [], otp_app = "unchanged", source = "local", path = "../unchanged" },
+]
+
+[requirements]
+forked = { git = "https://example.com/forked.git", ref = "main" }
+shared = { path = "../shared" }
+unchanged = { path = "../unchanged" }
+`,
+			module: `
+gleam = use_extension("@rules_gleam//:extensions.bzl", "gleam")
+use_repo(gleam, "hex_forked", "hex_shared", "hex_unchanged")
+`,
+			want: Drift{
+				Changed: []ChangedPackage{{
+					ModuleName:           "forked",
+					ManifestRequirement:  "git https://example.com/forked.git at main",
+					GleamTomlRequirement: "git https://example.com/forked.git at v2",
+					Locked:               "1.2.0",
+					Reasons: []string{
+						"requirement of forked changed from git https://example.com/forked.git at main to git https://example.com/forked.git at v2",
+					},
+				}, {
+					ModuleName:           "shared",
+					ManifestRequirement:  "path ../shared",
+					GleamTomlRequirement: "path ../common",
+					Locked:               "0.1.0",
+					Reasons: []string{
+						"locked shared is from ../shared, gleam.toml requires ../common",
+						"requirement of shared changed from path ../shared to path ../common",
+					},
+				}},
+			},
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.desc, func(t *testing.T) {
+			manifest := tc.manifest
+			if manifest == "" {
+				manifest = checkManifest
+			}
+			gleamToml := writeCheckFiles(t, tc.gleamToml, manifest, tc.module)
+			var stdout bytes.Buffer
+			err := runCheck([]string{"--gleam_toml", gleamToml}, &stdout)
+			if tc.want.OK && err != nil {
+				t.Fatalf("runCheck failed: %v", err)
+			}
+			if !tc.want.OK && !errors.Is(err, errDrift) {
+				t.Fatalf("runCheck error = %v, want %v", err, errDrift)
+			}
+			var got Drift
+			if err := json.Unmarshal(stdout.Bytes(), &got); err != nil {
+				t.Fatalf("failed to unmarshal json: %v", err)
+			}
+			for _, s := range []*[]string{&tc.want.Added, &tc.want.Removed, &tc.want.MissingUseRepo, &tc.want.ExtraUseRepo} {
+				if *s == nil {
+					*s = []string{}
+				}
+			}
+			if tc.want.Changed == nil {
+				tc.want.Changed = []ChangedPackage{}
+			}
+			if diff := cmp.Diff(tc.want, got); diff != "" {
+				t.Errorf("drift mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestCheckFix(t *testing.T) {
+	gleamToml := `
+[dependencies]
+gleam_json = ">= 2.0.0 and < 3.0.0"
+gleam_stdlib = ">= 0.44.0 and < 2.0.0"
+
+[dev-dependencies]
+gleeunit = ">= 1.0.0 and < 2.0.0"
+`
+	testCases := []struct {
+		desc   string
+		module string
+		want   string
+	}{
+		{
+			desc: "rewrite",
+			module: `module(name = "app")
+
+gleam = use_extension("@rules_gleam//:extensions.bzl", "gleam")
+gleam.deps(gleam_toml = "//:gleam.toml")
+use_repo(gleam, "hex_gleam_otp", "gleam_toolchains", "hex_gleam_json")
+use_repo(gleam, "hex_gleeunit", "hex_lustre")
+`,
+			want: `module(name = "app")
+
+gleam = use_extension("@rules_gleam//:extensions.bzl", "gleam")
+gleam.deps(gleam_toml = "//:gleam.toml")
+use_repo(gleam, "gleam_toolchains", "hex_gleam_json", "hex_gleam_stdlib", "hex_gleeunit")
+`,
+		},
+		{
+			desc: "no use_repo",
+			module: `module(name = "app")
+
+gleam = use_extension("@rules_gleam//:extensions.bzl", "gleam")
+gleam.deps(gleam_toml = "//:gleam.toml")
+
+bazel_dep(name = "rules_go", version = "0.57.0")
+`,
+			want: `module(name = "app")
+
+gleam = use_extension("@rules_gleam//:extensions.bzl", "gleam")
+gleam.deps(gleam_toml = "//:gleam.toml")
+use_repo(gleam, "hex_gleam_json", "hex_gleam_stdlib", "hex_gleeunit")
+
+bazel_dep(name = "rules_go", version = "0.57.0")
+`,
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.desc, func(t *testing.T) {
+			path := writeCheckFiles(t, gleamToml, checkManifest, tc.module)
+			var stdout bytes.Buffer
+			if err := runCheck([]string{"--gleam_toml", path, "--fix"}, &stdout); err != nil {
+				t.Fatalf("runCheck --fix failed: %v", err)
+			}
+			got, err := os.ReadFile(filepath.Join(filepath.Dir(path), "MODULE.bazel"))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if diff := cmp.Diff(tc.want, string(got)); diff != "" {
+				t.Errorf("MODULE.bazel mismatch (-want +got):\n%s", diff)
+			}
+
+			// The rewritten MODULE.bazel has no drift left.
+			stdout.Reset()
+			if err := runCheck([]string{"--gleam_toml", path}, &stdout); err != nil {
+				t.Errorf("runCheck after --fix failed: %v\n%s", err, stdout.String())
+			}
+		})
+	}
+}
@@ -1,21 +1,28 @@
 package main
 
 import (
+	"crypto/sha256"
 	"encoding/json"
+	"errors"
 	"flag"
 	"fmt"
 	"io"
-	"iter"
 	"os"
+	"path/filepath"
+	"slices"
 	"strings"
 
-	"github.com/BurntSushi/toml"
+	"github.com/iocat/rules_gleam/internal/hex"
 )
 
 var (
 	manifestFlag = flag.String("manifest", "", "Path to manifest.toml")
+	verifyFlag   = flag.String("verify", "", "Directory of downloaded Hex tarballs to check against the manifest.toml checksums instead")
 )
 
+// errVerifyFailed is returned when the tarballs don't match the manifest.
+var errVerifyFailed = errors.New("tarballs don't match manifest.toml")
+
 func logAndExit(w io.Writer, format string, params ...any) error {
 	return fmt.Errorf(format, params...)
 }
@@ -24,19 +31,13 @@ func logResult(w io.Writer, format string, params ...any) {
 	fmt.Fprintf(w, format, params...)
 }
 
-type Package struct {
-	Name          string   `toml:"name"`
-	Version       string   `toml:"version"`
-	BuildTools    []string `toml:"build_tools"`
-	Requirements  []string `toml:"requirements"`
-	OtpApp        string   `toml:"otp_app"`
-	Source        string   `toml:"source"`
-	OuterChecksum string   `toml:"outer_checksum"`
-}
-
-type Manifest struct {
-	Packages []Package `toml:"packages"`
-}
+// Package, Manifest and ManifestRequirement model manifest.toml, like
+// gazelle reads it.
+type (
+	Package             = hex.Package
+	Manifest            = hex.Manifest
+	ManifestRequirement = hex.Requirement
+)
 
 type Graph struct {
 	Nodes map[string]*GleamRepo
@@ -44,11 +45,16 @@ type Graph struct {
 }
 
 type GleamRepo struct {
-	ModuleName string   `json:"module_name"`
-	Version    string   `json:"version"`
-	Checksum   string   `json:"checksum"`
-	OtpApp     string   `json:"otp_app"`
-	Deps       []string `json:"deps"`
+	ModuleName string `json:"module_name"`
+	Version    string `json:"version"`
+	// One of the sources of internal/hex, e.g. "hex".
+	Source   string   `json:"source"`
+	Checksum string   `json:"checksum,omitempty"`
+	Repo     string   `json:"repo,omitempty"`
+	Commit   string   `json:"commit,omitempty"`
+	Path     string   `json:"path,omitempty"`
+	OtpApp   string   `json:"otp_app"`
+	Deps     []string `json:"deps"`
 }
 
 type GleamRepoes struct {
@@ -56,6 +62,20 @@ type GleamRepoes struct {
 }
 
 func main() {
+	if len(os.Args) > 1 {
+		subcommands := map[string]func([]string, io.Writer) error{
+			"check":   runCheck,
+			"resolve": runResolve,
+		}
+		if subcommand, ok := subcommands[os.Args[1]]; ok {
+			if err := subcommand(os.Args[2:], os.Stdout); err != nil {
+				fmt.Fprintf(os.Stderr, "%v\n", err)
+				os.Exit(1)
+			}
+			return
+		}
+	}
+
 	flag.Parse()
 
 	manifestFile := *manifestFlag
@@ -64,89 +84,315 @@ func main() {
 		os.Exit(1)
 	}
 
+	if *verifyFlag != "" {
+		if err := verify(manifestFile, *verifyFlag, os.Stdout); err != nil {
+			fmt.Fprintf(os.Stderr, "%v\n", err)
+			os.Exit(1)
+		}
+		return
+	}
+
 	if err := run(manifestFile, os.Stdout); err != nil {
 		fmt.Fprintf(os.Stderr, "%v\n", err)
 		os.Exit(1)
 	}
 }
 
-func run(manifestFile string, w io.Writer) error {
-	var (
-		content  []byte
-		manifest Manifest
-		err      error
-	)
-	if content, err = os.ReadFile(manifestFile); err != nil {
-		return logAndExit(w, "failed to read manifest file: %v", err)
+func readManifest(manifestFile string, w io.Writer) (*Manifest, error) {
+	manifest, err := hex.ReadManifest(manifestFile)
+	if err != nil {
+		return nil, logAndExit(w, "failed to read manifest: %v", err)
 	}
-	if _, err := toml.Decode(string(content), &manifest); err != nil {
-		return logAndExit(w, "failed to decode manifest: %v", err)
+	return manifest, nil
+}
+
+func run(manifestFile string, w io.Writer) error {
+	manifest, err := readManifest(manifestFile, w)
+	if err != nil {
+		return err
 	}
 
 	repos := GleamRepoes{}
 	for _, pkg := range manifest.Packages {
-		repos.Repos = append(repos.Repos, GleamRepo{
+		repo := GleamRepo{
 			ModuleName: pkg.Name,
 			Version:    pkg.Version,
-			Checksum:   pkg.OuterChecksum,
+			Source:     pkg.Origin(),
 			OtpApp:     pkg.OtpApp,
 			Deps:       pkg.Requirements,
-		})
+		}
+		switch repo.Source {
+		case hex.SourceHex:
+			repo.Checksum = pkg.OuterChecksum
+		case hex.SourceGit:
+			if pkg.Repo == "" || pkg.Commit == "" {
+				return logAndExit(w, "git package %s needs a repo and a commit", pkg.Name)
+			}
+			repo.Repo = pkg.Repo
+			repo.Commit = pkg.Commit
+		case hex.SourceLocal:
+			if pkg.Path == "" {
+				return logAndExit(w, "local package %s needs a path", pkg.Name)
+			}
+			repo.Path = pkg.Path
+		default:
+			return logAndExit(w, "package %s has unknown source %q", pkg.Name, pkg.Source)
+		}
+		repos.Repos = append(repos.Repos, repo)
 	}
 
 	// Topologically sort repos
-	g := Graph{
-		Nodes: make(map[string]*GleamRepo),
-		Edges: make(map[string][]string),
+	g, err := newGraph(repos.Repos)
+	if err != nil {
+		return logAndExit(w, "failed to sort packages: %v", err)
+	}
+	sorted, err := g.Topologically()
+	if err != nil {
+		return logAndExit(w, "failed to sort packages: %v", err)
+	}
+	repos.Repos = make([]GleamRepo, 0, len(sorted))
+	for _, node := range sorted {
+		repos.Repos = append(repos.Repos, *node)
+	}
+
+	str := strings.Builder{}
+	encoder := json.NewEncoder(&str)
+	encoder.SetIndent("", "  ")
+	encoder.Encode(repos)
+	logResult(w, "%s", str.String())
+	return nil
+}
+
+// TarballMismatch is a tarball whose SHA-256 isn't the outer checksum of its
+// package.
+type TarballMismatch struct {
+	ModuleName string `json:"module_name"`
+	Version    string `json:"version"`
+	File       string `json:"file"`
+	Want       string `json:"want"`
+	Got        string `json:"got"`
+}
+
+// MissingTarball is a package of the manifest without a tarball.
+type MissingTarball struct {
+	ModuleName string `json:"module_name"`
+	Version    string `json:"version"`
+	File       string `json:"file"`
+}
+
+// Verification is the result of checking a directory of tarballs against a
+// manifest.
+type Verification struct {
+	OK         bool              `json:"ok"`
+	Mismatches []TarballMismatch `json:"mismatches"`
+	Missing    []MissingTarball  `json:"missing"`
+	// Tarballs of no package of the manifest.
+	Extra []string `json:"extra"`
+}
+
+// tarballName returns the file name of the tarball of a package, like in
+// https://repo.hex.pm/tarballs/.
+func tarballName(pkg Package) string {
+	return fmt.Sprintf("%s-%s.tar", pkg.Name, pkg.Version)
+}
+
+// verify checks the Hex tarballs in dir against the outer checksums of the
+// manifest, and writes the Verification. It returns errVerifyFailed when
+// they don't match.
+func verify(manifestFile, dir string, w io.Writer) error {
+	manifest, err := readManifest(manifestFile, w)
+	if err != nil {
+		return err
 	}
-	for _, repo := range repos.Repos {
-		g.Nodes[repo.ModuleName] = &repo
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return logAndExit(w, "failed to read tarballs directory: %v", err)
+	}
+	tarballs := make(map[string]bool)
+	for _, entry := range entries {
+		if !entry.IsDir() && strings.HasSuffix(entry.Name(), ".tar") {
+			tarballs[entry.Name()] = true
+		}
+	}
+
+	result := Verification{
+		Mismatches: []TarballMismatch{},
+		Missing:    []MissingTarball{},
+		Extra:      []string{},
 	}
 	for _, pkg := range manifest.Packages {
-		for _, dep := range pkg.Requirements {
-			if edges, ok := g.Edges[dep]; !ok {
-				g.Edges[dep] = []string{pkg.Name}
-			} else {
-				g.Edges[dep] = append(edges, pkg.Name)
-			}
+		if pkg.Origin() != hex.SourceHex {
+			continue
+		}
+		file := tarballName(pkg)
+		if !tarballs[file] {
+			result.Missing = append(result.Missing, MissingTarball{
+				ModuleName: pkg.Name,
+				Version:    pkg.Version,
+				File:       file,
+			})
+			continue
+		}
+		delete(tarballs, file)
+		got, err := sha256File(filepath.Join(dir, file))
+		if err != nil {
+			return logAndExit(w, "failed to read tarball: %v", err)
+		}
+		if !strings.EqualFold(got, pkg.OuterChecksum) {
+			result.Mismatches = append(result.Mismatches, TarballMismatch{
+				ModuleName: pkg.Name,
+				Version:    pkg.Version,
+				File:       file,
+				Want:       pkg.OuterChecksum,
+				Got:        got,
+			})
 		}
 	}
-	repos.Repos = make([]GleamRepo, 0)
-	for node := range g.Topologically() {
-		repos.Repos = append(repos.Repos, *node)
+	for file := range tarballs {
+		result.Extra = append(result.Extra, file)
 	}
+	slices.Sort(result.Extra)
+	result.OK = len(result.Mismatches) == 0 && len(result.Missing) == 0 && len(result.Extra) == 0
 
 	str := strings.Builder{}
 	encoder := json.NewEncoder(&str)
 	encoder.SetIndent("", "  ")
-	encoder.Encode(repos)
+	encoder.Encode(result)
 	logResult(w, "%s", str.String())
+	if !result.OK {
+		return errVerifyFailed
+	}
 	return nil
 }
 
-func (g *Graph) Topologically() iter.Seq[*GleamRepo] {
-	return func(yield func(*GleamRepo) bool) {
-		visited := make(map[string]bool)
-		stack := make([]*GleamRepo, 0, len(g.Nodes))
-		for node := range g.Nodes {
-			if !visited[node] {
-				g.dfs(node, visited, &stack)
+// sha256File returns the SHA-256 of a file, in upper case hexadecimal like
+// the outer checksums of manifest.toml.
+func sha256File(path string) (string, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return "", err
+	}
+	defer f.Close()
+	hash := sha256.New()
+	if _, err := io.Copy(hash, f); err != nil {
+		return "", err
+	}
+	return fmt.Sprintf("%X", hash.Sum(nil)), nil
+}
+
+// MissingPackageError is returned when a package requires one that isn't in
+// the manifest.
+type MissingPackageError struct {
+	Package     string
+	Requirement string
+}
+
+func (e *MissingPackageError) Error() string {
+	return fmt.Sprintf("package %s requires %s, which is not in the manifest", e.Package, e.Requirement)
+}
+
+// CycleError is returned when packages require each other in a cycle.
+type CycleError struct {
+	// Packages of the cycle, each requiring the next one, starting and ending
+	// with the same package.
+	Path []string
+}
+
+func (e *CycleError) Error() string {
+	return fmt.Sprintf("packages require each other in a cycle: %s", strings.Join(e.Path, " -> "))
+}
+
+// newGraph returns the graph of the requirements between repos.
+func newGraph(repos []GleamRepo) (*Graph, error) {
+	g := &Graph{
+		Nodes: make(map[string]*GleamRepo, len(repos)),
+		Edges: make(map[string][]string),
+	}
+	for i := range repos {
+		g.Nodes[repos[i].ModuleName] = &repos[i]
+	}
+	for _, repo := range repos {
+		seen := make(map[string]bool)
+		for _, dep := range repo.Deps {
+			if _, ok := g.Nodes[dep]; !ok {
+				return nil, &MissingPackageError{Package: repo.ModuleName, Requirement: dep}
+			}
+			if seen[dep] {
+				continue
 			}
+			seen[dep] = true
+			g.Edges[dep] = append(g.Edges[dep], repo.ModuleName)
 		}
-		for len(stack) > 0 {
-			if !yield(stack[len(stack)-1]) {
-				return
+	}
+	return g, nil
+}
+
+// Topologically returns the repos, each after the ones it requires. Repos
+// ready at the same time are ordered by name.
+func (g *Graph) Topologically() ([]*GleamRepo, error) {
+	inDegree := make(map[string]int, len(g.Nodes))
+	for node := range g.Nodes {
+		inDegree[node] = 0
+	}
+	for _, dependents := range g.Edges {
+		for _, dependent := range dependents {
+			inDegree[dependent]++
+		}
+	}
+	var ready []string
+	for node, degree := range inDegree {
+		if degree == 0 {
+			ready = append(ready, node)
+		}
+	}
+	slices.Sort(ready)
+
+	sorted := make([]*GleamRepo, 0, len(g.Nodes))
+	for len(ready) > 0 {
+		node := ready[0]
+		ready = ready[1:]
+		sorted = append(sorted, g.Nodes[node])
+		for _, dependent := range g.Edges[node] {
+			inDegree[dependent]--
+			if inDegree[dependent] == 0 {
+				i, _ := slices.BinarySearch(ready, dependent)
+				ready = slices.Insert(ready, i, dependent)
 			}
-			stack = stack[:len(stack)-1]
 		}
 	}
+	if len(sorted) < len(g.Nodes) {
+		return nil, &CycleError{Path: g.cycle(inDegree)}
+	}
+	return sorted, nil
 }
-func (g *Graph) dfs(node string, visited map[string]bool, stack *[]*GleamRepo) {
-	visited[node] = true
-	for _, neighbor := range g.Edges[node] {
-		if !visited[neighbor] {
-			g.dfs(neighbor, visited, stack)
+
+// cycle returns a cycle among the nodes left with requirements after
+// sorting. Each of them requires at least one other, so following the first
+// one by name always ends up in a cycle.
+func (g *Graph) cycle(inDegree map[string]int) []string {
+	var left []string
+	for node, degree := range inDegree {
+		if degree > 0 {
+			left = append(left, node)
+		}
+	}
+	slices.Sort(left)
+
+	var path []string
+	index := make(map[string]int)
+	for node := left[0]; ; {
+		if i, ok := index[node]; ok {
+			return append(path[i:], node)
+		}
+		index[node] = len(path)
+		path = append(path, node)
+		deps := slices.Clone(g.Nodes[node].Deps)
+		slices.Sort(deps)
+		for _, dep := range deps {
+			if inDegree[dep] > 0 {
+				node = dep
+				break
+			}
 		}
 	}
-	*stack = append(*stack, g.Nodes[node])
 }
@@ -2,10 +2,16 @@ package main
 
 import (
 	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
 	"encoding/json"
+	"errors"
+	"fmt"
 	"os"
 	"path/filepath"
 	"testing"
+
+	"github.com/google/go-cmp/cmp"
 )
 
 func TestGetHexRepos(t *testing.T) {
@@ -68,3 +74,245 @@ packages = [
 		t.Errorf("expected otp_app gleeunit_app, got %s", gleeunitRepo.OtpApp)
 	}
 }
+
+func TestVerify(t *testing.T) {
+	checksum := func(content string) string {
+		sum := sha256.Sum256([]byte(content))
+		return hex.EncodeToString(sum[:])
+	}
+	manifestContent := fmt.Sprintf(`
+packages = [
+  { name = "gleam_stdlib", version = "0.34.0", otp_app = "stdlib", source = "hex", outer_checksum = "%s" },
+  { name = "gleeunit", version = "1.0.0", otp_app = "gleeunit_app", source = "hex", outer_checksum = "%s" },
+  { name = "gleam_erlang", version = "0.25.0", otp_app = "erlang", source = "hex", outer_checksum = "%s" },
+]
+`, checksum("stdlib"), checksum("gleeunit"), checksum("erlang"))
+	tmpDir := t.TempDir()
+	manifestPath := filepath.Join(tmpDir, "manifest.toml")
+	if err := os.WriteFile(manifestPath, []byte(manifestContent), 0644); err != nil {
+		t.Fatalf("failed to write manifest file: %v", err)
+	}
+
+	testCases := []struct {
+		desc     string
+		tarballs map[string]string
+		want     Verification
+	}{
+		{
+			desc: "matching tarballs",
+			tarballs: map[string]string{
+				"gleam_stdlib-0.34.0.tar": "stdlib",
+				"gleeunit-1.0.0.tar":      "gleeunit",
+				"gleam_erlang-0.25.0.tar": "erlang",
+				"README":                  "not a tarball",
+			},
+			want: Verification{
+				OK:         true,
+				Mismatches: []TarballMismatch{},
+				Missing:    []MissingTarball{},
+				Extra:      []string{},
+			},
+		},
+		{
+			desc: "tampered, missing and extra tarballs",
+			tarballs: map[string]string{
+				"gleam_stdlib-0.34.0.tar": "stdlib",
+				"gleeunit-1.0.0.tar":      "tampered",
+				"gleam_erlang-0.24.0.tar": "erlang",
+			},
+			want: Verification{
+				Mismatches: []TarballMismatch{{
+					ModuleName: "gleeunit",
+					Version:    "1.0.0",
+					File:       "gleeunit-1.0.0.tar",
+					Want:       checksum("gleeunit"),
+					Got:        fmt.Sprintf("%X", sha256.Sum256([]byte("tampered"))),
+				}},
+				Missing: []MissingTarball{{
+					ModuleName: "gleam_erlang",
+					Version:    "0.25.0",
+					File:       "gleam_erlang-0.25.0.tar",
+				}},
+				Extra: []string{"gleam_erlang-0.24.0.tar"},
+			},
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.desc, func(t *testing.T) {
+			dir := t.TempDir()
+			for name, content := range tc.tarballs {
+				if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0644); err != nil {
+					t.Fatalf("failed to write tarball: %v", err)
+				}
+			}
+
+			var stdout bytes.Buffer
+			err := verify(manifestPath, dir, &stdout)
+			if tc.want.OK && err != nil {
+				t.Fatalf("verify failed: %v", err)
+			}
+			if !tc.want.OK && !errors.Is(err, errVerifyFailed) {
+				t.Fatalf("verify error = %v, want %v", err, errVerifyFailed)
+			}
+
+			var got Verification
+			if err := json.Unmarshal(stdout.Bytes(), &got); err != nil {
+				t.Fatalf("failed to unmarshal json: %v", err)
+			}
+			if diff := cmp.Diff(tc.want, got); diff != "" {
+				t.Errorf("verify mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestGetHexReposSources(t *testing.T) {
+	manifestContent := `
+packages = [
+  { name = "gleam_stdlib", version = "0.60.0", otp_app = "gleam_stdlib", source = "hex", outer_checksum = "outersum_stdlib" },
+  { name = "forked", version = "1.2.0", otp_app = "forked", requirements = ["gleam_stdlib"], source = "git", repo = "https://github.com/someone/forked", commit = "0123456789abcdef" },
+  { name = "shared", version = "0.1.0", otp_app = "shared", requirements = ["gleam_stdlib"], source = "local", path = "../shared" },
+]
+`
+	manifestPath := filepath.Join(t.TempDir(), "manifest.toml")
+	if err := os.WriteFile(manifestPath, []byte(manifestContent), 0644); err != nil {
+		t.Fatalf("failed to write manifest file: %v", err)
+	}
+
+	var stdout bytes.Buffer
+	if err := run(manifestPath, &stdout); err != nil {
+		t.Fatalf("run failed: %v", err)
+	}
+	var result GleamRepoes
+	if err := json.Unmarshal(stdout.Bytes(), &result); err != nil {
+		t.Fatalf("failed to unmarshal json: %v", err)
+	}
+
+	got := make(map[string]GleamRepo)
+	for _, repo := range result.Repos {
+		got[repo.ModuleName] = repo
+	}
+	want := map[string]GleamRepo{
+		"gleam_stdlib": {ModuleName: "gleam_stdlib", Version: "0.60.0", Source: "hex", Checksum: "outersum_stdlib", OtpApp: "gleam_stdlib"},
+		"forked":       {ModuleName: "forked", Version: "1.2.0", Source: "git", Repo: "https://github.com/someone/forked", Commit: "0123456789abcdef", OtpApp: "forked", Deps: []string{"gleam_stdlib"}},
+		"shared":       {ModuleName: "shared", Version: "0.1.0", Source: "local", Path: "../shared", OtpApp: "shared", Deps: []string{"gleam_stdlib"}},
+	}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("run mismatch (-want +got):\n%s", diff)
+	}
+}
+
+func TestGetHexReposInvalidSources(t *testing.T) {
+	for desc, pkg := range map[string]string{
+		"git without commit": `{ name = "forked", version = "1.2.0", source = "git", repo = "https://github.com/someone/forked" }`,
+		"local without path": `{ name = "shared", version = "0.1.0", source = "local" }`,
+		"unknown source":     `{ name = "other", version = "0.1.0", source = "svn" }`,
+	} {
+		t.Run(desc, func(t *testing.T) {
+			manifestPath := filepath.Join(t.TempDir(), "manifest.toml")
+			if err := os.WriteFile(manifestPath, []byte("packages = [\n  "+pkg+",\n]\n"), 0644); err != nil {
+				t.Fatalf("failed to write manifest file: %v", err)
+			}
+			if err := run(manifestPath, &bytes.Buffer{}); err == nil {
+				t.Errorf("run succeeded, want an error")
+			}
+		})
+	}
+}
+
+func TestTopologically(t *testing.T) {
+	testCases := []struct {
+		desc string
+		// Requirements of each package, in manifest order.
+		repos       []GleamRepo
+		want        []string
+		wantMissing *MissingPackageError
+		wantCycle   *CycleError
+	}{
+		{
+			desc: "ties broken by name",
+			repos: []GleamRepo{
+				{ModuleName: "gleeunit", Deps: []string{"gleam_stdlib"}},
+				{ModuleName: "gleam_json", Deps: []string{"gleam_stdlib"}},
+				{ModuleName: "gleam_stdlib"},
+				{ModuleName: "argv"},
+			},
+			want: []string{"argv", "gleam_stdlib", "gleam_json", "gleeunit"},
+		},
+		{
+			desc: "chain",
+			repos: []GleamRepo{
+				{ModuleName: "wisp", Deps: []string{"mist", "gleam_http"}},
+				{ModuleName: "mist", Deps: []string{"gleam_http", "gleam_http"}},
+				{ModuleName: "gleam_http", Deps: []string{"gleam_stdlib"}},
+				{ModuleName: "gleam_stdlib"},
+			},
+			want: []string{"gleam_stdlib", "gleam_http", "mist", "wisp"},
+		},
+		{
+			desc: "missing requirement",
+			repos: []GleamRepo{
+				{ModuleName: "gleam_json", Deps: []string{"gleam_stdlib"}},
+			},
+			wantMissing: &MissingPackageError{Package: "gleam_json", Requirement: "gleam_stdlib"},
+		},
+		{
+			desc: "cycle",
+			repos: []GleamRepo{
+				{ModuleName: "gleam_stdlib"},
+				{ModuleName: "c", Deps: []string{"a"}},
+				{ModuleName: "b", Deps: []string{"c", "gleam_stdlib"}},
+				{ModuleName: "a", Deps: []string{"b"}},
+				{ModuleName: "d", Deps: []string{"a"}},
+			},
+			wantCycle: &CycleError{Path: []string{"a", "b", "c", "a"}},
+		},
+		{
+			desc: "self requirement",
+			repos: []GleamRepo{
+				{ModuleName: "a", Deps: []string{"a"}},
+			},
+			wantCycle: &CycleError{Path: []string{"a", "a"}},
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.desc, func(t *testing.T) {
+			g, err := newGraph(tc.repos)
+			if tc.wantMissing != nil {
+				var missing *MissingPackageError
+				if !errors.As(err, &missing) {
+					t.Fatalf("newGraph error = %v, want a MissingPackageError", err)
+				}
+				if diff := cmp.Diff(tc.wantMissing, missing); diff != "" {
+					t.Errorf("newGraph error mismatch (-want +got):\n%s", diff)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("newGraph failed: %v", err)
+			}
+
+			sorted, err := g.Topologically()
+			if tc.wantCycle != nil {
+				var cycle *CycleError
+				if !errors.As(err, &cycle) {
+					t.Fatalf("Topologically error = %v, want a CycleError", err)
+				}
+				if diff := cmp.Diff(tc.wantCycle, cycle); diff != "" {
+					t.Errorf("Topologically error mismatch (-want +got):\n%s", diff)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Topologically failed: %v", err)
+			}
+			var got []string
+			for _, repo := range sorted {
+				got = append(got, repo.ModuleName)
+			}
+			if diff := cmp.Diff(tc.want, got); diff != "" {
+				t.Errorf("Topologically mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
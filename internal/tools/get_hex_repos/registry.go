@@ -0,0 +1,118 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+
+	"github.com/BurntSushi/toml"
+)
+
+// Release is a release of a package in a registry snapshot.
+type Release struct {
+	Version       string   `toml:"version"`
+	OuterChecksum string   `toml:"outer_checksum"`
+	BuildTools    []string `toml:"build_tools"`
+	OtpApp        string   `toml:"otp_app"`
+	// Version requirements of the release by package.
+	Requirements map[string]string `toml:"requirements"`
+	// Retired releases are only picked when they're locked.
+	Retired bool `toml:"retired"`
+}
+
+type registryPackage struct {
+	Releases []Release `toml:"releases"`
+}
+
+// Registry is a local snapshot of the Hex registry: a directory with a
+// <package>.toml file per package, listing its releases.
+type Registry struct {
+	dir      string
+	packages map[string][]release
+}
+
+// release is a Release with its version and requirements parsed.
+type release struct {
+	Release
+	version      Version
+	requirements map[string]VersionSet
+	// The package of manifest.toml a pinned release stands for.
+	pinned *Package
+}
+
+func newRegistry(dir string) *Registry {
+	return &Registry{dir: dir, packages: make(map[string][]release)}
+}
+
+// releases returns the releases of a package, newest first. Unknown
+// packages have none.
+func (r *Registry) releases(name string) ([]release, error) {
+	if releases, ok := r.packages[name]; ok {
+		return releases, nil
+	}
+	var pkg registryPackage
+	if _, err := toml.DecodeFile(filepath.Join(r.dir, name+".toml"), &pkg); err != nil {
+		if os.IsNotExist(err) {
+			r.packages[name] = nil
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to read registry package %s: %w", name, err)
+	}
+	var releases []release
+	for _, rel := range pkg.Releases {
+		version, err := parseVersion(rel.Version)
+		if err != nil {
+			return nil, fmt.Errorf("registry package %s: %w", name, err)
+		}
+		requirements := make(map[string]VersionSet, len(rel.Requirements))
+		for dep, requirement := range rel.Requirements {
+			set, err := parseRequirement(requirement)
+			if err != nil {
+				return nil, fmt.Errorf("registry package %s %s: %w", name, rel.Version, err)
+			}
+			requirements[dep] = set
+		}
+		releases = append(releases, release{Release: rel, version: version, requirements: requirements})
+	}
+	slices.SortFunc(releases, func(a, b release) int {
+		return b.version.Compare(a.version)
+	})
+	r.packages[name] = releases
+	return releases, nil
+}
+
+// pin makes the release locked by manifest.toml the only release of a
+// package that isn't resolved against the registry, e.g. a git or local one. Its
+// requirements allow any version, manifest.toml only has their names.
+func (r *Registry) pin(pkg Package) error {
+	version, err := parseVersion(pkg.Version)
+	if err != nil {
+		return fmt.Errorf("manifest.toml package %s: %w", pkg.Name, err)
+	}
+	requirements := make(map[string]VersionSet, len(pkg.Requirements))
+	for _, dep := range pkg.Requirements {
+		requirements[dep] = anyVersion()
+	}
+	r.packages[pkg.Name] = []release{{
+		Release:      Release{Version: pkg.Version, BuildTools: pkg.BuildTools, OtpApp: pkg.OtpApp},
+		version:      version,
+		requirements: requirements,
+		pinned:       &pkg,
+	}}
+	return nil
+}
+
+// release returns the release of a package at version.
+func (r *Registry) release(name string, version Version) (release, bool, error) {
+	releases, err := r.releases(name)
+	if err != nil {
+		return release{}, false, err
+	}
+	for _, rel := range releases {
+		if rel.version.Compare(version) == 0 {
+			return rel, true, nil
+		}
+	}
+	return release{}, false, nil
+}
@@ -0,0 +1,315 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/BurntSushi/toml"
+	"github.com/iocat/rules_gleam/internal/hex"
+)
+
+// errStale is returned by "resolve --check" when manifest.toml is stale.
+var errStale = errors.New("manifest.toml doesn't match gleam.toml")
+
+// GleamToml is the part of gleam.toml resolution needs.
+type GleamToml struct {
+	// Version requirements, or tables for git and local path dependencies.
+	Dependencies    map[string]any `toml:"dependencies"`
+	DevDependencies map[string]any `toml:"dev-dependencies"`
+}
+
+// requirementToml returns the requirement as manifest.toml records it.
+func requirementToml(r ManifestRequirement) string {
+	switch r.Source() {
+	case hex.SourceGit:
+		return fmt.Sprintf("{ git = %q, ref = %q }", r.Git, r.Ref)
+	case hex.SourceLocal:
+		return fmt.Sprintf("{ path = %q }", r.Path)
+	}
+	return fmt.Sprintf("{ version = %q }", r.Version)
+}
+
+// lockMismatch returns why the package locked for a git or local requirement
+// doesn't come from where it's required from, or "" when it does.
+func lockMismatch(r ManifestRequirement, pkg Package) string {
+	switch {
+	case pkg.Origin() != r.Source():
+		return fmt.Sprintf("locked %s is a %s package, gleam.toml requires a %s one", pkg.Name, pkg.Origin(), r.Source())
+	case r.Source() == hex.SourceGit && pkg.Repo != r.Git:
+		return fmt.Sprintf("locked %s is from %s, gleam.toml requires %s", pkg.Name, pkg.Repo, r.Git)
+	case r.Source() == hex.SourceLocal && filepath.Clean(pkg.Path) != filepath.Clean(r.Path):
+		return fmt.Sprintf("locked %s is from %s, gleam.toml requires %s", pkg.Name, pkg.Path, r.Path)
+	}
+	return ""
+}
+
+// Staleness tells whether a manifest.toml is stale and why.
+type Staleness struct {
+	Stale   bool     `json:"stale"`
+	Reasons []string `json:"reasons"`
+	// Whether a new manifest.toml was written.
+	Written bool `json:"written"`
+}
+
+// runResolve is the resolve subcommand: it resolves the requirements of a
+// gleam.toml against a registry snapshot and writes manifest.toml, unless
+// it's up to date.
+func runResolve(args []string, w io.Writer) error {
+	fs := flag.NewFlagSet("resolve", flag.ContinueOnError)
+	gleamTomlFlag := fs.String("gleam_toml", "", "Path to gleam.toml")
+	registryFlag := fs.String("registry", "", "Directory of the registry snapshot, with a <package>.toml file per package")
+	manifestFlag := fs.String("manifest", "", "Path to the manifest.toml to check and write, defaults to the one next to gleam.toml")
+	checkFlag := fs.Bool("check", false, "Only check whether manifest.toml is stale")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if *gleamTomlFlag == "" {
+		return logAndExit(w, "gleam_toml flag is required")
+	}
+	manifestFile := *manifestFlag
+	if manifestFile == "" {
+		manifestFile = filepath.Join(filepath.Dir(*gleamTomlFlag), "manifest.toml")
+	}
+
+	var gleamToml GleamToml
+	if _, err := toml.DecodeFile(*gleamTomlFlag, &gleamToml); err != nil {
+		return logAndExit(w, "failed to decode gleam.toml: %v", err)
+	}
+	requirements, err := gleamRequirements(&gleamToml)
+	if err != nil {
+		return logAndExit(w, "%v", err)
+	}
+	var manifest *Manifest
+	if _, err := os.Stat(manifestFile); err == nil {
+		if manifest, err = readManifest(manifestFile, w); err != nil {
+			return err
+		}
+	}
+
+	staleness := stale(requirements, manifest)
+	if staleness.Stale && !*checkFlag {
+		if *registryFlag == "" {
+			return logAndExit(w, "registry flag is required to resolve a stale manifest.toml")
+		}
+		if err := resolve(requirements, manifest, newRegistry(*registryFlag), manifestFile); err != nil {
+			return err
+		}
+		staleness.Written = true
+	}
+
+	str := strings.Builder{}
+	encoder := json.NewEncoder(&str)
+	encoder.SetIndent("", "  ")
+	encoder.Encode(staleness)
+	logResult(w, "%s", str.String())
+	if staleness.Stale && *checkFlag {
+		return errStale
+	}
+	return nil
+}
+
+// gleamRequirements returns the requirements of the dependencies and dev
+// dependencies of gleam.toml: Hex version requirements, or where git and
+// local dependencies come from.
+func gleamRequirements(gleamToml *GleamToml) (map[string]ManifestRequirement, error) {
+	requirements := make(map[string]ManifestRequirement)
+	for _, deps := range []map[string]any{gleamToml.Dependencies, gleamToml.DevDependencies} {
+		for name, dep := range deps {
+			var requirement ManifestRequirement
+			switch dep := dep.(type) {
+			case string:
+				requirement.Version = dep
+			case map[string]any:
+				requirement.Version, _ = dep["version"].(string)
+				requirement.Git, _ = dep["git"].(string)
+				requirement.Ref, _ = dep["ref"].(string)
+				requirement.Path, _ = dep["path"].(string)
+			}
+			if requirement == (ManifestRequirement{}) {
+				return nil, fmt.Errorf("%s is neither a version requirement nor a git or path dependency", name)
+			}
+			requirements[name] = requirement
+		}
+	}
+	return requirements, nil
+}
+
+// stale compares the requirements of gleam.toml with the ones manifest.toml
+// was resolved from, and with the versions it locks.
+func stale(requirements map[string]ManifestRequirement, manifest *Manifest) Staleness {
+	if manifest == nil {
+		return Staleness{Stale: true, Reasons: []string{"no manifest.toml"}}
+	}
+	locked := make(map[string]Package)
+	for _, pkg := range manifest.Packages {
+		locked[pkg.Name] = pkg
+	}
+
+	reasons := []string{}
+	for name, requirement := range requirements {
+		recorded, ok := manifest.Requirements[name]
+		if !ok {
+			reasons = append(reasons, fmt.Sprintf("%s is not a requirement of manifest.toml", name))
+			continue
+		}
+		reasons = append(reasons, staleRequirement(name, requirement, recorded, locked)...)
+	}
+	for name := range manifest.Requirements {
+		if _, ok := requirements[name]; !ok {
+			reasons = append(reasons, fmt.Sprintf("%s is no longer required", name))
+		}
+	}
+	slices.Sort(reasons)
+	return Staleness{Stale: len(reasons) > 0, Reasons: reasons}
+}
+
+// staleRequirement returns why a requirement of gleam.toml doesn't match the
+// one manifest.toml recorded for it, or the package manifest.toml locks for
+// it, if ever.
+func staleRequirement(name string, requirement, recorded ManifestRequirement, locked map[string]Package) []string {
+	var reasons []string
+	if recorded != requirement {
+		reasons = append(reasons, fmt.Sprintf("requirement of %s changed from %s to %s", name, recorded, requirement))
+	}
+	pkg, ok := locked[name]
+	if !ok {
+		return append(reasons, fmt.Sprintf("%s is not locked by manifest.toml", name))
+	}
+	if mismatch := lockMismatch(requirement, pkg); mismatch != "" {
+		return append(reasons, mismatch)
+	}
+	// Git and local packages aren't versioned by Hex.
+	if requirement.Source() != hex.SourceHex {
+		return reasons
+	}
+	set, err := parseRequirement(requirement.Version)
+	if err != nil {
+		return append(reasons, err.Error())
+	}
+	if v, err := parseVersion(pkg.Version); err != nil || !set.Contains(v) {
+		reasons = append(reasons, fmt.Sprintf("locked %s %s doesn't match %s", name, pkg.Version, requirement))
+	}
+	return reasons
+}
+
+// resolve solves the requirements, keeping the versions locked by the
+// previous manifest when they still match, and writes manifest.toml. Git and
+// local packages can't be resolved against the registry, they're pinned to
+// the previous manifest.
+func resolve(requirements map[string]ManifestRequirement, previous *Manifest, registry *Registry, manifestFile string) error {
+	locked := make(map[string]Version)
+	pinned := make(map[string]Package)
+	if previous != nil {
+		for _, pkg := range previous.Packages {
+			if pkg.Origin() != hex.SourceHex {
+				if err := registry.pin(pkg); err != nil {
+					return err
+				}
+				pinned[pkg.Name] = pkg
+				continue
+			}
+			if v, err := parseVersion(pkg.Version); err == nil {
+				locked[pkg.Name] = v
+			}
+		}
+	}
+	root := make(map[string]VersionSet, len(requirements))
+	for name, requirement := range requirements {
+		if requirement.Source() != hex.SourceHex {
+			pkg, ok := pinned[name]
+			if !ok {
+				return fmt.Errorf("%s is a %s dependency not locked by manifest.toml, run gleam deps download to lock it", name, requirement.Source())
+			}
+			if mismatch := lockMismatch(requirement, pkg); mismatch != "" {
+				return fmt.Errorf("%s, run gleam deps download to lock it", mismatch)
+			}
+			rels, err := registry.releases(name)
+			if err != nil {
+				return err
+			}
+			root[name] = exactly(rels[0].version)
+			continue
+		}
+		set, err := parseRequirement(requirement.Version)
+		if err != nil {
+			return fmt.Errorf("gleam.toml requirement of %s: %w", name, err)
+		}
+		root[name] = set
+	}
+
+	solution, err := newSolver(registry, root, locked).solve()
+	if err != nil {
+		return err
+	}
+	f, err := os.Create(manifestFile)
+	if err != nil {
+		return err
+	}
+	if err := writeManifest(f, requirements, solution, registry); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
+
+// writeManifest writes a manifest.toml like the gleam tool does.
+func writeManifest(w io.Writer, requirements map[string]ManifestRequirement, solution map[string]Version, registry *Registry) error {
+	var names []string
+	for name := range solution {
+		names = append(names, name)
+	}
+	slices.Sort(names)
+
+	var b strings.Builder
+	b.WriteString("# This file was generated by Gleam\n# You typically do not need to edit this file\n\npackages = [\n")
+	for _, name := range names {
+		rel, ok, err := registry.release(name, solution[name])
+		if err != nil {
+			return err
+		}
+		if !ok {
+			return fmt.Errorf("no release %s of %s in the registry", solution[name], name)
+		}
+		deps := make([]string, 0, len(rel.requirements))
+		for dep := range rel.requirements {
+			deps = append(deps, dep)
+		}
+		slices.Sort(deps)
+		fmt.Fprintf(&b, "  { name = %q, version = %q, build_tools = %s, requirements = %s, otp_app = %q, ",
+			name, rel.Version, tomlStrings(rel.BuildTools), tomlStrings(deps), rel.OtpApp)
+		switch pkg := rel.pinned; {
+		case pkg == nil:
+			fmt.Fprintf(&b, "source = \"hex\", outer_checksum = %q },\n", rel.OuterChecksum)
+		case pkg.Origin() == hex.SourceGit:
+			fmt.Fprintf(&b, "source = \"git\", repo = %q, commit = %q },\n", pkg.Repo, pkg.Commit)
+		default:
+			fmt.Fprintf(&b, "source = \"local\", path = %q },\n", pkg.Path)
+		}
+	}
+	b.WriteString("]\n\n[requirements]\n")
+	var required []string
+	for name := range requirements {
+		required = append(required, name)
+	}
+	slices.Sort(required)
+	for _, name := range required {
+		fmt.Fprintf(&b, "%s = %s\n", name, requirementToml(requirements[name]))
+	}
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+func tomlStrings(values []string) string {
+	quoted := make([]string, len(values))
+	for i, v := range values {
+		quoted[i] = fmt.Sprintf("%q", v)
+	}
+	return "[" + strings.Join(quoted, ", ") + "]"
+}
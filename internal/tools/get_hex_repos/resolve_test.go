@@ -0,0 +1,457 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+// writeRegistry writes a registry snapshot with the releases of each
+// package.
+func writeRegistry(t *testing.T, packages map[string]string) string {
+	t.Helper()
+	dir := t.TempDir()
+	for name, releases := range packages {
+		if err := os.WriteFile(filepath.Join(dir, name+".toml"), []byte(releases), 0644); err != nil {
+			t.Fatalf("failed to write registry package: %v", err)
+		}
+	}
+	return dir
+}
+
+func TestSolve(t *testing.T) {
+	testCases := []struct {
+		desc         string
+		registry     map[string]string
+		requirements map[string]string
+		locked       map[string]string
+		want         map[string]string
+		wantErr      []string
+	}{
+		{
+			desc: "newest matching releases",
+			registry: map[string]string{
+				"gleam_stdlib": `
+[[releases]]
+version = "0.60.0"
+[[releases]]
+version = "1.0.0-rc.1"
+[[releases]]
+version = "0.44.0"
+`,
+				"gleam_json": `
+[[releases]]
+version = "3.0.2"
+requirements = { gleam_stdlib = ">= 0.51.0 and < 2.0.0" }
+[[releases]]
+version = "2.0.0"
+requirements = { gleam_stdlib = ">= 0.34.0 and < 2.0.0" }
+`,
+			},
+			requirements: map[string]string{
+				"gleam_json": ">= 2.0.0 and < 4.0.0",
+			},
+			want: map[string]string{
+				"gleam_json":   "3.0.2",
+				"gleam_stdlib": "0.60.0",
+			},
+		},
+		{
+			desc: "backtracks on conflict",
+			registry: map[string]string{
+				"a": `
+[[releases]]
+version = "2.0.0"
+requirements = { c = "< 2.0.0" }
+[[releases]]
+version = "1.0.0"
+`,
+				"b": `
+[[releases]]
+version = "1.0.0"
+requirements = { c = ">= 2.0.0" }
+`,
+				"c": `
+[[releases]]
+version = "2.0.0"
+[[releases]]
+version = "1.0.0"
+`,
+			},
+			requirements: map[string]string{
+				"a": ">= 1.0.0",
+				"b": ">= 1.0.0",
+			},
+			want: map[string]string{
+				"a": "1.0.0",
+				"b": "1.0.0",
+				"c": "2.0.0",
+			},
+		},
+		{
+			desc: "locked versions kept",
+			registry: map[string]string{
+				"gleam_stdlib": `
+[[releases]]
+version = "0.60.0"
+[[releases]]
+version = "0.50.0"
+`,
+			},
+			requirements: map[string]string{
+				"gleam_stdlib": ">= 0.44.0 and < 2.0.0",
+			},
+			locked: map[string]string{
+				"gleam_stdlib": "0.50.0",
+			},
+			want: map[string]string{
+				"gleam_stdlib": "0.50.0",
+			},
+		},
+		{
+			desc: "retired releases skipped",
+			registry: map[string]string{
+				"gleam_stdlib": `
+[[releases]]
+version = "0.60.0"
+retired = true
+[[releases]]
+version = "0.59.0"
+`,
+			},
+			requirements: map[string]string{
+				"gleam_stdlib": ">= 0.44.0 and < 2.0.0",
+			},
+			want: map[string]string{
+				"gleam_stdlib": "0.59.0",
+			},
+		},
+		{
+			desc: "conflicting requirements",
+			registry: map[string]string{
+				"a": `
+[[releases]]
+version = "1.0.0"
+requirements = { c = "1.0.0" }
+`,
+				"b": `
+[[releases]]
+version = "1.0.0"
+requirements = { c = "2.0.0" }
+`,
+				"c": `
+[[releases]]
+version = "2.0.0"
+[[releases]]
+version = "1.0.0"
+`,
+			},
+			requirements: map[string]string{
+				"a": "~> 1.0",
+				"b": "~> 1.0",
+			},
+			wantErr: []string{
+				"a 1.0.0 requires c 1.0.0",
+				"b 1.0.0 requires c 2.0.0",
+			},
+		},
+		{
+			desc:     "unknown package",
+			registry: map[string]string{},
+			requirements: map[string]string{
+				"missing": ">= 1.0.0",
+			},
+			wantErr: []string{
+				"gleam.toml requires missing >= 1.0.0",
+				"no release of missing matches >= 1.0.0",
+			},
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.desc, func(t *testing.T) {
+			registry := newRegistry(writeRegistry(t, tc.registry))
+			root := make(map[string]VersionSet)
+			for name, requirement := range tc.requirements {
+				set, err := parseRequirement(requirement)
+				if err != nil {
+					t.Fatal(err)
+				}
+				root[name] = set
+			}
+			locked := make(map[string]Version)
+			for name, v := range tc.locked {
+				locked[name] = mustParseVersion(t, v)
+			}
+
+			solution, err := newSolver(registry, root, locked).solve()
+			if tc.wantErr != nil {
+				var solveErr *SolveError
+				if !errors.As(err, &solveErr) {
+					t.Fatalf("solve error = %v, want a SolveError", err)
+				}
+				for _, want := range tc.wantErr {
+					if !strings.Contains(err.Error(), want) {
+						t.Errorf("solve error = %v, want it to contain %q", err, want)
+					}
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("solve failed: %v", err)
+			}
+			got := make(map[string]string)
+			for name, v := range solution {
+				got[name] = v.String()
+			}
+			if diff := cmp.Diff(tc.want, got); diff != "" {
+				t.Errorf("solve mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestStale(t *testing.T) {
+	manifest := &Manifest{
+		Packages: []Package{
+			{Name: "gleam_json", Version: "3.0.2", Source: "hex"},
+			{Name: "gleam_stdlib", Version: "0.60.0", Source: "hex"},
+			{Name: "shared", Version: "0.1.0", Source: "local", Path: "../shared"},
+			{Name: "forked", Version: "1.2.0", Source: "git", Repo: "https://example.com/forked.git", Commit: "abc123"},
+		},
+		Requirements: map[string]ManifestRequirement{
+			"gleam_json":   {Version: ">= 3.0.0 and < 4.0.0"},
+			"gleam_stdlib": {Version: ">= 0.44.0 and < 2.0.0"},
+			"shared":       {Path: "../shared"},
+			"forked":       {Git: "https://example.com/forked.git", Ref: "main"},
+		},
+	}
+	upToDate := map[string]ManifestRequirement{
+		"gleam_json":   {Version: ">= 3.0.0 and < 4.0.0"},
+		"gleam_stdlib": {Version: ">= 0.44.0 and < 2.0.0"},
+		"shared":       {Path: "../shared"},
+		"forked":       {Git: "https://example.com/forked.git", Ref: "main"},
+	}
+	testCases := []struct {
+		desc         string
+		requirements map[string]ManifestRequirement
+		manifest     *Manifest
+		want         Staleness
+	}{
+		{
+			desc:         "up to date",
+			requirements: upToDate,
+			manifest:     manifest,
+			want:         Staleness{Reasons: []string{}},
+		},
+		{
+			desc:         "no manifest",
+			requirements: map[string]ManifestRequirement{"gleam_stdlib": {Version: ">= 0.44.0"}},
+			want:         Staleness{Stale: true, Reasons: []string{"no manifest.toml"}},
+		},
+		{
+			desc: "changed, added and removed requirements",
+			requirements: map[string]ManifestRequirement{
+				"gleam_json": {Version: ">= 4.0.0 and < 5.0.0"},
+				"gleeunit":   {Version: ">= 1.0.0 and < 2.0.0"},
+				"shared":     {Path: "../shared"},
+				"forked":     {Git: "https://example.com/forked.git", Ref: "main"},
+			},
+			manifest: manifest,
+			want: Staleness{Stale: true, Reasons: []string{
+				"gleam_stdlib is no longer required",
+				"gleeunit is not a requirement of manifest.toml",
+				`locked gleam_json 3.0.2 doesn't match ">= 4.0.0 and < 5.0.0"`,
+				`requirement of gleam_json changed from ">= 3.0.0 and < 4.0.0" to ">= 4.0.0 and < 5.0.0"`,
+			}},
+		},
+		{
+			desc: "changed sources",
+			requirements: map[string]ManifestRequirement{
+				"gleam_json":   {Path: "../gleam_json"},
+				"gleam_stdlib": {Version: ">= 0.44.0 and < 2.0.0"},
+				"shared":       {Version: ">= 0.1.0"},
+				"forked":       {Git: "https://example.com/other.git", Ref: "main"},
+			},
+			manifest: manifest,
+			want: Staleness{Stale: true, Reasons: []string{
+				"locked forked is from https://example.com/forked.git, gleam.toml requires https://example.com/other.git",
+				"locked gleam_json is a hex package, gleam.toml requires a local one",
+				"locked shared is a local package, gleam.toml requires a hex one",
+				`requirement of forked changed from git https://example.com/forked.git at main to git https://example.com/other.git at main`,
+				`requirement of gleam_json changed from ">= 3.0.0 and < 4.0.0" to path ../gleam_json`,
+				`requirement of shared changed from path ../shared to ">= 0.1.0"`,
+			}},
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.desc, func(t *testing.T) {
+			if diff := cmp.Diff(tc.want, stale(tc.requirements, tc.manifest)); diff != "" {
+				t.Errorf("stale mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestRunResolve(t *testing.T) {
+	registry := writeRegistry(t, map[string]string{
+		"gleam_stdlib": `
+[[releases]]
+version = "0.60.0"
+outer_checksum = "STDLIB"
+build_tools = ["gleam"]
+otp_app = "gleam_stdlib"
+`,
+		"gleeunit": `
+[[releases]]
+version = "1.6.0"
+outer_checksum = "GLEEUNIT"
+build_tools = ["gleam"]
+otp_app = "gleeunit"
+requirements = { gleam_stdlib = ">= 0.60.0 and < 2.0.0" }
+`,
+	})
+	dir := t.TempDir()
+	gleamToml := filepath.Join(dir, "gleam.toml")
+	if err := os.WriteFile(gleamToml, []byte(`
+name = "app"
+
+[dependencies]
+gleam_stdlib = ">= 0.44.0 and < 2.0.0"
+
+[dev-dependencies]
+gleeunit = ">= 1.0.0 and < 2.0.0"
+`), 0644); err != nil {
+		t.Fatal(err)
+	}
+	args := []string{"--gleam_toml", gleamToml, "--registry", registry}
+
+	// Stale without a manifest.
+	var stdout bytes.Buffer
+	if err := runResolve(append(args, "--check"), &stdout); !errors.Is(err, errStale) {
+		t.Fatalf("runResolve --check error = %v, want %v", err, errStale)
+	}
+
+	stdout.Reset()
+	if err := runResolve(args, &stdout); err != nil {
+		t.Fatalf("runResolve failed: %v", err)
+	}
+	var staleness Staleness
+	if err := json.Unmarshal(stdout.Bytes(), &staleness); err != nil {
+		t.Fatalf("failed to unmarshal json: %v", err)
+	}
+	if !staleness.Written {
+		t.Errorf("manifest.toml was not written: %+v", staleness)
+	}
+	got, err := os.ReadFile(filepath.Join(dir, "manifest.toml"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := `# This file was generated by Gleam
+# You typically do not need to edit this file
+
+packages = [
+  { name = "gleam_stdlib", version = "0.60.0", build_tools = ["gleam"], requirements = [], otp_app = "gleam_stdlib", source = "hex", outer_checksum = "STDLIB" },
+  { name = "gleeunit", version = "1.6.0", build_tools = ["gleam"], requirements = ["gleam_stdlib"], otp_app = "gleeunit", source = "hex", outer_checksum = "GLEEUNIT" },
+]
+
+[requirements]
+gleam_stdlib = { version = ">= 0.44.0 and < 2.0.0" }
+gleeunit = { version = ">= 1.0.0 and < 2.0.0" }
+`
+	if diff := cmp.Diff(want, string(got)); diff != "" {
+		t.Errorf("manifest.toml mismatch (-want +got):\n%s", diff)
+	}
+
+	// The written manifest is up to date and readable by run.
+	stdout.Reset()
+	if err := runResolve(append(args, "--check"), &stdout); err != nil {
+		t.Fatalf("runResolve --check failed on the written manifest: %v", err)
+	}
+	stdout.Reset()
+	if err := run(filepath.Join(dir, "manifest.toml"), &stdout); err != nil {
+		t.Fatalf("run failed on the written manifest: %v", err)
+	}
+}
+
+func TestRunResolveGitAndLocalDependencies(t *testing.T) {
+	registry := writeRegistry(t, map[string]string{
+		"gleam_stdlib": `
+[[releases]]
+version = "0.60.0"
+outer_checksum = "STDLIB"
+build_tools = ["gleam"]
+otp_app = "gleam_stdlib"
+`,
+	})
+	dir := t.TempDir()
+	gleamToml := filepath.Join(dir, "gleam.toml")
+	if err := os.WriteFile(gleamToml, []byte(`
+name = "app"
+
+[dependencies]
+gleam_stdlib = ">= 0.44.0 and < 2.0.0"
+shared = { path = "../shared" }
+forked = { git = "https://example.com/forked.git", ref = "main" }
+`), 0644); err != nil {
+		t.Fatal(err)
+	}
+	manifestFile := filepath.Join(dir, "manifest.toml")
+	args := []string{"--gleam_toml", gleamToml, "--registry", registry}
+
+	// A git dependency can only be pinned to manifest.toml.
+	var stdout bytes.Buffer
+	err := runResolve(args, &stdout)
+	if err == nil || !strings.Contains(err.Error(), "not locked by manifest.toml") {
+		t.Fatalf("runResolve error = %v, want the git or local dependency not to be locked", err)
+	}
+
+	// Locked by gleam, before shared required gleam_stdlib.
+	if err := os.WriteFile(manifestFile, []byte(`
+packages = [
+  { name = "forked", version = "1.2.0", build_tools = ["gleam"], requirements = [], otp_app = "forked", source = "git", repo = "https://example.com/forked.git", commit = "abc123" },
+  { name = "shared", version = "0.1.0", build_tools = ["gleam"], requirements = ["gleam_stdlib"], otp_app = "shared", source = "local", path = "../shared" },
+]
+
+[requirements]
+forked = { git = "https://example.com/forked.git", ref = "main" }
+shared = { path = "../shared" }
+`), 0644); err != nil {
+		t.Fatal(err)
+	}
+	stdout.Reset()
+	if err := runResolve(args, &stdout); err != nil {
+		t.Fatalf("runResolve failed: %v", err)
+	}
+	got, err := os.ReadFile(manifestFile)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := `# This file was generated by Gleam
+# You typically do not need to edit this file
+
+packages = [
+  { name = "forked", version = "1.2.0", build_tools = ["gleam"], requirements = [], otp_app = "forked", source = "git", repo = "https://example.com/forked.git", commit = "abc123" },
+  { name = "gleam_stdlib", version = "0.60.0", build_tools = ["gleam"], requirements = [], otp_app = "gleam_stdlib", source = "hex", outer_checksum = "STDLIB" },
+  { name = "shared", version = "0.1.0", build_tools = ["gleam"], requirements = ["gleam_stdlib"], otp_app = "shared", source = "local", path = "../shared" },
+]
+
+[requirements]
+forked = { git = "https://example.com/forked.git", ref = "main" }
+gleam_stdlib = { version = ">= 0.44.0 and < 2.0.0" }
+shared = { path = "../shared" }
+`
+	if diff := cmp.Diff(want, string(got)); diff != "" {
+		t.Errorf("manifest.toml mismatch (-want +got):\n%s", diff)
+	}
+
+	stdout.Reset()
+	if err := runResolve(append(args, "--check"), &stdout); err != nil {
+		t.Fatalf("runResolve --check failed on the written manifest: %v", err)
+	}
+}
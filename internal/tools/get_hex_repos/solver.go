@@ -0,0 +1,529 @@
+package main
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+)
+
+// rootPackage stands for the gleam.toml being resolved.
+const rootPackage = "$root"
+
+// term is a statement about the version of a package: a positive term says
+// one of its versions in set is selected, a negative one that none is,
+// possibly because the package isn't selected at all.
+type term struct {
+	pkg      string
+	set      VersionSet
+	positive bool
+}
+
+func (t term) negate() term {
+	return term{pkg: t.pkg, set: t.set, positive: !t.positive}
+}
+
+// intersect returns the term allowing what both t and other, about the same
+// package, allow.
+func (t term) intersect(other term) term {
+	switch {
+	case t.positive && other.positive:
+		return term{pkg: t.pkg, set: t.set.Intersect(other.set), positive: true}
+	case t.positive:
+		return term{pkg: t.pkg, set: t.set.Difference(other.set), positive: true}
+	case other.positive:
+		return term{pkg: t.pkg, set: other.set.Difference(t.set), positive: true}
+	}
+	return term{pkg: t.pkg, set: t.set.Union(other.set)}
+}
+
+// satisfies reports whether everything t allows is allowed by other.
+func (t term) satisfies(other term) bool {
+	switch {
+	case t.positive && other.positive:
+		return t.set.IsSubset(other.set)
+	case t.positive:
+		return t.set.IsDisjoint(other.set)
+	case other.positive:
+		// t allows not selecting the package.
+		return false
+	}
+	return other.set.IsSubset(t.set)
+}
+
+// contradicts reports whether t and other allow nothing in common.
+func (t term) contradicts(other term) bool {
+	switch {
+	case t.positive && other.positive:
+		return t.set.IsDisjoint(other.set)
+	case t.positive:
+		return t.set.IsSubset(other.set)
+	case other.positive:
+		return other.set.IsSubset(t.set)
+	}
+	// Both allow not selecting the package.
+	return false
+}
+
+// isEmpty reports whether t allows nothing.
+func (t term) isEmpty() bool {
+	return t.positive && t.set.IsEmpty()
+}
+
+func (t term) String() string {
+	s := t.pkg + " " + t.set.String()
+	if t.set.IsAny() || t.pkg == rootPackage {
+		s = packageString(t.pkg)
+	}
+	if !t.positive {
+		return "not " + s
+	}
+	return s
+}
+
+func packageString(pkg string) string {
+	if pkg == rootPackage {
+		return "gleam.toml"
+	}
+	return pkg
+}
+
+// incompatibilityKind is why an incompatibility holds.
+type incompatibilityKind int
+
+const (
+	// The root package must be selected.
+	kindRoot incompatibilityKind = iota
+	// A release requires a package.
+	kindDependency
+	// No release of the registry matches.
+	kindNoVersions
+	// Derived from two others while resolving a conflict.
+	kindDerived
+)
+
+// incompatibility is a set of terms that can't all be true at once.
+type incompatibility struct {
+	terms []term
+	kind  incompatibilityKind
+	// The incompatibilities a kindDerived one is derived from.
+	causes [2]*incompatibility
+}
+
+// newIncompatibility merges the terms about the same package.
+func newIncompatibility(terms []term, kind incompatibilityKind, causes ...*incompatibility) *incompatibility {
+	var merged []term
+	index := make(map[string]int)
+	for _, t := range terms {
+		// The root is always selected, saying so adds nothing.
+		if kind == kindDerived && t.positive && t.pkg == rootPackage && len(terms) > 1 {
+			continue
+		}
+		if i, ok := index[t.pkg]; ok {
+			merged[i] = merged[i].intersect(t)
+			continue
+		}
+		index[t.pkg] = len(merged)
+		merged = append(merged, t)
+	}
+	inc := &incompatibility{terms: merged, kind: kind}
+	copy(inc.causes[:], causes)
+	return inc
+}
+
+// isFailure reports whether the incompatibility says that the root can't be
+// selected, so that there's no solution.
+func (inc *incompatibility) isFailure() bool {
+	return len(inc.terms) == 0 || (len(inc.terms) == 1 && inc.terms[0].positive && inc.terms[0].pkg == rootPackage)
+}
+
+func (inc *incompatibility) String() string {
+	switch inc.kind {
+	case kindDependency:
+		return fmt.Sprintf("%s requires %s", inc.terms[0], inc.terms[1].negate())
+	case kindNoVersions:
+		return fmt.Sprintf("no release of %s matches %s", inc.terms[0].pkg, inc.terms[0].set)
+	}
+	var terms []string
+	for _, t := range inc.terms {
+		terms = append(terms, t.String())
+	}
+	return strings.Join(terms, " and ") + " are incompatible"
+}
+
+// SolveError is returned when no set of releases satisfies the requirements.
+type SolveError struct {
+	inc *incompatibility
+}
+
+// Error lists the requirements and missing releases that conflict.
+func (e *SolveError) Error() string {
+	var lines []string
+	seen := make(map[*incompatibility]bool)
+	var walk func(inc *incompatibility)
+	walk = func(inc *incompatibility) {
+		if inc == nil || seen[inc] {
+			return
+		}
+		seen[inc] = true
+		switch inc.kind {
+		case kindDerived:
+			walk(inc.causes[0])
+			walk(inc.causes[1])
+		case kindDependency, kindNoVersions:
+			lines = append(lines, inc.String())
+		}
+	}
+	walk(e.inc)
+	return "version solving failed:\n  " + strings.Join(lines, "\n  ")
+}
+
+type assignment struct {
+	term
+	// Number of decisions made when it was assigned.
+	level int
+	// Incompatibility it was derived from, nil for decisions.
+	cause *incompatibility
+}
+
+// partialSolution holds the decisions and derivations made so far.
+type partialSolution struct {
+	assignments []assignment
+	decisions   map[string]Version
+}
+
+func (ps *partialSolution) decide(pkg string, v Version) {
+	ps.decisions[pkg] = v
+	ps.assignments = append(ps.assignments, assignment{
+		term:  term{pkg: pkg, set: exactly(v), positive: true},
+		level: len(ps.decisions),
+	})
+}
+
+func (ps *partialSolution) derive(t term, cause *incompatibility) {
+	ps.assignments = append(ps.assignments, assignment{term: t, level: len(ps.decisions), cause: cause})
+}
+
+// backtrack drops the decisions and derivations made after level.
+func (ps *partialSolution) backtrack(level int) {
+	for len(ps.assignments) > 0 {
+		last := ps.assignments[len(ps.assignments)-1]
+		if last.level <= level {
+			break
+		}
+		if last.cause == nil {
+			delete(ps.decisions, last.pkg)
+		}
+		ps.assignments = ps.assignments[:len(ps.assignments)-1]
+	}
+}
+
+// accumulated returns what the assignments up to n say about pkg.
+func (ps *partialSolution) accumulated(pkg string, n int) (term, bool) {
+	var acc term
+	found := false
+	for _, a := range ps.assignments[:n] {
+		if a.pkg != pkg {
+			continue
+		}
+		if !found {
+			acc, found = a.term, true
+		} else {
+			acc = acc.intersect(a.term)
+		}
+	}
+	return acc, found
+}
+
+type relation int
+
+const (
+	relationSatisfied relation = iota
+	relationContradicted
+	// All the terms but one are satisfied, and that one isn't contradicted.
+	relationAlmostSatisfied
+	relationInconclusive
+)
+
+// relation returns how the incompatibility relates to the partial solution,
+// and the unsatisfied term when it's almost satisfied.
+func (ps *partialSolution) relation(inc *incompatibility) (relation, term) {
+	var unsatisfied *term
+	for i, t := range inc.terms {
+		acc, ok := ps.accumulated(t.pkg, len(ps.assignments))
+		switch {
+		case ok && acc.satisfies(t):
+			continue
+		case ok && acc.contradicts(t):
+			return relationContradicted, term{}
+		case unsatisfied != nil:
+			return relationInconclusive, term{}
+		}
+		unsatisfied = &inc.terms[i]
+	}
+	if unsatisfied == nil {
+		return relationSatisfied, term{}
+	}
+	return relationAlmostSatisfied, *unsatisfied
+}
+
+// satisfier returns the index of the earliest assignment that, with the ones
+// before it, satisfies t.
+func (ps *partialSolution) satisfier(t term) int {
+	var acc term
+	found := false
+	for i, a := range ps.assignments {
+		if a.pkg != t.pkg {
+			continue
+		}
+		if !found {
+			acc, found = a.term, true
+		} else {
+			acc = acc.intersect(a.term)
+		}
+		if acc.satisfies(t) {
+			return i
+		}
+	}
+	panic(fmt.Sprintf("%s is not satisfied", t))
+}
+
+// solver finds releases satisfying requirements with the PubGrub algorithm,
+// https://github.com/dart-lang/pub/blob/master/doc/solver.md.
+type solver struct {
+	registry *Registry
+	// Requirements of the root package.
+	root map[string]VersionSet
+	// Versions to keep when they still match, e.g. from manifest.toml.
+	locked map[string]Version
+
+	incompatibilities map[string][]*incompatibility
+	ps                partialSolution
+}
+
+func newSolver(registry *Registry, root map[string]VersionSet, locked map[string]Version) *solver {
+	return &solver{
+		registry:          registry,
+		root:              root,
+		locked:            locked,
+		incompatibilities: make(map[string][]*incompatibility),
+		ps:                partialSolution{decisions: make(map[string]Version)},
+	}
+}
+
+// solve returns the selected version of each package required by the root,
+// directly or not.
+func (s *solver) solve() (map[string]Version, error) {
+	s.add(newIncompatibility([]term{{pkg: rootPackage, set: anyVersion()}}, kindRoot))
+	next := rootPackage
+	for {
+		if err := s.propagate(next); err != nil {
+			return nil, err
+		}
+		pkg, done, err := s.decide()
+		if err != nil {
+			return nil, err
+		}
+		if done {
+			break
+		}
+		next = pkg
+	}
+	solution := make(map[string]Version, len(s.ps.decisions))
+	for pkg, v := range s.ps.decisions {
+		if pkg != rootPackage {
+			solution[pkg] = v
+		}
+	}
+	return solution, nil
+}
+
+func (s *solver) add(inc *incompatibility) {
+	for _, t := range inc.terms {
+		s.incompatibilities[t.pkg] = append(s.incompatibilities[t.pkg], inc)
+	}
+}
+
+// propagate derives what the incompatibilities imply from the changes to
+// pkg, resolving conflicts on the way.
+func (s *solver) propagate(pkg string) error {
+	changed := []string{pkg}
+	for len(changed) > 0 {
+		pkg := changed[len(changed)-1]
+		changed = changed[:len(changed)-1]
+		incs := s.incompatibilities[pkg]
+		for i := len(incs) - 1; i >= 0; i-- {
+			rel, unsatisfied := s.ps.relation(incs[i])
+			switch rel {
+			case relationSatisfied:
+				root, err := s.resolveConflict(incs[i])
+				if err != nil {
+					return err
+				}
+				_, unsatisfied = s.ps.relation(root)
+				s.ps.derive(unsatisfied.negate(), root)
+				changed = []string{unsatisfied.pkg}
+				i = -1
+			case relationAlmostSatisfied:
+				s.ps.derive(unsatisfied.negate(), incs[i])
+				changed = append(changed, unsatisfied.pkg)
+			}
+		}
+	}
+	return nil
+}
+
+// resolveConflict derives the incompatibility at the root of a conflict and
+// backtracks to where it's almost satisfied.
+func (s *solver) resolveConflict(inc *incompatibility) (*incompatibility, error) {
+	derived := false
+	for !inc.isFailure() {
+		var (
+			mostRecentTerm      term
+			mostRecentSatisfier = -1
+			difference          *term
+			previousLevel       = 1
+		)
+		for _, t := range inc.terms {
+			satisfier := s.ps.satisfier(t)
+			if satisfier > mostRecentSatisfier {
+				if mostRecentSatisfier >= 0 {
+					previousLevel = max(previousLevel, s.ps.assignments[mostRecentSatisfier].level)
+				}
+				mostRecentTerm, mostRecentSatisfier, difference = t, satisfier, nil
+			} else {
+				previousLevel = max(previousLevel, s.ps.assignments[satisfier].level)
+			}
+			if mostRecentTerm.pkg == t.pkg {
+				// The part of the satisfier not needed to satisfy the term
+				// was satisfied earlier.
+				d := s.ps.assignments[mostRecentSatisfier].term.intersect(mostRecentTerm.negate())
+				if !d.isEmpty() {
+					difference = &d
+					previousLevel = max(previousLevel, s.ps.assignments[s.ps.satisfier(d.negate())].level)
+				}
+			}
+		}
+
+		satisfier := s.ps.assignments[mostRecentSatisfier]
+		if previousLevel < satisfier.level || satisfier.cause == nil {
+			s.ps.backtrack(previousLevel)
+			if derived {
+				s.add(inc)
+			}
+			return inc, nil
+		}
+
+		var terms []term
+		for _, t := range inc.terms {
+			if t.pkg != mostRecentTerm.pkg {
+				terms = append(terms, t)
+			}
+		}
+		for _, t := range satisfier.cause.terms {
+			if t.pkg != satisfier.pkg {
+				terms = append(terms, t)
+			}
+		}
+		if difference != nil {
+			terms = append(terms, difference.negate())
+		}
+		inc = newIncompatibility(terms, kindDerived, inc, satisfier.cause)
+		derived = true
+	}
+	return nil, &SolveError{inc: inc}
+}
+
+// decide selects a version of a package that must be selected and doesn't
+// have one yet. It's done when there's none left.
+func (s *solver) decide() (string, bool, error) {
+	var (
+		pkg      string
+		t        term
+		releases []release
+		found    bool
+	)
+	// Packages with fewer matching releases first, they're the most likely
+	// to conflict.
+	for _, a := range s.ps.assignments {
+		if _, ok := s.ps.decisions[a.pkg]; ok || a.pkg == pkg {
+			continue
+		}
+		acc, _ := s.ps.accumulated(a.pkg, len(s.ps.assignments))
+		if !acc.positive {
+			continue
+		}
+		rels, err := s.releases(a.pkg)
+		if err != nil {
+			return "", false, err
+		}
+		rels = slices.DeleteFunc(slices.Clone(rels), func(r release) bool {
+			return !acc.set.Contains(r.version)
+		})
+		if !found || len(rels) < len(releases) || (len(rels) == len(releases) && a.pkg < pkg) {
+			pkg, t, releases, found = a.pkg, acc, rels, true
+		}
+	}
+	if !found {
+		return "", true, nil
+	}
+
+	rel, ok := s.pick(pkg, releases)
+	if !ok {
+		s.add(newIncompatibility([]term{t}, kindNoVersions))
+		return pkg, false, nil
+	}
+
+	var deps []string
+	for dep := range rel.requirements {
+		deps = append(deps, dep)
+	}
+	slices.Sort(deps)
+	var incs []*incompatibility
+	for _, dep := range deps {
+		inc := newIncompatibility([]term{
+			{pkg: pkg, set: exactly(rel.version), positive: true},
+			{pkg: dep, set: rel.requirements[dep]},
+		}, kindDependency)
+		s.add(inc)
+		incs = append(incs, inc)
+	}
+
+	// The decision is left to propagation when it breaks a requirement.
+	s.ps.decide(pkg, rel.version)
+	for _, inc := range incs {
+		if rel, _ := s.ps.relation(inc); rel == relationSatisfied {
+			s.ps.backtrack(len(s.ps.decisions) - 1)
+			break
+		}
+	}
+	return pkg, false, nil
+}
+
+// releases returns the releases of a package, newest first.
+func (s *solver) releases(pkg string) ([]release, error) {
+	if pkg == rootPackage {
+		return []release{{requirements: s.root}}, nil
+	}
+	return s.registry.releases(pkg)
+}
+
+// pick returns the release to try among the matching ones: the locked one,
+// else the newest one that's neither a pre-release nor retired.
+func (s *solver) pick(pkg string, releases []release) (release, bool) {
+	if len(releases) == 0 {
+		return release{}, false
+	}
+	if locked, ok := s.locked[pkg]; ok {
+		for _, r := range releases {
+			if r.version.Compare(locked) == 0 {
+				return r, true
+			}
+		}
+	}
+	for _, r := range releases {
+		if !r.version.IsPreRelease() && !r.Retired {
+			return r, true
+		}
+	}
+	return releases[0], true
+}
@@ -0,0 +1,381 @@
+package main
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// Version is a semantic version, like the ones of Hex releases.
+type Version struct {
+	Major, Minor, Patch int
+	// Pre-release identifiers, e.g. ["rc", "1"] for 1.0.0-rc.1.
+	Pre []string
+}
+
+// parseVersion parses a semantic version. Build metadata is ignored.
+func parseVersion(s string) (Version, error) {
+	v, err := parsePartialVersion(s)
+	if err != nil {
+		return Version{}, err
+	}
+	if v.parts != 3 {
+		return Version{}, fmt.Errorf("invalid version %q: want MAJOR.MINOR.PATCH", s)
+	}
+	return v.Version, nil
+}
+
+// partialVersion is a version missing its patch, or minor and patch, numbers
+// like in "~> 1.2".
+type partialVersion struct {
+	Version
+	// Number of MAJOR, MINOR and PATCH numbers given.
+	parts int
+}
+
+func parsePartialVersion(s string) (partialVersion, error) {
+	core, _, _ := strings.Cut(strings.TrimSpace(s), "+")
+	core, pre, hasPre := strings.Cut(core, "-")
+	numbers := strings.Split(core, ".")
+	if len(numbers) > 3 || core == "" {
+		return partialVersion{}, fmt.Errorf("invalid version %q", s)
+	}
+	var v partialVersion
+	for i, n := range numbers {
+		number, err := strconv.Atoi(n)
+		if err != nil || number < 0 {
+			return partialVersion{}, fmt.Errorf("invalid version %q", s)
+		}
+		switch i {
+		case 0:
+			v.Major = number
+		case 1:
+			v.Minor = number
+		case 2:
+			v.Patch = number
+		}
+	}
+	v.parts = len(numbers)
+	if hasPre {
+		if v.parts != 3 || pre == "" {
+			return partialVersion{}, fmt.Errorf("invalid version %q", s)
+		}
+		v.Pre = strings.Split(pre, ".")
+	}
+	return v, nil
+}
+
+func (v Version) String() string {
+	s := fmt.Sprintf("%d.%d.%d", v.Major, v.Minor, v.Patch)
+	if len(v.Pre) > 0 {
+		s += "-" + strings.Join(v.Pre, ".")
+	}
+	return s
+}
+
+// Compare returns -1, 0 or 1 when v is lower than, equal to or greater than
+// other, in semantic versioning precedence.
+func (v Version) Compare(other Version) int {
+	for _, d := range []int{v.Major - other.Major, v.Minor - other.Minor, v.Patch - other.Patch} {
+		if d != 0 {
+			return sign(d)
+		}
+	}
+	// A pre-release is lower than its release.
+	switch {
+	case len(v.Pre) == 0 && len(other.Pre) == 0:
+		return 0
+	case len(v.Pre) == 0:
+		return 1
+	case len(other.Pre) == 0:
+		return -1
+	}
+	for i := 0; i < len(v.Pre) && i < len(other.Pre); i++ {
+		if c := comparePreIdentifier(v.Pre[i], other.Pre[i]); c != 0 {
+			return c
+		}
+	}
+	return sign(len(v.Pre) - len(other.Pre))
+}
+
+// IsPreRelease reports whether v is a pre-release, e.g. 1.0.0-rc.1.
+func (v Version) IsPreRelease() bool {
+	return len(v.Pre) > 0
+}
+
+func comparePreIdentifier(a, b string) int {
+	an, aErr := strconv.Atoi(a)
+	bn, bErr := strconv.Atoi(b)
+	switch {
+	case aErr == nil && bErr == nil:
+		return sign(an - bn)
+	case aErr == nil:
+		// Numeric identifiers are lower than alphanumeric ones.
+		return -1
+	case bErr == nil:
+		return 1
+	}
+	return strings.Compare(a, b)
+}
+
+func sign(d int) int {
+	switch {
+	case d < 0:
+		return -1
+	case d > 0:
+		return 1
+	}
+	return 0
+}
+
+// bound is a point between versions: just before or just after a version.
+// Intervals of versions go from a bound to another, so that inclusive and
+// exclusive ends don't need to be told apart.
+type bound struct {
+	v     Version
+	after bool
+}
+
+func (b bound) compare(other bound) int {
+	if c := b.v.Compare(other.v); c != 0 {
+		return c
+	}
+	switch {
+	case b.after == other.after:
+		return 0
+	case b.after:
+		return 1
+	}
+	return -1
+}
+
+// interval holds the versions between lo and hi. A nil lo or hi is unbounded.
+type interval struct {
+	lo, hi *bound
+}
+
+// VersionSet is a set of versions, as disjoint intervals in increasing
+// order.
+type VersionSet struct {
+	intervals []interval
+}
+
+// anyVersion returns the set of all versions.
+func anyVersion() VersionSet {
+	return VersionSet{intervals: []interval{{}}}
+}
+
+// exactly returns the set of v only.
+func exactly(v Version) VersionSet {
+	return VersionSet{intervals: []interval{{lo: &bound{v: v}, hi: &bound{v: v, after: true}}}}
+}
+
+// IsEmpty reports whether s has no versions.
+func (s VersionSet) IsEmpty() bool {
+	return len(s.intervals) == 0
+}
+
+// IsAny reports whether s has all versions.
+func (s VersionSet) IsAny() bool {
+	return len(s.intervals) == 1 && s.intervals[0].lo == nil && s.intervals[0].hi == nil
+}
+
+// Contains reports whether v is in s.
+func (s VersionSet) Contains(v Version) bool {
+	before, after := bound{v: v}, bound{v: v, after: true}
+	for _, i := range s.intervals {
+		if (i.lo == nil || i.lo.compare(before) <= 0) && (i.hi == nil || i.hi.compare(after) >= 0) {
+			return true
+		}
+	}
+	return false
+}
+
+// Complement returns the versions not in s.
+func (s VersionSet) Complement() VersionSet {
+	var out []interval
+	var lo *bound
+	for _, i := range s.intervals {
+		if i.lo != nil && (lo == nil || lo.compare(*i.lo) < 0) {
+			out = append(out, interval{lo: lo, hi: i.lo})
+		}
+		lo = i.hi
+		if lo == nil {
+			return VersionSet{intervals: out}
+		}
+	}
+	out = append(out, interval{lo: lo})
+	return VersionSet{intervals: out}
+}
+
+// Intersect returns the versions in both s and other.
+func (s VersionSet) Intersect(other VersionSet) VersionSet {
+	var out []interval
+	i, j := 0, 0
+	for i < len(s.intervals) && j < len(other.intervals) {
+		a, b := s.intervals[i], other.intervals[j]
+		lo := maxLo(a.lo, b.lo)
+		hi := minHi(a.hi, b.hi)
+		if lo == nil || hi == nil || lo.compare(*hi) < 0 {
+			out = append(out, interval{lo: lo, hi: hi})
+		}
+		// Moves past the interval ending first.
+		if hiLess(a.hi, b.hi) {
+			i++
+		} else {
+			j++
+		}
+	}
+	return VersionSet{intervals: out}
+}
+
+// Union returns the versions in s or other.
+func (s VersionSet) Union(other VersionSet) VersionSet {
+	return s.Complement().Intersect(other.Complement()).Complement()
+}
+
+// Difference returns the versions in s but not in other.
+func (s VersionSet) Difference(other VersionSet) VersionSet {
+	return s.Intersect(other.Complement())
+}
+
+// IsSubset reports whether all the versions of s are in other.
+func (s VersionSet) IsSubset(other VersionSet) bool {
+	return s.Difference(other).IsEmpty()
+}
+
+// IsDisjoint reports whether s and other have no version in common.
+func (s VersionSet) IsDisjoint(other VersionSet) bool {
+	return s.Intersect(other).IsEmpty()
+}
+
+// Equal reports whether s and other have the same versions.
+func (s VersionSet) Equal(other VersionSet) bool {
+	return s.IsSubset(other) && other.IsSubset(s)
+}
+
+func maxLo(a, b *bound) *bound {
+	if a == nil {
+		return b
+	}
+	if b == nil || a.compare(*b) >= 0 {
+		return a
+	}
+	return b
+}
+
+func minHi(a, b *bound) *bound {
+	if hiLess(a, b) {
+		return a
+	}
+	return b
+}
+
+// hiLess reports whether the upper bound a is lower than b.
+func hiLess(a, b *bound) bool {
+	if a == nil {
+		return false
+	}
+	return b == nil || a.compare(*b) < 0
+}
+
+// String formats s like a Hex requirement.
+func (s VersionSet) String() string {
+	if s.IsEmpty() {
+		return "no version"
+	}
+	if s.IsAny() {
+		return "any version"
+	}
+	var alternatives []string
+	for _, i := range s.intervals {
+		alternatives = append(alternatives, i.String())
+	}
+	return strings.Join(alternatives, " or ")
+}
+
+func (i interval) String() string {
+	if i.lo != nil && i.hi != nil && !i.lo.after && i.hi.after && i.lo.v.Compare(i.hi.v) == 0 {
+		return i.lo.v.String()
+	}
+	var clauses []string
+	if i.lo != nil {
+		op := ">="
+		if i.lo.after {
+			op = ">"
+		}
+		clauses = append(clauses, op+" "+i.lo.v.String())
+	}
+	if i.hi != nil {
+		op := "<"
+		if i.hi.after {
+			op = "<="
+		}
+		clauses = append(clauses, op+" "+i.hi.v.String())
+	}
+	return strings.Join(clauses, " and ")
+}
+
+// parseRequirement parses a Hex version requirement, e.g.
+// ">= 0.51.0 and < 2.0.0", "~> 1.2" or "1.0.0 or 2.0.0".
+func parseRequirement(s string) (VersionSet, error) {
+	if strings.TrimSpace(s) == "" {
+		return VersionSet{}, fmt.Errorf("empty version requirement")
+	}
+	set := VersionSet{}
+	for _, alternative := range strings.Split(s, " or ") {
+		clauses := anyVersion()
+		for _, clause := range strings.Split(alternative, " and ") {
+			c, err := parseClause(strings.TrimSpace(clause))
+			if err != nil {
+				return VersionSet{}, fmt.Errorf("invalid version requirement %q: %w", s, err)
+			}
+			clauses = clauses.Intersect(c)
+		}
+		set = set.Union(clauses)
+	}
+	return set, nil
+}
+
+func parseClause(clause string) (VersionSet, error) {
+	op := ""
+	for _, prefix := range []string{"~>", ">=", "<=", "==", "!=", ">", "<"} {
+		if rest, ok := strings.CutPrefix(clause, prefix); ok {
+			op, clause = prefix, strings.TrimSpace(rest)
+			break
+		}
+	}
+	if op == "~>" {
+		v, err := parsePartialVersion(clause)
+		if err != nil {
+			return VersionSet{}, err
+		}
+		if v.parts < 2 {
+			return VersionSet{}, fmt.Errorf("~> needs MAJOR.MINOR at least, got %q", clause)
+		}
+		// ~> 1.2 allows < 2.0.0 and ~> 1.2.3 allows < 1.3.0.
+		upper := Version{Major: v.Major + 1}
+		if v.parts == 3 {
+			upper = Version{Major: v.Major, Minor: v.Minor + 1}
+		}
+		return VersionSet{intervals: []interval{{lo: &bound{v: v.Version}, hi: &bound{v: upper}}}}, nil
+	}
+
+	v, err := parseVersion(clause)
+	if err != nil {
+		return VersionSet{}, err
+	}
+	switch op {
+	case ">=":
+		return VersionSet{intervals: []interval{{lo: &bound{v: v}}}}, nil
+	case ">":
+		return VersionSet{intervals: []interval{{lo: &bound{v: v, after: true}}}}, nil
+	case "<=":
+		return VersionSet{intervals: []interval{{hi: &bound{v: v, after: true}}}}, nil
+	case "<":
+		return VersionSet{intervals: []interval{{hi: &bound{v: v}}}}, nil
+	case "!=":
+		return exactly(v).Complement(), nil
+	}
+	return exactly(v), nil
+}
@@ -0,0 +1,117 @@
+package main
+
+import (
+	"testing"
+)
+
+func TestParseRequirement(t *testing.T) {
+	testCases := []struct {
+		requirement string
+		want        string
+		matches     []string
+		misses      []string
+		wantErr     bool
+	}{
+		{
+			requirement: ">= 0.51.0 and < 2.0.0",
+			want:        ">= 0.51.0 and < 2.0.0",
+			matches:     []string{"0.51.0", "1.9.9", "2.0.0-rc.1"},
+			misses:      []string{"0.50.9", "2.0.0", "0.51.0-rc.1"},
+		},
+		{
+			requirement: "~> 1.2",
+			want:        ">= 1.2.0 and < 2.0.0",
+			matches:     []string{"1.2.0", "1.99.0"},
+			misses:      []string{"1.1.9", "2.0.0"},
+		},
+		{
+			requirement: "~> 1.2.3",
+			want:        ">= 1.2.3 and < 1.3.0",
+			matches:     []string{"1.2.3", "1.2.99"},
+			misses:      []string{"1.3.0"},
+		},
+		{
+			requirement: "1.0.0 or == 2.0.0",
+			want:        "1.0.0 or 2.0.0",
+			matches:     []string{"1.0.0", "2.0.0"},
+			misses:      []string{"1.5.0"},
+		},
+		{
+			requirement: "> 1.0.0 and <= 2.0.0 and != 1.5.0",
+			want:        "> 1.0.0 and < 1.5.0 or > 1.5.0 and <= 2.0.0",
+			matches:     []string{"1.0.1", "2.0.0"},
+			misses:      []string{"1.0.0", "1.5.0", "2.0.1"},
+		},
+		{
+			requirement: ">= 2.0.0 and < 1.0.0",
+			want:        "no version",
+			misses:      []string{"1.5.0"},
+		},
+		{
+			requirement: ">= 1.0.0 or < 2.0.0",
+			want:        "any version",
+			matches:     []string{"0.0.1", "3.0.0"},
+		},
+		{requirement: "", wantErr: true},
+		{requirement: ">= 1.0", wantErr: true},
+		{requirement: "~> 1", wantErr: true},
+		{requirement: "latest", wantErr: true},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.requirement, func(t *testing.T) {
+			set, err := parseRequirement(tc.requirement)
+			if (err != nil) != tc.wantErr {
+				t.Fatalf("parseRequirement(%q) error = %v, wantErr %v", tc.requirement, err, tc.wantErr)
+			}
+			if tc.wantErr {
+				return
+			}
+			if got := set.String(); got != tc.want {
+				t.Errorf("parseRequirement(%q) = %s, want %s", tc.requirement, got, tc.want)
+			}
+			for _, v := range tc.matches {
+				if !set.Contains(mustParseVersion(t, v)) {
+					t.Errorf("%s doesn't contain %s", set, v)
+				}
+			}
+			for _, v := range tc.misses {
+				if set.Contains(mustParseVersion(t, v)) {
+					t.Errorf("%s contains %s", set, v)
+				}
+			}
+		})
+	}
+}
+
+func TestVersionCompare(t *testing.T) {
+	// In increasing order.
+	versions := []string{
+		"0.9.0",
+		"1.0.0-alpha",
+		"1.0.0-alpha.1",
+		"1.0.0-alpha.beta",
+		"1.0.0-beta.2",
+		"1.0.0-beta.11",
+		"1.0.0-rc.1",
+		"1.0.0",
+		"1.0.1",
+		"1.10.0",
+	}
+	for i := range versions {
+		for j := range versions {
+			got := mustParseVersion(t, versions[i]).Compare(mustParseVersion(t, versions[j]))
+			if want := sign(i - j); got != want {
+				t.Errorf("%s.Compare(%s) = %d, want %d", versions[i], versions[j], got, want)
+			}
+		}
+	}
+}
+
+func mustParseVersion(t *testing.T, s string) Version {
+	t.Helper()
+	v, err := parseVersion(s)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return v
+}
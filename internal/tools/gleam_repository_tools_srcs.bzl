@@ -5,13 +5,26 @@ GLEAM_REPOSITORY_TOOLS_SRCS = [
     Label("//gazelle:BUILD"),
     Label("//gazelle/gleam:BUILD"),
     Label("//gazelle/gleam:configurer.go"),
+    Label("//gazelle/gleam:fix.go"),
+    Label("//gazelle/gleam:import_cycles.go"),
     Label("//gazelle/gleam:language.go"),
     Label("//gazelle/gleam:language_generate_rules.go"),
+    Label("//gazelle/gleam:module_index.go"),
+    Label("//gazelle/gleam/otp:BUILD"),
+    Label("//gazelle/gleam/otp:app.go"),
+    Label("//gazelle/gleam/otp:modules.go"),
+    Label("//gazelle/gleam/otp:otp.go"),
     Label("//gazelle/gleam/parser:BUILD"),
     Label("//gazelle/gleam/parser:parser.go"),
     Label("//gazelle/gleam:resolver.go"),
     Label("//gazelle/gleam:utils.go"),
     Label("//internal:BUILD"),
+    Label("//internal/hex:BUILD"),
+    Label("//internal/hex:client.go"),
+    Label("//internal/hex/hextest:BUILD"),
+    Label("//internal/hex/hextest:hextest.go"),
+    Label("//internal/hex:manifest.go"),
+    Label("//internal/hex:tarball.go"),
     Label("//internal/tools:BUILD"),
     Label("//internal/tools/find_gleam_modules:BUILD"),
     Label("//internal/tools/find_gleam_modules:find_gleam_modules.go"),
@@ -19,6 +32,7 @@ GLEAM_REPOSITORY_TOOLS_SRCS = [
     Label("//internal/tools/gazelle:diff.go"),
     Label("//internal/tools/gazelle:fix-update.go"),
     Label("//internal/tools/gazelle:fix.go"),
+    Label("//internal/tools/gazelle:graph.go"),
     Label("//internal/tools/gazelle:langs.go"),
     Label("//internal/tools/gazelle:main.go"),
     Label("//internal/tools/gazelle:metaresolver.go"),
@@ -28,7 +42,12 @@ GLEAM_REPOSITORY_TOOLS_SRCS = [
     Label("//internal/tools/gazelle/wspace:BUILD"),
     Label("//internal/tools/gazelle/wspace:finder.go"),
     Label("//internal/tools/get_hex_repos:BUILD"),
+    Label("//internal/tools/get_hex_repos:check.go"),
     Label("//internal/tools/get_hex_repos:get_hex_repos.go"),
+    Label("//internal/tools/get_hex_repos:registry.go"),
+    Label("//internal/tools/get_hex_repos:resolve.go"),
+    Label("//internal/tools/get_hex_repos:solver.go"),
+    Label("//internal/tools/get_hex_repos:version.go"),
     Label("//internal/tools/hack_for_transitive_deps:BUILD"),
     Label("//internal/tools/hack_for_transitive_deps:hack_keep_indirect_deps.go"),
     Label("//internal/tools/list_repository_tools_srcs:BUILD"),